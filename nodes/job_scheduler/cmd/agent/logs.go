@@ -0,0 +1,122 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// logFlushInterval is how often buffered output is uploaded to the server
+	logFlushInterval = 500 * time.Millisecond
+	// maxLogLine caps a single line; anything longer is split
+	maxLogLine = 64 * 1024
+)
+
+// logShipper buffers the output of a running job and uploads it in batches
+type logShipper struct {
+	agent   *agent
+	jobID   string
+	pending map[string][]string // Lines not yet uploaded, keyed by stream
+	mu      sync.Mutex
+	done    chan struct{}
+	wg      sync.WaitGroup
+}
+
+func newLogShipper(a *agent, jobID string) *logShipper {
+	ls := &logShipper{
+		agent:   a,
+		jobID:   jobID,
+		pending: make(map[string][]string),
+		done:    make(chan struct{}),
+	}
+	ls.wg.Add(1)
+	go ls.loop()
+	return ls
+}
+
+// capture reads lines from r until EOF and queues them for upload on stream
+func (ls *logShipper) capture(stream string, r io.Reader) {
+	reader := bufio.NewReaderSize(r, maxLogLine)
+	for {
+		line, isPrefix, err := reader.ReadLine()
+		if len(line) > 0 || (err == nil && !isPrefix) {
+			ls.mu.Lock()
+			ls.pending[stream] = append(ls.pending[stream], string(line))
+			ls.mu.Unlock()
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// Close stops the periodic flush and uploads whatever is left. Lines the
+// server still does not accept then are dropped.
+func (ls *logShipper) Close() {
+	close(ls.done)
+	ls.wg.Wait()
+	ls.flush()
+}
+
+func (ls *logShipper) loop() {
+	defer ls.wg.Done()
+
+	ticker := time.NewTicker(logFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			ls.flush()
+		case <-ls.done:
+			return
+		}
+	}
+}
+
+func (ls *logShipper) flush() {
+	ls.mu.Lock()
+	pending := ls.pending
+	ls.pending = make(map[string][]string)
+	ls.mu.Unlock()
+
+	for stream, lines := range pending {
+		if err := ls.upload(stream, lines); err != nil {
+			log.Printf("Error uploading %s for job %s, will retry: %v", stream, ls.jobID, err)
+			// Put the batch back ahead of the lines captured since, for the next flush
+			ls.mu.Lock()
+			ls.pending[stream] = append(lines, ls.pending[stream]...)
+			ls.mu.Unlock()
+		}
+	}
+}
+
+func (ls *logShipper) upload(stream string, lines []string) error {
+	requestBody, err := json.Marshal(map[string]interface{}{
+		"worker_id": ls.agent.workerID,
+		"stream":    stream,
+		"lines":     lines,
+	})
+	if err != nil {
+		return err
+	}
+
+	resp, err := ls.agent.client.Post(ls.agent.serverURL+"/jobs/"+ls.jobID+"/logs", "application/json", bytes.NewBuffer(requestBody))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("server returned %s: %s", resp.Status, body)
+	}
+	return nil
+}
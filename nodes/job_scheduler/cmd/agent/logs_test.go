@@ -0,0 +1,62 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLogShipperRetries(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		failing  = true
+		received []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if failing {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var upload struct {
+			Lines []string `json:"lines"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&upload); err != nil {
+			t.Error(err)
+		}
+		received = append(received, upload.Lines...)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	a := &agent{serverURL: server.URL, workerID: "w1", client: server.Client(), stops: make(map[string]chan time.Duration)}
+	// Flushed by hand rather than on a ticker
+	ls := &logShipper{agent: a, jobID: "job-1", pending: make(map[string][]string)}
+
+	ls.pending["stdout"] = []string{"first", "second"}
+	ls.flush()
+	if got := ls.pending["stdout"]; !slices.Equal(got, []string{"first", "second"}) {
+		t.Fatalf("pending after a failed upload = %v, want the batch kept", got)
+	}
+
+	// Lines captured in the meantime go after the failed batch
+	ls.pending["stdout"] = append(ls.pending["stdout"], "third")
+	mu.Lock()
+	failing = false
+	mu.Unlock()
+	ls.flush()
+
+	if len(ls.pending["stdout"]) != 0 {
+		t.Errorf("pending after a successful upload = %v, want none", ls.pending["stdout"])
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if want := []string{"first", "second", "third"}; !slices.Equal(received, want) {
+		t.Errorf("server received %v, want %v", received, want)
+	}
+}
@@ -0,0 +1,531 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"os/exec"
+	"os/signal"
+	"runtime"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/Shishir_grez/coltnode/nodes/job_scheduler/internal/labels"
+	"github.com/Shishir_grez/coltnode/nodes/job_scheduler/pkg/models"
+)
+
+// agent registers with the scheduler server and executes the jobs assigned to it
+type agent struct {
+	serverURL   string
+	workerID    string
+	pollTimeout time.Duration
+	client      *http.Client
+	stops       map[string]chan time.Duration // Running jobs, receiving the grace period when cancelled
+	undelivered int                           // Results the server has not accepted yet
+	delivered   chan struct{}                 // Closed once undelivered drops back to zero
+	mu          sync.Mutex
+}
+
+// tokenTransport adds the agent's API token to every request
+type tokenTransport struct {
+	token string
+	base  http.RoundTripper
+}
+
+// RoundTrip sends the request with an Authorization header, if there is a token
+func (t tokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.token != "" {
+		req = req.Clone(req.Context())
+		req.Header.Set("Authorization", "Bearer "+t.token)
+	}
+	return t.base.RoundTrip(req)
+}
+
+// shutdownGrace is how long running jobs get to exit after SIGTERM when the
+// agent stops or a job reaches its deadline
+const shutdownGrace = 10 * time.Second
+
+// Delivering a result is retried with exponential backoff between these delays
+const (
+	reportRetryMin = time.Second
+	reportRetryMax = time.Minute
+)
+
+// errWorkerRemoved is returned when the server no longer knows this worker
+var errWorkerRemoved = errors.New("worker was removed from the scheduler")
+
+// resultRejectedError is returned when the server refuses a result outright,
+// so sending it again cannot succeed
+type resultRejectedError struct {
+	err error
+}
+
+func (e resultRejectedError) Error() string { return e.err.Error() }
+
+func main() {
+	hostname, _ := os.Hostname()
+
+	serverURL := flag.String("server", "http://localhost:8080", "Server URL for the job scheduler API")
+	name := flag.String("name", hostname, "Name of this worker")
+	cpuCores := flag.Int("cpu", runtime.NumCPU(), "Number of CPU cores offered to the scheduler")
+	memoryMB := flag.Int("memory", 1024, "Memory in MB offered to the scheduler")
+	pollTimeout := flag.Duration("poll-timeout", 30*time.Second, "How long to wait for an assignment per request")
+	labelList := flag.String("labels", "", "Comma-separated key=value labels jobs can select this worker by, e.g. disk=ssd,zone=eu-1")
+	taintList := flag.String("taints", "", "Comma-separated taints keeping jobs that do not tolerate them off this worker, e.g. dedicated=ml:NoSchedule")
+	token := flag.String("token", os.Getenv("COLTNODE_TOKEN"), "Worker API token to authenticate with (default $COLTNODE_TOKEN)")
+	namespace := flag.String("namespace", "", "Only run jobs from this namespace (default: run jobs from every namespace)")
+	heartbeatInterval := flag.Duration("heartbeat-interval", 10*time.Second, "How often to tell the server this worker is alive")
+	flag.Parse()
+
+	workerLabels, err := labels.ParseLabels(*labelList)
+	if err != nil {
+		log.Fatalf("Invalid -labels: %v", err)
+	}
+	var workerTaints []string
+	for _, expr := range strings.Split(*taintList, ",") {
+		if expr = strings.TrimSpace(expr); expr == "" {
+			continue
+		}
+		if _, err := labels.ParseTaint(expr); err != nil {
+			log.Fatalf("Invalid -taints: %v", err)
+		}
+		workerTaints = append(workerTaints, expr)
+	}
+
+	a := &agent{
+		serverURL:   *serverURL,
+		pollTimeout: *pollTimeout,
+		// Leave headroom over the long-poll so the server answers first
+		client: &http.Client{
+			Timeout:   *pollTimeout + 10*time.Second,
+			Transport: tokenTransport{token: *token, base: http.DefaultTransport},
+		},
+		stops:     make(map[string]chan time.Duration),
+		delivered: make(chan struct{}),
+	}
+	close(a.delivered)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := a.register(*name, *cpuCores, *memoryMB, workerLabels, workerTaints, *namespace); err != nil {
+		log.Fatalf("Failed to register worker: %v", err)
+	}
+	log.Printf("Worker %s registered as %s", *name, a.workerID)
+
+	go a.heartbeat(ctx, *heartbeatInterval)
+	a.run(ctx)
+	log.Println("Worker agent stopped")
+}
+
+// register announces this worker to the server and stores the assigned ID
+func (a *agent) register(name string, cpuCores, memoryMB int, labels map[string]string, taints []string, namespace string) error {
+	requestBody, err := json.Marshal(map[string]interface{}{
+		"name":      name,
+		"cpu_cores": cpuCores,
+		"memory_mb": memoryMB,
+		"labels":    labels,
+		"taints":    taints,
+		"namespace": namespace,
+	})
+	if err != nil {
+		return err
+	}
+
+	resp, err := a.client.Post(a.serverURL+"/workers", "application/json", bytes.NewBuffer(requestBody))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusCreated {
+		return fmt.Errorf("server returned %s: %s", resp.Status, body)
+	}
+
+	var response struct {
+		WorkerID string `json:"worker_id"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return err
+	}
+	a.workerID = response.WorkerID
+	return nil
+}
+
+// run polls for assignments and executes each job in its own goroutine until
+// ctx is cancelled or the worker is removed, in which case its jobs have been
+// requeued elsewhere and are stopped here. The server only assigns as many
+// jobs as fit this worker's resources, so no local limit is needed.
+func (a *agent) run(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var running sync.WaitGroup
+	defer running.Wait()
+
+	for ctx.Err() == nil {
+		// Take no new work while a finished job still holds resources on the server
+		a.waitForDelivery(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+
+		assignment, err := a.nextAssignment(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			if errors.Is(err, errWorkerRemoved) {
+				log.Printf("Worker %s was removed from the scheduler, stopping", a.workerID)
+				cancel()
+				return
+			}
+			log.Printf("Error polling for assignment: %v", err)
+			time.Sleep(5 * time.Second)
+			continue
+		}
+		if assignment == nil {
+			continue
+		}
+
+		if assignment.Cancel != "" {
+			a.stop(assignment.Cancel, assignment.GracePeriod)
+			continue
+		}
+
+		job := assignment.Job
+		stop := make(chan time.Duration, 1)
+		a.mu.Lock()
+		a.stops[job.ID] = stop
+		a.mu.Unlock()
+
+		running.Add(1)
+		go func() {
+			defer running.Done()
+			a.runJob(ctx, job, stop)
+		}()
+	}
+}
+
+// stop asks a running job to terminate
+func (a *agent) stop(jobID string, gracePeriod time.Duration) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	stop, exists := a.stops[jobID]
+	if !exists {
+		// Already finished, or never started here
+		return
+	}
+	log.Printf("Cancelling job %s", jobID)
+	select {
+	case stop <- gracePeriod:
+	default:
+	}
+}
+
+// runJob executes a job and reports its result
+func (a *agent) runJob(ctx context.Context, job *models.Job, stop <-chan time.Duration) {
+	log.Printf("Running job %s (%s)", job.ID, job.Name)
+	result := a.execute(ctx, job, stop)
+
+	a.mu.Lock()
+	delete(a.stops, job.ID)
+	a.mu.Unlock()
+
+	log.Printf("Job %s finished with status %s (exit code %d)", job.ID, result.Status, result.ExitCode)
+
+	// Report even when shutting down so the server does not lose the result
+	a.deliver(ctx, job.ID, result)
+}
+
+// deliver reports a job's result, retrying with backoff until the server
+// accepts or rejects it. While it retries the agent takes no new assignments.
+// Once ctx is cancelled it keeps trying for shutdownGrace and then gives up,
+// leaving the server to requeue the job when this worker's heartbeats stop.
+func (a *agent) deliver(ctx context.Context, jobID string, result models.JobResult) {
+	delay := reportRetryMin
+	var giveUp time.Time
+	for attempt := 1; ; attempt++ {
+		err := a.report(jobID, result)
+		if err == nil {
+			return
+		}
+		var rejected resultRejectedError
+		if errors.As(err, &rejected) {
+			log.Printf("Result for job %s was rejected: %v", jobID, err)
+			return
+		}
+
+		if attempt == 1 {
+			a.holdAssignments()
+			defer a.releaseAssignments()
+		}
+		wait := delay
+		if ctx.Err() != nil {
+			if giveUp.IsZero() {
+				giveUp = time.Now().Add(shutdownGrace)
+			}
+			if time.Now().After(giveUp) {
+				log.Printf("Giving up reporting the result for job %s: %v", jobID, err)
+				return
+			}
+			wait = min(wait, time.Until(giveUp))
+		}
+		log.Printf("Error reporting result for job %s, retrying in %s: %v", jobID, wait, err)
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			// Shutting down: retry now rather than sitting out a long backoff
+			timer.Stop()
+		}
+		delay = min(delay*2, reportRetryMax)
+	}
+}
+
+// holdAssignments marks a result as undelivered
+func (a *agent) holdAssignments() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.undelivered == 0 {
+		a.delivered = make(chan struct{})
+	}
+	a.undelivered++
+}
+
+// releaseAssignments marks an undelivered result as settled
+func (a *agent) releaseAssignments() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.undelivered--
+	if a.undelivered == 0 {
+		close(a.delivered)
+	}
+}
+
+// waitForDelivery blocks until every result has been settled or ctx is cancelled
+func (a *agent) waitForDelivery(ctx context.Context) {
+	a.mu.Lock()
+	delivered := a.delivered
+	a.mu.Unlock()
+
+	select {
+	case <-delivered:
+	case <-ctx.Done():
+	}
+}
+
+// heartbeat tells the server this worker is alive every interval until ctx is cancelled
+func (a *agent) heartbeat(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.serverURL+"/workers/"+a.workerID+"/heartbeat", nil)
+		if err != nil {
+			log.Printf("Error sending heartbeat: %v", err)
+			continue
+		}
+		resp, err := a.client.Do(req)
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Printf("Error sending heartbeat: %v", err)
+			}
+			continue
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			log.Printf("Heartbeat rejected: %s", resp.Status)
+		}
+	}
+}
+
+// nextAssignment long-polls the server; it returns nil when nothing was assigned
+func (a *agent) nextAssignment(ctx context.Context) (*models.Assignment, error) {
+	url := fmt.Sprintf("%s/workers/%s/assignment?wait=%s", a.serverURL, a.workerID, a.pollTimeout)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusNoContent:
+		return nil, nil
+	case http.StatusOK:
+		var assignment models.Assignment
+		if err := json.NewDecoder(resp.Body).Decode(&assignment); err != nil {
+			return nil, err
+		}
+		return &assignment, nil
+	case http.StatusNotFound:
+		return nil, errWorkerRemoved
+	default:
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("server returned %s: %s", resp.Status, body)
+	}
+}
+
+// execute runs the job's command as a child process and captures its outcome.
+// The process gets SIGTERM when the job is stopped, reaches its deadline or ctx
+// is cancelled, and SIGKILL if it is still running once the grace period is over.
+func (a *agent) execute(ctx context.Context, job *models.Job, stop <-chan time.Duration) models.JobResult {
+	result := models.JobResult{
+		WorkerID:  a.workerID,
+		StartTime: time.Now(),
+	}
+
+	// Pipe stdout and stderr through the shipper so the server can serve them
+	shipper := newLogShipper(a, job.ID)
+	stdoutReader, stdoutWriter := io.Pipe()
+	stderrReader, stderrWriter := io.Pipe()
+	var captured sync.WaitGroup
+	captured.Add(2)
+	go func() {
+		defer captured.Done()
+		shipper.capture("stdout", stdoutReader)
+	}()
+	go func() {
+		defer captured.Done()
+		shipper.capture("stderr", stderrReader)
+	}()
+
+	cmd := exec.Command(job.Command, job.Args...)
+	cmd.Stdout = stdoutWriter
+	cmd.Stderr = stderrWriter
+	startInProcessGroup(cmd)
+	// Don't hang on background processes that inherited the pipes
+	cmd.WaitDelay = 5 * time.Second
+
+	var deadline <-chan time.Time
+	if at := job.Deadline(result.StartTime); !at.IsZero() {
+		timer := time.NewTimer(time.Until(at))
+		defer timer.Stop()
+		deadline = timer.C
+	}
+
+	timedOut := false
+	err := cmd.Start()
+	if err == nil {
+		exited := make(chan struct{})
+		stopped := make(chan bool, 1)
+		go func() {
+			stopped <- terminateOnStop(ctx, cmd, stop, deadline, exited)
+		}()
+		err = cmd.Wait()
+		close(exited)
+		timedOut = <-stopped
+	}
+	result.EndTime = time.Now()
+
+	stdoutWriter.Close()
+	stderrWriter.Close()
+	captured.Wait()
+	shipper.Close()
+
+	var exitErr *exec.ExitError
+	switch {
+	case timedOut:
+		result.Status = "timed_out"
+		if errors.As(err, &exitErr) {
+			result.ExitCode = exitErr.ExitCode()
+		}
+	case err == nil:
+		result.Status = "completed"
+	case errors.As(err, &exitErr):
+		result.Status = "failed"
+		result.ExitCode = exitErr.ExitCode()
+	default:
+		// The command never started (not found, permission denied, ...)
+		result.Status = "failed"
+		result.ExitCode = -1
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// terminateOnStop signals cmd once the job is stopped, reaches its deadline or
+// ctx is cancelled, escalating to SIGKILL after the grace period. It returns
+// when exited is closed, reporting whether the job was stopped for its deadline.
+func terminateOnStop(ctx context.Context, cmd *exec.Cmd, stop <-chan time.Duration, deadline <-chan time.Time, exited <-chan struct{}) bool {
+	var gracePeriod time.Duration
+	timedOut := false
+	select {
+	case gracePeriod = <-stop:
+	case <-deadline:
+		log.Printf("Process %d reached its deadline, stopping it", cmd.Process.Pid)
+		gracePeriod = shutdownGrace
+		timedOut = true
+	case <-ctx.Done():
+		gracePeriod = shutdownGrace
+	case <-exited:
+		return false
+	}
+
+	terminate(cmd)
+	timer := time.NewTimer(gracePeriod)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		log.Printf("Process %d did not exit within %s, killing it", cmd.Process.Pid, gracePeriod)
+		kill(cmd)
+		<-exited
+	case <-exited:
+	}
+	return timedOut
+}
+
+// report sends the result of a job back to the server. Client errors other
+// than timeouts and rate limiting come back as a resultRejectedError.
+func (a *agent) report(jobID string, result models.JobResult) error {
+	requestBody, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+
+	resp, err := a.client.Post(a.serverURL+"/jobs/"+jobID+"/result", "application/json", bytes.NewBuffer(requestBody))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		err := fmt.Errorf("server returned %s: %s", resp.Status, body)
+		if resp.StatusCode >= 400 && resp.StatusCode < 500 &&
+			resp.StatusCode != http.StatusRequestTimeout && resp.StatusCode != http.StatusTooManyRequests {
+			return resultRejectedError{err}
+		}
+		return err
+	}
+	return nil
+}
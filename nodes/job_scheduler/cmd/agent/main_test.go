@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Shishir_grez/coltnode/nodes/job_scheduler/pkg/models"
+)
+
+// newTestAgent returns an agent whose server accepts every log upload
+func newTestAgent(t *testing.T) *agent {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(server.Close)
+
+	return &agent{
+		serverURL: server.URL,
+		workerID:  "w1",
+		client:    server.Client(),
+		stops:     make(map[string]chan time.Duration),
+	}
+}
+
+func TestExecute(t *testing.T) {
+	tests := []struct {
+		name         string
+		command      string
+		args         []string
+		timeout      time.Duration
+		finishIn     time.Duration // FinishBy relative to the start; 0 leaves it unset
+		stopAfter    time.Duration // Cancel the job after this long; 0 never
+		wantStatus   string
+		wantExitCode int
+		wantErr      bool
+	}{
+		{name: "completed", command: "true", wantStatus: "completed"},
+		{name: "failed", command: "sh", args: []string{"-c", "exit 3"}, wantStatus: "failed", wantExitCode: 3},
+		{name: "not found", command: "/nonexistent/command", wantStatus: "failed", wantExitCode: -1, wantErr: true},
+		{name: "timeout", command: "sleep", args: []string{"30"}, timeout: 200 * time.Millisecond, wantStatus: "timed_out", wantExitCode: -1},
+		{name: "finish by", command: "sleep", args: []string{"30"}, finishIn: 200 * time.Millisecond, wantStatus: "timed_out", wantExitCode: -1},
+		{name: "done before timeout", command: "true", timeout: time.Minute, wantStatus: "completed"},
+		{name: "stopped", command: "sleep", args: []string{"30"}, stopAfter: 200 * time.Millisecond, wantStatus: "failed", wantExitCode: -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestAgent(t)
+			job := models.NewJob(tt.name, tt.command, tt.args)
+			job.Timeout = tt.timeout
+			if tt.finishIn > 0 {
+				job.FinishBy = time.Now().Add(tt.finishIn)
+			}
+			stop := make(chan time.Duration, 1)
+			if tt.stopAfter > 0 {
+				timer := time.AfterFunc(tt.stopAfter, func() { stop <- time.Second })
+				defer timer.Stop()
+			}
+
+			start := time.Now()
+			result := a.execute(context.Background(), job, stop)
+			// Every case ends well before the 30s sleeps would
+			if elapsed := time.Since(start); elapsed > 5*time.Second {
+				t.Errorf("took %s, want the job stopped sooner", elapsed)
+			}
+			if result.Status != tt.wantStatus || result.ExitCode != tt.wantExitCode {
+				t.Errorf("result = %s with exit code %d, want %s with %d", result.Status, result.ExitCode, tt.wantStatus, tt.wantExitCode)
+			}
+			if (result.Error != "") != tt.wantErr {
+				t.Errorf("error = %q, want one: %v", result.Error, tt.wantErr)
+			}
+			if result.WorkerID != "w1" || result.EndTime.Before(result.StartTime) {
+				t.Errorf("result = %+v, want it from w1 with an end after the start", result)
+			}
+		})
+	}
+}
@@ -0,0 +1,21 @@
+//go:build !unix
+
+package main
+
+import (
+	"os"
+	"os/exec"
+)
+
+// startInProcessGroup is a no-op where process groups are not available
+func startInProcessGroup(cmd *exec.Cmd) {}
+
+// terminate asks the job's process to stop
+func terminate(cmd *exec.Cmd) {
+	cmd.Process.Signal(os.Interrupt)
+}
+
+// kill stops the job's process immediately
+func kill(cmd *exec.Cmd) {
+	cmd.Process.Kill()
+}
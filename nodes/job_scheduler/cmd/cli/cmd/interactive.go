@@ -24,6 +24,9 @@ func startInteractiveMode() {
 	fmt.Println("Welcome to ColtNode CLI Interactive Mode")
 	fmt.Println("Type 'help' for available commands or 'exit' to quit")
 	fmt.Println("Server URL:", serverURL)
+	if namespace != "" {
+		fmt.Println("Namespace:", namespace)
+	}
 	fmt.Println()
 
 	scanner := bufio.NewScanner(os.Stdin)
@@ -60,6 +63,32 @@ func handleInteractiveCommand(input string) {
 		handleJobCommand(args)
 	case "worker":
 		handleWorkerCommand(args)
+	case "workflow":
+		handleWorkflowCommand(args)
+	case "schedule":
+		handleScheduleCommand(args)
+	case "namespace":
+		handleNamespaceCommand(args)
+	case "token":
+		handleTokenCommand(args)
+	case "login":
+		if len(args) > 0 {
+			apiToken = args[0]
+			fmt.Println("API token set")
+		} else if apiToken != "" {
+			fmt.Println("An API token is set")
+		} else {
+			fmt.Println("No API token set. Usage: login TOKEN")
+		}
+	case "use":
+		if len(args) > 0 {
+			namespace = args[0]
+			fmt.Println("Namespace set to:", namespace)
+		} else if namespace != "" {
+			fmt.Println("Current namespace:", namespace)
+		} else {
+			fmt.Println("No namespace set: jobs go to the default namespace and lists show every namespace")
+		}
 	case "server":
 		if len(args) > 0 {
 			serverURL = args[0]
@@ -77,18 +106,63 @@ func showInteractiveHelp() {
 	fmt.Println("  help                           Show this help message")
 	fmt.Println("  exit, quit                     Exit interactive mode")
 	fmt.Println("  server [url]                   Show or set server URL")
-	fmt.Println("  job create --name NAME --command CMD [--arg ARG]...")
+	fmt.Println("  login [token]                  Set the API token to authenticate with")
+	fmt.Println("  use [namespace]                Show or set the namespace to submit to, register workers in and list")
+	fmt.Println("  job create --name NAME --command CMD [--arg ARG]... [--cpu N] [--memory M] [--policy P] [--priority N]")
+	fmt.Println("             [--max-attempts N] [--backoff fixed|exponential] [--backoff-delay D] [--backoff-max-delay D]")
+	fmt.Println("             [--timeout D] [--start-by TIME] [--finish-by TIME] [--at TIME | --delay D]")
+	fmt.Println("             [--selector SEL] [--prefer SEL[:WEIGHT]]... [--toleration TOL]...")
+	fmt.Println("             [--label KEY=VALUE]... [--group G] [--affinity RULE]...")
 	fmt.Println("                                 Create a new job")
 	fmt.Println("  job get --id ID                Get information about a job")
 	fmt.Println("  job list                       List all jobs")
-	fmt.Println("  worker register --name NAME [--cpu N] [--memory M]")
+	fmt.Println("  job cancel --id ID [--grace D] Cancel a job")
+	fmt.Println("  job logs --id ID [--stream S] [--tail N] [--follow]")
+	fmt.Println("                                 Show the output of a job")
+	fmt.Println("  worker register --name NAME [--cpu N] [--memory M] [--label KEY=VALUE]... [--taint KEY=VALUE:EFFECT]...")
 	fmt.Println("                                 Register a new worker")
 	fmt.Println("  worker list                    List all workers")
+	fmt.Println("  worker cordon --id ID          Stop placing new jobs on a worker")
+	fmt.Println("  worker uncordon --id ID        Put a cordoned or drained worker back in rotation")
+	fmt.Println("  worker drain --id ID [--wait D]")
+	fmt.Println("                                 Cordon a worker and let its running jobs finish")
+	fmt.Println("  worker taint --id ID --taint KEY=VALUE:EFFECT")
+	fmt.Println("                                 Keep jobs that do not tolerate the taint off a worker")
+	fmt.Println("  worker untaint --id ID --key KEY")
+	fmt.Println("                                 Remove a worker's taints with the given key")
+	fmt.Println("  worker remove --id ID [--force]")
+	fmt.Println("                                 Remove a worker, requeueing its running jobs with --force")
+	fmt.Println("  workflow submit --file FILE    Submit a workflow described in a JSON file")
+	fmt.Println("  workflow get --id ID           Get the status of a workflow")
+	fmt.Println("  schedule create --name NAME --cron EXPR --command CMD [--arg ARG]... [--overlap allow|skip|replace]")
+	fmt.Println("             [--cpu N] [--memory M] [--policy P] [--priority N] [--max-attempts N] [--timeout D]")
+	fmt.Println("             [--selector SEL] [--prefer SEL[:WEIGHT]]... [--toleration TOL]...")
+	fmt.Println("             [--label KEY=VALUE]... [--group G] [--affinity RULE]...")
+	fmt.Println("                                 Create a recurring job schedule")
+	fmt.Println("  schedule list                  List all schedules")
+	fmt.Println("  schedule get --id ID           Get information about a schedule")
+	fmt.Println("  schedule pause --id ID         Pause a schedule")
+	fmt.Println("  schedule resume --id ID        Resume a paused schedule")
+	fmt.Println("  schedule delete --id ID        Delete a schedule")
+	fmt.Println("  namespace create --name NAME [--weight N] [--max-running N] [--max-queued N] [--cpu N] [--memory M]")
+	fmt.Println("                                 Create a namespace, optionally with a quota")
+	fmt.Println("  namespace list                 List namespaces with their quota and usage")
+	fmt.Println("  namespace get --name NAME      Get a namespace's quota and usage")
+	fmt.Println("  namespace set-quota --name NAME [--max-running N] [--max-queued N] [--cpu N] [--memory M]")
+	fmt.Println("                                 Set a namespace's quota")
+	fmt.Println("  namespace set-weight --name NAME --weight N")
+	fmt.Println("                                 Set a namespace's fair share of the cluster")
+	fmt.Println("  namespace delete --name NAME   Delete an unused namespace")
+	fmt.Println("  token create --name NAME [--kind admin|user|worker] [--role viewer|submitter|operator|admin]")
+	fmt.Println("               [--scope NS...] [--expires-in D]")
+	fmt.Println("                                 Issue an API token, optionally limited to namespaces")
+	fmt.Println("  token list                     List API tokens")
+	fmt.Println("  token revoke --id ID           Revoke an API token")
 }
 
 func handleJobCommand(args []string) {
 	if len(args) == 0 {
-		fmt.Println("Missing job subcommand. Available: create, get, list")
+		fmt.Println("Missing job subcommand. Available: create, get, list, logs, cancel")
 		return
 	}
 
@@ -101,6 +175,25 @@ func handleJobCommand(args []string) {
 		jobName = ""
 		jobCommand = ""
 		jobArgs = []string{}
+		jobCPU = 1
+		jobMemory = 0
+		jobPolicy = ""
+		jobPrio = 5
+		jobMaxAttempts = 1
+		jobBackoff = "fixed"
+		jobBackoffDelay = "10s"
+		jobBackoffMax = ""
+		jobTimeout = ""
+		jobStartBy = ""
+		jobFinishBy = ""
+		jobRunAt = ""
+		jobDelay = ""
+		jobSelector = ""
+		jobPrefer = []string{}
+		jobTolerations = []string{}
+		jobLabels = []string{}
+		jobGroup = ""
+		jobAffinity = []string{}
 
 		for i := 0; i < len(subargs); i++ {
 			if subargs[i] == "--name" && i+1 < len(subargs) {
@@ -112,11 +205,68 @@ func handleJobCommand(args []string) {
 			} else if subargs[i] == "--arg" && i+1 < len(subargs) {
 				jobArgs = append(jobArgs, subargs[i+1])
 				i++
+			} else if subargs[i] == "--cpu" && i+1 < len(subargs) {
+				fmt.Sscanf(subargs[i+1], "%d", &jobCPU)
+				i++
+			} else if subargs[i] == "--memory" && i+1 < len(subargs) {
+				fmt.Sscanf(subargs[i+1], "%d", &jobMemory)
+				i++
+			} else if subargs[i] == "--policy" && i+1 < len(subargs) {
+				jobPolicy = subargs[i+1]
+				i++
+			} else if subargs[i] == "--priority" && i+1 < len(subargs) {
+				fmt.Sscanf(subargs[i+1], "%d", &jobPrio)
+				i++
+			} else if subargs[i] == "--max-attempts" && i+1 < len(subargs) {
+				fmt.Sscanf(subargs[i+1], "%d", &jobMaxAttempts)
+				i++
+			} else if subargs[i] == "--backoff" && i+1 < len(subargs) {
+				jobBackoff = subargs[i+1]
+				i++
+			} else if subargs[i] == "--backoff-delay" && i+1 < len(subargs) {
+				jobBackoffDelay = subargs[i+1]
+				i++
+			} else if subargs[i] == "--backoff-max-delay" && i+1 < len(subargs) {
+				jobBackoffMax = subargs[i+1]
+				i++
+			} else if subargs[i] == "--timeout" && i+1 < len(subargs) {
+				jobTimeout = subargs[i+1]
+				i++
+			} else if subargs[i] == "--start-by" && i+1 < len(subargs) {
+				jobStartBy = subargs[i+1]
+				i++
+			} else if subargs[i] == "--finish-by" && i+1 < len(subargs) {
+				jobFinishBy = subargs[i+1]
+				i++
+			} else if subargs[i] == "--at" && i+1 < len(subargs) {
+				jobRunAt = subargs[i+1]
+				i++
+			} else if subargs[i] == "--delay" && i+1 < len(subargs) {
+				jobDelay = subargs[i+1]
+				i++
+			} else if subargs[i] == "--selector" && i+1 < len(subargs) {
+				jobSelector = subargs[i+1]
+				i++
+			} else if subargs[i] == "--prefer" && i+1 < len(subargs) {
+				jobPrefer = append(jobPrefer, subargs[i+1])
+				i++
+			} else if subargs[i] == "--toleration" && i+1 < len(subargs) {
+				jobTolerations = append(jobTolerations, subargs[i+1])
+				i++
+			} else if subargs[i] == "--label" && i+1 < len(subargs) {
+				jobLabels = append(jobLabels, subargs[i+1])
+				i++
+			} else if subargs[i] == "--group" && i+1 < len(subargs) {
+				jobGroup = subargs[i+1]
+				i++
+			} else if subargs[i] == "--affinity" && i+1 < len(subargs) {
+				jobAffinity = append(jobAffinity, subargs[i+1])
+				i++
 			}
 		}
 
 		if jobName == "" || jobCommand == "" {
-			fmt.Println("Missing required arguments. Usage: job create --name NAME --command CMD [--arg ARG]...")
+			fmt.Println("Missing required arguments. Usage: job create --name NAME --command CMD [--arg ARG]... [--cpu N] [--memory M] [--policy P] [--priority N]")
 			return
 		}
 
@@ -143,14 +293,65 @@ func handleJobCommand(args []string) {
 	case "list":
 		listJobs()
 
+	case "cancel":
+		// Parse arguments for job cancellation
+		jobID = ""
+		jobGrace = ""
+
+		for i := 0; i < len(subargs); i++ {
+			if subargs[i] == "--id" && i+1 < len(subargs) {
+				jobID = subargs[i+1]
+				i++
+			} else if subargs[i] == "--grace" && i+1 < len(subargs) {
+				jobGrace = subargs[i+1]
+				i++
+			}
+		}
+
+		if jobID == "" {
+			fmt.Println("Missing required argument. Usage: job cancel --id ID [--grace D]")
+			return
+		}
+
+		cancelJob()
+
+	case "logs":
+		// Parse arguments for job logs
+		jobID = ""
+		logStream = "all"
+		logTail = 0
+		logFollow = false
+
+		for i := 0; i < len(subargs); i++ {
+			if subargs[i] == "--id" && i+1 < len(subargs) {
+				jobID = subargs[i+1]
+				i++
+			} else if subargs[i] == "--stream" && i+1 < len(subargs) {
+				logStream = subargs[i+1]
+				i++
+			} else if subargs[i] == "--tail" && i+1 < len(subargs) {
+				fmt.Sscanf(subargs[i+1], "%d", &logTail)
+				i++
+			} else if subargs[i] == "--follow" {
+				logFollow = true
+			}
+		}
+
+		if jobID == "" {
+			fmt.Println("Missing required argument. Usage: job logs --id ID [--stream S] [--tail N] [--follow]")
+			return
+		}
+
+		jobLogs()
+
 	default:
-		fmt.Printf("Unknown job subcommand: %s\nAvailable: create, get, list\n", subcommand)
+		fmt.Printf("Unknown job subcommand: %s\nAvailable: create, get, list, logs, cancel\n", subcommand)
 	}
 }
 
 func handleWorkerCommand(args []string) {
 	if len(args) == 0 {
-		fmt.Println("Missing worker subcommand. Available: register, list")
+		fmt.Println("Missing worker subcommand. Available: register, list, cordon, uncordon, drain, taint, untaint, remove")
 		return
 	}
 
@@ -163,6 +364,8 @@ func handleWorkerCommand(args []string) {
 		workerName = ""
 		workerCPU = 1
 		workerMemory = 1024
+		workerLabels = []string{}
+		workerTaints = []string{}
 
 		for i := 0; i < len(subargs); i++ {
 			if subargs[i] == "--name" && i+1 < len(subargs) {
@@ -174,6 +377,12 @@ func handleWorkerCommand(args []string) {
 			} else if subargs[i] == "--memory" && i+1 < len(subargs) {
 				fmt.Sscanf(subargs[i+1], "%d", &workerMemory)
 				i++
+			} else if subargs[i] == "--label" && i+1 < len(subargs) {
+				workerLabels = append(workerLabels, subargs[i+1])
+				i++
+			} else if subargs[i] == "--taint" && i+1 < len(subargs) {
+				workerTaints = append(workerTaints, subargs[i+1])
+				i++
 			}
 		}
 
@@ -187,7 +396,362 @@ func handleWorkerCommand(args []string) {
 	case "list":
 		listWorkers()
 
+	case "cordon", "uncordon", "drain", "taint", "untaint", "remove":
+		workerID = ""
+		workerWait = ""
+		workerForce = false
+		workerTaint = ""
+		workerKey = ""
+		for i := 0; i < len(subargs); i++ {
+			if subargs[i] == "--id" && i+1 < len(subargs) {
+				workerID = subargs[i+1]
+				i++
+			} else if subargs[i] == "--wait" && i+1 < len(subargs) {
+				workerWait = subargs[i+1]
+				i++
+			} else if subargs[i] == "--taint" && i+1 < len(subargs) {
+				workerTaint = subargs[i+1]
+				i++
+			} else if subargs[i] == "--key" && i+1 < len(subargs) {
+				workerKey = subargs[i+1]
+				i++
+			} else if subargs[i] == "--force" {
+				workerForce = true
+			}
+		}
+
+		if workerID == "" {
+			fmt.Printf("Missing required argument. Usage: worker %s --id ID\n", subcommand)
+			return
+		}
+
+		switch subcommand {
+		case "cordon":
+			workerAction("/cordon", "cordon")
+		case "uncordon":
+			workerAction("/uncordon", "uncordon")
+		case "drain":
+			drainWorker()
+		case "taint":
+			if workerTaint == "" {
+				fmt.Println("Missing required argument. Usage: worker taint --id ID --taint KEY=VALUE:EFFECT")
+				return
+			}
+			taintWorker()
+		case "untaint":
+			if workerKey == "" {
+				fmt.Println("Missing required argument. Usage: worker untaint --id ID --key KEY")
+				return
+			}
+			untaintWorker()
+		case "remove":
+			removeWorker()
+		}
+
+	default:
+		fmt.Printf("Unknown worker subcommand: %s\nAvailable: register, list, cordon, uncordon, drain, taint, untaint, remove\n", subcommand)
+	}
+}
+
+func handleWorkflowCommand(args []string) {
+	if len(args) == 0 {
+		fmt.Println("Missing workflow subcommand. Available: submit, get")
+		return
+	}
+
+	subcommand := args[0]
+	subargs := args[1:]
+
+	switch subcommand {
+	case "submit":
+		workflowFile = ""
+		for i := 0; i < len(subargs); i++ {
+			if subargs[i] == "--file" && i+1 < len(subargs) {
+				workflowFile = subargs[i+1]
+				i++
+			}
+		}
+
+		if workflowFile == "" || workflowFile == "-" {
+			fmt.Println("Missing required argument. Usage: workflow submit --file FILE")
+			return
+		}
+
+		submitWorkflow()
+
+	case "get":
+		workflowID = ""
+		for i := 0; i < len(subargs); i++ {
+			if subargs[i] == "--id" && i+1 < len(subargs) {
+				workflowID = subargs[i+1]
+				i++
+			}
+		}
+
+		if workflowID == "" {
+			fmt.Println("Missing required argument. Usage: workflow get --id ID")
+			return
+		}
+
+		getWorkflow()
+
+	default:
+		fmt.Printf("Unknown workflow subcommand: %s\nAvailable: submit, get\n", subcommand)
+	}
+}
+
+func handleScheduleCommand(args []string) {
+	if len(args) == 0 {
+		fmt.Println("Missing schedule subcommand. Available: create, list, get, pause, resume, delete")
+		return
+	}
+
+	subcommand := args[0]
+	subargs := args[1:]
+
+	switch subcommand {
+	case "create":
+		// Parse arguments for schedule creation
+		scheduleName = ""
+		scheduleCron = ""
+		scheduleOverlap = "allow"
+		jobCommand = ""
+		jobArgs = []string{}
+		jobCPU = 1
+		jobMemory = 0
+		jobPolicy = ""
+		jobPrio = 5
+		jobMaxAttempts = 1
+		jobTimeout = ""
+		jobSelector = ""
+		jobPrefer = []string{}
+		jobTolerations = []string{}
+		jobLabels = []string{}
+		jobGroup = ""
+		jobAffinity = []string{}
+
+		for i := 0; i < len(subargs); i++ {
+			if subargs[i] == "--name" && i+1 < len(subargs) {
+				scheduleName = subargs[i+1]
+				i++
+			} else if subargs[i] == "--cron" && i+1 < len(subargs) {
+				scheduleCron = subargs[i+1]
+				i++
+			} else if subargs[i] == "--overlap" && i+1 < len(subargs) {
+				scheduleOverlap = subargs[i+1]
+				i++
+			} else if subargs[i] == "--command" && i+1 < len(subargs) {
+				jobCommand = subargs[i+1]
+				i++
+			} else if subargs[i] == "--arg" && i+1 < len(subargs) {
+				jobArgs = append(jobArgs, subargs[i+1])
+				i++
+			} else if subargs[i] == "--cpu" && i+1 < len(subargs) {
+				fmt.Sscanf(subargs[i+1], "%d", &jobCPU)
+				i++
+			} else if subargs[i] == "--memory" && i+1 < len(subargs) {
+				fmt.Sscanf(subargs[i+1], "%d", &jobMemory)
+				i++
+			} else if subargs[i] == "--policy" && i+1 < len(subargs) {
+				jobPolicy = subargs[i+1]
+				i++
+			} else if subargs[i] == "--priority" && i+1 < len(subargs) {
+				fmt.Sscanf(subargs[i+1], "%d", &jobPrio)
+				i++
+			} else if subargs[i] == "--max-attempts" && i+1 < len(subargs) {
+				fmt.Sscanf(subargs[i+1], "%d", &jobMaxAttempts)
+				i++
+			} else if subargs[i] == "--timeout" && i+1 < len(subargs) {
+				jobTimeout = subargs[i+1]
+				i++
+			} else if subargs[i] == "--selector" && i+1 < len(subargs) {
+				jobSelector = subargs[i+1]
+				i++
+			} else if subargs[i] == "--prefer" && i+1 < len(subargs) {
+				jobPrefer = append(jobPrefer, subargs[i+1])
+				i++
+			} else if subargs[i] == "--toleration" && i+1 < len(subargs) {
+				jobTolerations = append(jobTolerations, subargs[i+1])
+				i++
+			} else if subargs[i] == "--label" && i+1 < len(subargs) {
+				jobLabels = append(jobLabels, subargs[i+1])
+				i++
+			} else if subargs[i] == "--group" && i+1 < len(subargs) {
+				jobGroup = subargs[i+1]
+				i++
+			} else if subargs[i] == "--affinity" && i+1 < len(subargs) {
+				jobAffinity = append(jobAffinity, subargs[i+1])
+				i++
+			}
+		}
+
+		if scheduleName == "" || scheduleCron == "" || jobCommand == "" {
+			fmt.Println("Missing required arguments. Usage: schedule create --name NAME --cron EXPR --command CMD [options]")
+			return
+		}
+
+		createSchedule()
+
+	case "list":
+		listSchedules()
+
+	case "get", "pause", "resume", "delete":
+		scheduleID = ""
+		for i := 0; i < len(subargs); i++ {
+			if subargs[i] == "--id" && i+1 < len(subargs) {
+				scheduleID = subargs[i+1]
+				i++
+			}
+		}
+
+		if scheduleID == "" {
+			fmt.Printf("Missing required argument. Usage: schedule %s --id ID\n", subcommand)
+			return
+		}
+
+		switch subcommand {
+		case "get":
+			getSchedule()
+		case "pause":
+			scheduleAction("POST", "/pause", "pause", "paused")
+		case "resume":
+			scheduleAction("POST", "/resume", "resume", "resumed")
+		case "delete":
+			scheduleAction("DELETE", "", "delete", "deleted")
+		}
+
+	default:
+		fmt.Printf("Unknown schedule subcommand: %s\nAvailable: create, list, get, pause, resume, delete\n", subcommand)
+	}
+}
+
+func handleNamespaceCommand(args []string) {
+	if len(args) == 0 {
+		fmt.Println("Missing namespace subcommand. Available: create, list, get, set-quota, set-weight, delete")
+		return
+	}
+
+	subcommand := args[0]
+	subargs := args[1:]
+
+	switch subcommand {
+	case "list":
+		listNamespaces()
+
+	case "create", "get", "set-quota", "set-weight", "delete":
+		namespaceName = ""
+		namespaceWeight = 1
+		namespaceMaxRunning = 0
+		namespaceMaxQueued = 0
+		namespaceCPU = 0
+		namespaceMemory = 0
+		for i := 0; i < len(subargs); i++ {
+			if subargs[i] == "--name" && i+1 < len(subargs) {
+				namespaceName = subargs[i+1]
+				i++
+			} else if subargs[i] == "--max-running" && i+1 < len(subargs) {
+				fmt.Sscanf(subargs[i+1], "%d", &namespaceMaxRunning)
+				i++
+			} else if subargs[i] == "--max-queued" && i+1 < len(subargs) {
+				fmt.Sscanf(subargs[i+1], "%d", &namespaceMaxQueued)
+				i++
+			} else if subargs[i] == "--cpu" && i+1 < len(subargs) {
+				fmt.Sscanf(subargs[i+1], "%d", &namespaceCPU)
+				i++
+			} else if subargs[i] == "--memory" && i+1 < len(subargs) {
+				fmt.Sscanf(subargs[i+1], "%d", &namespaceMemory)
+				i++
+			} else if subargs[i] == "--weight" && i+1 < len(subargs) {
+				fmt.Sscanf(subargs[i+1], "%d", &namespaceWeight)
+				i++
+			}
+		}
+
+		if namespaceName == "" {
+			fmt.Printf("Missing required argument. Usage: namespace %s --name NAME\n", subcommand)
+			return
+		}
+
+		switch subcommand {
+		case "create":
+			createNamespace()
+		case "get":
+			getNamespace()
+		case "set-quota":
+			setQuota()
+		case "set-weight":
+			setWeight()
+		case "delete":
+			deleteNamespace()
+		}
+
+	default:
+		fmt.Printf("Unknown namespace subcommand: %s\nAvailable: create, list, get, set-quota, set-weight, delete\n", subcommand)
+	}
+}
+
+func handleTokenCommand(args []string) {
+	if len(args) == 0 {
+		fmt.Println("Missing token subcommand. Available: create, list, revoke")
+		return
+	}
+
+	subcommand := args[0]
+	subargs := args[1:]
+
+	switch subcommand {
+	case "create":
+		tokenName = ""
+		tokenKind = "user"
+		tokenRole = ""
+		tokenScope = []string{}
+		tokenExpiresIn = ""
+		for i := 0; i < len(subargs); i++ {
+			if subargs[i] == "--name" && i+1 < len(subargs) {
+				tokenName = subargs[i+1]
+				i++
+			} else if subargs[i] == "--kind" && i+1 < len(subargs) {
+				tokenKind = subargs[i+1]
+				i++
+			} else if subargs[i] == "--role" && i+1 < len(subargs) {
+				tokenRole = subargs[i+1]
+				i++
+			} else if subargs[i] == "--scope" && i+1 < len(subargs) {
+				tokenScope = append(tokenScope, subargs[i+1])
+				i++
+			} else if subargs[i] == "--expires-in" && i+1 < len(subargs) {
+				tokenExpiresIn = subargs[i+1]
+				i++
+			}
+		}
+
+		if tokenName == "" {
+			fmt.Println("Missing required argument. Usage: token create --name NAME [--kind admin|user|worker] [--role R] [--scope NS...] [--expires-in D]")
+			return
+		}
+
+		createToken()
+
+	case "list":
+		listTokens()
+
+	case "revoke":
+		tokenID = ""
+		for i := 0; i < len(subargs); i++ {
+			if subargs[i] == "--id" && i+1 < len(subargs) {
+				tokenID = subargs[i+1]
+				i++
+			}
+		}
+
+		if tokenID == "" {
+			fmt.Println("Missing required argument. Usage: token revoke --id ID")
+			return
+		}
+
+		revokeToken()
+
 	default:
-		fmt.Printf("Unknown worker subcommand: %s\nAvailable: register, list\n", subcommand)
+		fmt.Printf("Unknown token subcommand: %s\nAvailable: create, list, revoke\n", subcommand)
 	}
 }
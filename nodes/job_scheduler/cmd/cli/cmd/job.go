@@ -1,11 +1,16 @@
 package cmd
 
 import (
+	"bufio"
 	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
 
 	"github.com/spf13/cobra"
 )
@@ -15,6 +20,30 @@ var (
 	jobCommand string
 	jobArgs    []string
 	jobID      string
+	jobCPU     int
+	jobMemory  int
+	jobPolicy  string
+	jobPrio    int
+	logStream  string
+	logTail    int
+	logFollow  bool
+	jobGrace   string
+
+	jobMaxAttempts  int
+	jobBackoff      string
+	jobBackoffDelay string
+	jobBackoffMax   string
+	jobTimeout      string
+	jobStartBy      string
+	jobFinishBy     string
+	jobRunAt        string
+	jobDelay        string
+	jobSelector     string
+	jobPrefer       []string
+	jobTolerations  []string
+	jobLabels       []string
+	jobGroup        string
+	jobAffinity     []string
 
 	jobCmd = &cobra.Command{
 		Use:   "job",
@@ -48,6 +77,24 @@ var (
 			listJobs()
 		},
 	}
+
+	cancelJobCmd = &cobra.Command{
+		Use:   "cancel",
+		Short: "Cancel a job",
+		Long:  `Cancel a pending job, or stop a running one. A running job gets SIGTERM and is killed if it has not exited after the grace period.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			cancelJob()
+		},
+	}
+
+	jobLogsCmd = &cobra.Command{
+		Use:   "logs",
+		Short: "Show the output of a job",
+		Long:  `Show the stdout and stderr captured for a job, optionally following new output until the job finishes.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			jobLogs()
+		},
+	}
 )
 
 func init() {
@@ -55,26 +102,89 @@ func init() {
 	jobCmd.AddCommand(createJobCmd)
 	jobCmd.AddCommand(getJobCmd)
 	jobCmd.AddCommand(listJobsCmd)
+	jobCmd.AddCommand(jobLogsCmd)
+	jobCmd.AddCommand(cancelJobCmd)
 
 	// Flags for create job command
 	createJobCmd.Flags().StringVar(&jobName, "name", "", "Name of the job (required)")
 	createJobCmd.Flags().StringVar(&jobCommand, "command", "", "Command to execute (required)")
 	createJobCmd.Flags().StringArrayVar(&jobArgs, "arg", []string{}, "Arguments for the command (can be specified multiple times)")
+	createJobCmd.Flags().IntVar(&jobCPU, "cpu", 1, "CPU cores to reserve for the job")
+	createJobCmd.Flags().IntVar(&jobMemory, "memory", 0, "Memory in MB to reserve for the job")
+	createJobCmd.Flags().StringVar(&jobPolicy, "policy", "", "Scheduling policy for the job (default: the server's policy)")
+	createJobCmd.Flags().IntVar(&jobPrio, "priority", 5, "Priority of the job, 0 (lowest) to 9 (highest)")
+	createJobCmd.Flags().IntVar(&jobMaxAttempts, "max-attempts", 1, "Number of times to run the job before leaving it failed")
+	createJobCmd.Flags().StringVar(&jobBackoff, "backoff", "fixed", "Delay between attempts: fixed or exponential (with jitter)")
+	createJobCmd.Flags().StringVar(&jobBackoffDelay, "backoff-delay", "10s", "Delay before the first retry")
+	createJobCmd.Flags().StringVar(&jobBackoffMax, "backoff-max-delay", "", "Upper bound on the delay for exponential backoff")
+	createJobCmd.Flags().StringVar(&jobTimeout, "timeout", "", "Longest a single attempt may run, e.g. 10m (default: server setting)")
+	createJobCmd.Flags().StringVar(&jobStartBy, "start-by", "", "RFC 3339 time after which the job expires if it has not started")
+	createJobCmd.Flags().StringVar(&jobFinishBy, "finish-by", "", "RFC 3339 time by which the job must be done or it times out")
+	createJobCmd.Flags().StringVar(&jobRunAt, "at", "", "RFC 3339 time before which the job is not run")
+	createJobCmd.Flags().StringVar(&jobDelay, "delay", "", "Hold the job back for this long before running it, e.g. 30m")
+	createJobCmd.Flags().StringVar(&jobSelector, "selector", "", `Labels a worker must have to run the job, e.g. "disk=ssd,zone in (a,b),!gpu"`)
+	createJobCmd.Flags().StringArrayVar(&jobPrefer, "prefer", []string{}, "Labels that make a worker preferred, as SELECTOR or SELECTOR:WEIGHT (can be specified multiple times)")
+	createJobCmd.Flags().StringArrayVar(&jobTolerations, "toleration", []string{}, "Worker taint the job tolerates, as key=value:Effect, key, key:Effect or * (can be specified multiple times)")
+	createJobCmd.Flags().StringArrayVar(&jobLabels, "label", []string{}, "Label as key=value that other jobs' affinity rules can select the job by (can be specified multiple times)")
+	createJobCmd.Flags().StringVar(&jobGroup, "group", "", "Group that other jobs' affinity rules can refer to, e.g. the service a replica belongs to")
+	createJobCmd.Flags().StringArrayVar(&jobAffinity, "affinity", []string{}, `Placement relative to other running jobs, e.g. "same-worker:@cache" or "prefer-different-worker=5:app=web" (can be specified multiple times)`)
 	createJobCmd.MarkFlagRequired("name")
 	createJobCmd.MarkFlagRequired("command")
 
 	// Flags for get job command
 	getJobCmd.Flags().StringVar(&jobID, "id", "", "ID of the job to get information about (required)")
 	getJobCmd.MarkFlagRequired("id")
+
+	// Flags for cancel job command
+	cancelJobCmd.Flags().StringVar(&jobID, "id", "", "ID of the job to cancel (required)")
+	cancelJobCmd.Flags().StringVar(&jobGrace, "grace", "", "Time to wait after SIGTERM before killing a running job, e.g. 30s (default: server setting)")
+	cancelJobCmd.MarkFlagRequired("id")
+
+	// Flags for job logs command
+	jobLogsCmd.Flags().StringVar(&jobID, "id", "", "ID of the job to show output for (required)")
+	jobLogsCmd.Flags().StringVar(&logStream, "stream", "all", "Stream to show: stdout, stderr or all")
+	jobLogsCmd.Flags().IntVar(&logTail, "tail", 0, "Only show the last N lines of each stream")
+	jobLogsCmd.Flags().BoolVar(&logFollow, "follow", false, "Keep streaming new output until the job finishes")
+	jobLogsCmd.MarkFlagRequired("id")
 }
 
 func createJob() {
 	// Prepare request body
-	requestBody, err := json.Marshal(map[string]interface{}{
-		"name":    jobName,
-		"command": jobCommand,
-		"args":    jobArgs,
-	})
+	request := map[string]interface{}{
+		"name":              jobName,
+		"namespace":         namespace,
+		"command":           jobCommand,
+		"args":              jobArgs,
+		"cpu_cores":         jobCPU,
+		"memory_mb":         jobMemory,
+		"policy":            jobPolicy,
+		"priority":          jobPrio,
+		"max_attempts":      jobMaxAttempts,
+		"backoff":           jobBackoff,
+		"backoff_delay":     jobBackoffDelay,
+		"backoff_max_delay": jobBackoffMax,
+		"timeout":           jobTimeout,
+		"selector":          jobSelector,
+		"preferences":       preferences(),
+		"tolerations":       jobTolerations,
+		"labels":            labelMap(jobLabels),
+		"group":             jobGroup,
+		"affinity":          jobAffinity,
+	}
+	// Deadlines are only sent when set, the server rejects empty times
+	if jobStartBy != "" {
+		request["start_by"] = jobStartBy
+	}
+	if jobFinishBy != "" {
+		request["finish_by"] = jobFinishBy
+	}
+	if jobRunAt != "" {
+		request["run_at"] = jobRunAt
+	}
+	if jobDelay != "" {
+		request["delay"] = jobDelay
+	}
+	requestBody, err := json.Marshal(request)
 	if err != nil {
 		exitWithError("Failed to create request: %v", err)
 	}
@@ -105,6 +215,9 @@ func createJob() {
 
 	// Print job ID
 	fmt.Printf("Job created successfully. ID: %s\n", response["job_id"])
+	if runAt, ok := response["run_at"]; ok {
+		fmt.Printf("Held back until %s\n", runAt)
+	}
 }
 
 func getJob() {
@@ -143,7 +256,7 @@ func getJob() {
 
 func listJobs() {
 	// Make API request
-	resp, err := http.Get(serverURL + "/jobs")
+	resp, err := http.Get(serverURL + "/jobs" + namespaceQuery())
 	if err != nil {
 		exitWithError("Failed to connect to server: %v", err)
 	}
@@ -174,3 +287,147 @@ func listJobs() {
 
 	fmt.Println(string(prettyJSON))
 }
+
+func cancelJob() {
+	// Make API request
+	endpoint := serverURL + "/jobs/" + jobID + "/cancel"
+	if jobGrace != "" {
+		endpoint += "?grace=" + url.QueryEscape(jobGrace)
+	}
+	resp, err := http.Post(endpoint, "application/json", nil)
+	if err != nil {
+		exitWithError("Failed to connect to server: %v", err)
+	}
+	defer resp.Body.Close()
+
+	// Read response
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		exitWithError("Failed to read response: %v", err)
+	}
+
+	// Check response status
+	if resp.StatusCode != http.StatusOK {
+		exitWithError("Failed to cancel job: %s", body)
+	}
+
+	// Parse response
+	var response map[string]interface{}
+	if err := json.Unmarshal(body, &response); err != nil {
+		exitWithError("Failed to parse response: %v", err)
+	}
+
+	fmt.Printf("Job %s is %s\n", response["job_id"], response["status"])
+}
+
+func jobLogs() {
+	query := url.Values{}
+	query.Set("stream", logStream)
+	query.Set("tail", strconv.Itoa(logTail))
+	if logFollow {
+		query.Set("follow", "true")
+	}
+
+	// Make API request
+	resp, err := http.Get(serverURL + "/jobs/" + jobID + "/logs?" + query.Encode())
+	if err != nil {
+		exitWithError("Failed to connect to server: %v", err)
+	}
+	defer resp.Body.Close()
+
+	// Check response status
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		exitWithError("Failed to get job logs: %s", body)
+	}
+
+	if logFollow {
+		followJobLogs(resp.Body)
+		return
+	}
+
+	// Read response
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		exitWithError("Failed to read response: %v", err)
+	}
+
+	// Parse response
+	var pages map[string]struct {
+		Truncated bool     `json:"truncated"`
+		Lines     []string `json:"lines"`
+	}
+	if err := json.Unmarshal(body, &pages); err != nil {
+		exitWithError("Failed to parse response: %v", err)
+	}
+
+	for _, stream := range []string{"stdout", "stderr"} {
+		page, exists := pages[stream]
+		if !exists {
+			continue
+		}
+		out := logOutput(stream)
+		if page.Truncated {
+			fmt.Fprintf(out, "[earlier %s output was rotated away]\n", stream)
+		}
+		for _, line := range page.Lines {
+			fmt.Fprintln(out, line)
+		}
+	}
+}
+
+// followJobLogs prints server-sent events until the server ends the stream
+func followJobLogs(body io.Reader) {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	event := ""
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data := strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " ")
+			if event == "end" {
+				fmt.Fprintf(os.Stderr, "Job finished with status %s\n", data)
+				continue
+			}
+			fmt.Fprintln(logOutput(event), data)
+		case line == "":
+			event = ""
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		exitWithError("Failed to read log stream: %v", err)
+	}
+}
+
+// logOutput returns where lines of the given job stream should be printed
+func logOutput(stream string) io.Writer {
+	if stream == "stderr" {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
+// preferences turns the --prefer flags into the request's preferences. A
+// weight may follow the selector after a colon; it defaults to 1.
+func preferences() []map[string]interface{} {
+	preferences := make([]map[string]interface{}, 0, len(jobPrefer))
+	for _, prefer := range jobPrefer {
+		selector, weight := prefer, 1
+		if i := strings.LastIndex(prefer, ":"); i >= 0 {
+			n, err := strconv.Atoi(prefer[i+1:])
+			if err != nil {
+				exitWithError("Invalid weight in --prefer %q", prefer)
+			}
+			selector, weight = prefer[:i], n
+		}
+		preferences = append(preferences, map[string]interface{}{
+			"selector": selector,
+			"weight":   weight,
+		})
+	}
+	return preferences
+}
@@ -0,0 +1,314 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	namespaceName       string
+	namespaceMaxRunning int
+	namespaceMaxQueued  int
+	namespaceCPU        int
+	namespaceMemory     int
+	namespaceWeight     int
+
+	namespaceCmd = &cobra.Command{
+		Use:   "namespace",
+		Short: "Manage namespaces, their quotas and fair shares",
+		Long: `Create, list, get and delete namespaces, which partition jobs, schedules and workers
+between teams, and set the quota each one's jobs are limited to and its share of the cluster.`,
+	}
+
+	createNamespaceCmd = &cobra.Command{
+		Use:   "create",
+		Short: "Create a new namespace",
+		Long:  `Create a namespace, optionally with a quota. Limits left at 0 are not enforced.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			createNamespace()
+		},
+	}
+
+	listNamespacesCmd = &cobra.Command{
+		Use:   "list",
+		Short: "List all namespaces",
+		Long:  `List all namespaces with their quota and what their jobs currently use.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			listNamespaces()
+		},
+	}
+
+	getNamespaceCmd = &cobra.Command{
+		Use:   "get",
+		Short: "Get information about a namespace",
+		Long:  `Get a namespace's quota and what its jobs currently use.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			getNamespace()
+		},
+	}
+
+	setQuotaCmd = &cobra.Command{
+		Use:   "set-quota",
+		Short: "Set a namespace's quota",
+		Long: `Replace a namespace's quota. Limits left at 0 are not enforced. Jobs already running
+keep running if the namespace is over the new quota; no new ones start until it fits.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			setQuota()
+		},
+	}
+
+	setWeightCmd = &cobra.Command{
+		Use:   "set-weight",
+		Short: "Set a namespace's fair share",
+		Long: `Set a namespace's weight. With fair-share scheduling, namespaces with queued jobs get
+capacity in proportion to their weights; capacity one leaves idle goes to the others.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			setWeight()
+		},
+	}
+
+	deleteNamespaceCmd = &cobra.Command{
+		Use:   "delete",
+		Short: "Delete a namespace",
+		Long:  `Delete a namespace that no longer has unfinished jobs, schedules or workers.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			deleteNamespace()
+		},
+	}
+)
+
+func init() {
+	// Add subcommands to namespace command
+	namespaceCmd.AddCommand(createNamespaceCmd)
+	namespaceCmd.AddCommand(listNamespacesCmd)
+	namespaceCmd.AddCommand(getNamespaceCmd)
+	namespaceCmd.AddCommand(setQuotaCmd)
+	namespaceCmd.AddCommand(setWeightCmd)
+	namespaceCmd.AddCommand(deleteNamespaceCmd)
+
+	// Flags for commands that act on a single namespace
+	for _, cmd := range []*cobra.Command{createNamespaceCmd, getNamespaceCmd, setQuotaCmd, setWeightCmd, deleteNamespaceCmd} {
+		cmd.Flags().StringVar(&namespaceName, "name", "", "Name of the namespace (required)")
+		cmd.MarkFlagRequired("name")
+	}
+
+	// Quota flags for create and set-quota
+	for _, cmd := range []*cobra.Command{createNamespaceCmd, setQuotaCmd} {
+		cmd.Flags().IntVar(&namespaceMaxRunning, "max-running", 0, "Jobs that may run at the same time")
+		cmd.Flags().IntVar(&namespaceMaxQueued, "max-queued", 0, "Jobs that may wait to run")
+		cmd.Flags().IntVar(&namespaceCPU, "cpu", 0, "CPU cores the running jobs may reserve in total")
+		cmd.Flags().IntVar(&namespaceMemory, "memory", 0, "Memory in MB the running jobs may reserve in total")
+	}
+	createNamespaceCmd.Flags().IntVar(&namespaceWeight, "weight", 1, "Share of the cluster relative to other namespaces")
+	setWeightCmd.Flags().IntVar(&namespaceWeight, "weight", 1, "Share of the cluster relative to other namespaces (required)")
+	setWeightCmd.MarkFlagRequired("weight")
+}
+
+// quotaRequest returns the quota given by the quota flags as a request body
+func quotaRequest() map[string]interface{} {
+	return map[string]interface{}{
+		"max_running": namespaceMaxRunning,
+		"max_queued":  namespaceMaxQueued,
+		"cpu_cores":   namespaceCPU,
+		"memory_mb":   namespaceMemory,
+	}
+}
+
+func createNamespace() {
+	// Prepare request body
+	request := quotaRequest()
+	request["name"] = namespaceName
+	request["weight"] = namespaceWeight
+	requestBody, err := json.Marshal(request)
+	if err != nil {
+		exitWithError("Failed to create request: %v", err)
+	}
+
+	// Make API request
+	resp, err := http.Post(serverURL+"/namespaces", "application/json", bytes.NewBuffer(requestBody))
+	if err != nil {
+		exitWithError("Failed to connect to server: %v", err)
+	}
+	defer resp.Body.Close()
+
+	// Read response
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		exitWithError("Failed to read response: %v", err)
+	}
+
+	// Check response status
+	if resp.StatusCode != http.StatusCreated {
+		exitWithError("Failed to create namespace: %s", body)
+	}
+
+	fmt.Printf("Namespace %s created successfully\n", namespaceName)
+}
+
+func listNamespaces() {
+	// Make API request
+	resp, err := http.Get(serverURL + "/namespaces")
+	if err != nil {
+		exitWithError("Failed to connect to server: %v", err)
+	}
+	defer resp.Body.Close()
+
+	// Read response
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		exitWithError("Failed to read response: %v", err)
+	}
+
+	// Check response status
+	if resp.StatusCode != http.StatusOK {
+		exitWithError("Failed to list namespaces: %s", body)
+	}
+
+	// Parse response
+	var namespaces []map[string]interface{}
+	if err := json.Unmarshal(body, &namespaces); err != nil {
+		exitWithError("Failed to parse response: %v", err)
+	}
+
+	// Pretty print namespaces
+	prettyJSON, err := json.MarshalIndent(namespaces, "", "  ")
+	if err != nil {
+		exitWithError("Failed to format response: %v", err)
+	}
+
+	fmt.Println(string(prettyJSON))
+}
+
+func getNamespace() {
+	// Make API request
+	resp, err := http.Get(serverURL + "/namespaces/" + url.PathEscape(namespaceName))
+	if err != nil {
+		exitWithError("Failed to connect to server: %v", err)
+	}
+	defer resp.Body.Close()
+
+	// Read response
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		exitWithError("Failed to read response: %v", err)
+	}
+
+	// Check response status
+	if resp.StatusCode != http.StatusOK {
+		exitWithError("Failed to get namespace: %s", body)
+	}
+
+	// Parse response
+	var ns map[string]interface{}
+	if err := json.Unmarshal(body, &ns); err != nil {
+		exitWithError("Failed to parse response: %v", err)
+	}
+
+	// Pretty print namespace information
+	prettyJSON, err := json.MarshalIndent(ns, "", "  ")
+	if err != nil {
+		exitWithError("Failed to format response: %v", err)
+	}
+
+	fmt.Println(string(prettyJSON))
+}
+
+func setQuota() {
+	// Prepare request body
+	requestBody, err := json.Marshal(quotaRequest())
+	if err != nil {
+		exitWithError("Failed to create request: %v", err)
+	}
+
+	// Make API request
+	req, err := http.NewRequest(http.MethodPut, serverURL+"/namespaces/"+url.PathEscape(namespaceName)+"/quota", bytes.NewBuffer(requestBody))
+	if err != nil {
+		exitWithError("Failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		exitWithError("Failed to connect to server: %v", err)
+	}
+	defer resp.Body.Close()
+
+	// Read response
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		exitWithError("Failed to read response: %v", err)
+	}
+
+	// Check response status
+	if resp.StatusCode != http.StatusOK {
+		exitWithError("Failed to set quota: %s", body)
+	}
+
+	fmt.Printf("Quota of namespace %s set\n", namespaceName)
+}
+
+func setWeight() {
+	// Prepare request body
+	requestBody, err := json.Marshal(map[string]interface{}{
+		"weight": namespaceWeight,
+	})
+	if err != nil {
+		exitWithError("Failed to create request: %v", err)
+	}
+
+	// Make API request
+	req, err := http.NewRequest(http.MethodPut, serverURL+"/namespaces/"+url.PathEscape(namespaceName)+"/weight", bytes.NewBuffer(requestBody))
+	if err != nil {
+		exitWithError("Failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		exitWithError("Failed to connect to server: %v", err)
+	}
+	defer resp.Body.Close()
+
+	// Read response
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		exitWithError("Failed to read response: %v", err)
+	}
+
+	// Check response status
+	if resp.StatusCode != http.StatusOK {
+		exitWithError("Failed to set weight: %s", body)
+	}
+
+	fmt.Printf("Weight of namespace %s set to %d\n", namespaceName, namespaceWeight)
+}
+
+func deleteNamespace() {
+	// Make API request
+	req, err := http.NewRequest(http.MethodDelete, serverURL+"/namespaces/"+url.PathEscape(namespaceName), nil)
+	if err != nil {
+		exitWithError("Failed to create request: %v", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		exitWithError("Failed to connect to server: %v", err)
+	}
+	defer resp.Body.Close()
+
+	// Read response
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		exitWithError("Failed to read response: %v", err)
+	}
+
+	// Check response status
+	if resp.StatusCode != http.StatusNoContent {
+		exitWithError("Failed to delete namespace: %s", body)
+	}
+
+	fmt.Printf("Namespace %s deleted\n", namespaceName)
+}
@@ -2,6 +2,8 @@ package cmd
 
 import (
 	"fmt"
+	"net/http"
+	"net/url"
 	"os"
 
 	"github.com/spf13/cobra"
@@ -9,11 +11,14 @@ import (
 
 var (
 	serverURL string
+	apiToken  string
+	namespace string
 	rootCmd   = &cobra.Command{
 		Use:   "coltnode",
 		Short: "ColtNode CLI - A command-line interface for the job scheduler",
 		Long: `ColtNode CLI is a comprehensive command-line tool for interacting with the job scheduler.
-It supports both interactive and command modes for managing jobs and workers.`,
+It supports both interactive and command modes for managing jobs, workers, workflows, schedules
+and the namespaces they belong to.`,
 	}
 )
 
@@ -25,13 +30,45 @@ func Execute() error {
 func init() {
 	// Global flags
 	rootCmd.PersistentFlags().StringVar(&serverURL, "server", "http://localhost:8080", "Server URL for the job scheduler API")
+	rootCmd.PersistentFlags().StringVar(&apiToken, "token", os.Getenv("COLTNODE_TOKEN"), "API token to authenticate with (default $COLTNODE_TOKEN)")
+	rootCmd.PersistentFlags().StringVar(&namespace, "namespace", "", "Namespace to submit to, register workers in and list (default: submit to the default namespace, list all)")
 
 	// Add commands
 	rootCmd.AddCommand(jobCmd)
 	rootCmd.AddCommand(workerCmd)
+	rootCmd.AddCommand(workflowCmd)
+	rootCmd.AddCommand(scheduleCmd)
+	rootCmd.AddCommand(namespaceCmd)
+	rootCmd.AddCommand(tokenCmd)
+
+	// Every request to the server carries the token
+	http.DefaultClient.Transport = tokenTransport{base: http.DefaultTransport}
 	rootCmd.AddCommand(interactiveCmd)
 }
 
+// tokenTransport adds the API token given by --token to every request
+type tokenTransport struct {
+	base http.RoundTripper
+}
+
+// RoundTrip sends the request with an Authorization header, if there is a token
+func (t tokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if apiToken != "" {
+		req = req.Clone(req.Context())
+		req.Header.Set("Authorization", "Bearer "+apiToken)
+	}
+	return t.base.RoundTrip(req)
+}
+
+// namespaceQuery returns the query string that limits a list to the
+// namespace given by --namespace, if any
+func namespaceQuery() string {
+	if namespace == "" {
+		return ""
+	}
+	return "?namespace=" + url.QueryEscape(namespace)
+}
+
 // exitWithError prints an error message and exits with code 1
 func exitWithError(format string, args ...interface{}) {
 	fmt.Fprintf(os.Stderr, format+"\n", args...)
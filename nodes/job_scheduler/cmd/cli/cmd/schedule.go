@@ -0,0 +1,272 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	scheduleName    string
+	scheduleCron    string
+	scheduleOverlap string
+	scheduleID      string
+
+	scheduleCmd = &cobra.Command{
+		Use:   "schedule",
+		Short: "Manage recurring job schedules",
+		Long:  `Create, list, pause, resume and delete schedules that run a job on a cron expression.`,
+	}
+
+	createScheduleCmd = &cobra.Command{
+		Use:   "create",
+		Short: "Create a new schedule",
+		Long: `Create a schedule that queues a job every time its cron expression fires.
+The expression has five fields: minute, hour, day of month, month and day of week,
+e.g. "*/15 * * * *" for every 15 minutes. @hourly, @daily, @weekly, @monthly and
+@yearly are accepted too.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			createSchedule()
+		},
+	}
+
+	listSchedulesCmd = &cobra.Command{
+		Use:   "list",
+		Short: "List all schedules",
+		Long:  `List all schedules in the scheduler.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			listSchedules()
+		},
+	}
+
+	getScheduleCmd = &cobra.Command{
+		Use:   "get",
+		Short: "Get information about a schedule",
+		Long:  `Get detailed information about a specific schedule by ID.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			getSchedule()
+		},
+	}
+
+	pauseScheduleCmd = &cobra.Command{
+		Use:   "pause",
+		Short: "Pause a schedule",
+		Long:  `Stop a schedule from queueing jobs until it is resumed.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			scheduleAction(http.MethodPost, "/pause", "pause", "paused")
+		},
+	}
+
+	resumeScheduleCmd = &cobra.Command{
+		Use:   "resume",
+		Short: "Resume a paused schedule",
+		Long:  `Let a paused schedule queue jobs again, starting from the next time its expression fires.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			scheduleAction(http.MethodPost, "/resume", "resume", "resumed")
+		},
+	}
+
+	deleteScheduleCmd = &cobra.Command{
+		Use:   "delete",
+		Short: "Delete a schedule",
+		Long:  `Delete a schedule. Jobs it already queued are left alone.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			scheduleAction(http.MethodDelete, "", "delete", "deleted")
+		},
+	}
+)
+
+func init() {
+	// Add subcommands to schedule command
+	scheduleCmd.AddCommand(createScheduleCmd)
+	scheduleCmd.AddCommand(listSchedulesCmd)
+	scheduleCmd.AddCommand(getScheduleCmd)
+	scheduleCmd.AddCommand(pauseScheduleCmd)
+	scheduleCmd.AddCommand(resumeScheduleCmd)
+	scheduleCmd.AddCommand(deleteScheduleCmd)
+
+	// Flags for create schedule command; the job flags match job create
+	createScheduleCmd.Flags().StringVar(&scheduleName, "name", "", "Name of the schedule and of the jobs it creates (required)")
+	createScheduleCmd.Flags().StringVar(&scheduleCron, "cron", "", "Cron expression saying when to run (required)")
+	createScheduleCmd.Flags().StringVar(&scheduleOverlap, "overlap", "allow", "What to do if the previous job is still running: allow, skip or replace")
+	createScheduleCmd.Flags().StringVar(&jobCommand, "command", "", "Command to execute (required)")
+	createScheduleCmd.Flags().StringArrayVar(&jobArgs, "arg", []string{}, "Arguments for the command (can be specified multiple times)")
+	createScheduleCmd.Flags().IntVar(&jobCPU, "cpu", 1, "CPU cores to reserve for each job")
+	createScheduleCmd.Flags().IntVar(&jobMemory, "memory", 0, "Memory in MB to reserve for each job")
+	createScheduleCmd.Flags().StringVar(&jobPolicy, "policy", "", "Scheduling policy for the jobs (default: the server's policy)")
+	createScheduleCmd.Flags().IntVar(&jobPrio, "priority", 5, "Priority of the jobs, 0 (lowest) to 9 (highest)")
+	createScheduleCmd.Flags().IntVar(&jobMaxAttempts, "max-attempts", 1, "Number of times to run each job before leaving it failed")
+	createScheduleCmd.Flags().StringVar(&jobTimeout, "timeout", "", "Longest a single attempt may run, e.g. 10m (default: server setting)")
+	createScheduleCmd.Flags().StringVar(&jobSelector, "selector", "", "Labels a worker must have to run the jobs")
+	createScheduleCmd.Flags().StringArrayVar(&jobPrefer, "prefer", []string{}, "Labels that make a worker preferred, as SELECTOR or SELECTOR:WEIGHT (can be specified multiple times)")
+	createScheduleCmd.Flags().StringArrayVar(&jobTolerations, "toleration", []string{}, "Worker taint the jobs tolerate (can be specified multiple times)")
+	createScheduleCmd.Flags().StringArrayVar(&jobLabels, "label", []string{}, "Label as key=value that other jobs' affinity rules can select the jobs by (can be specified multiple times)")
+	createScheduleCmd.Flags().StringVar(&jobGroup, "group", "", "Group that other jobs' affinity rules can refer to")
+	createScheduleCmd.Flags().StringArrayVar(&jobAffinity, "affinity", []string{}, "Placement relative to other running jobs (can be specified multiple times)")
+	createScheduleCmd.MarkFlagRequired("name")
+	createScheduleCmd.MarkFlagRequired("cron")
+	createScheduleCmd.MarkFlagRequired("command")
+
+	// Flags for commands that act on a single schedule
+	for _, cmd := range []*cobra.Command{getScheduleCmd, pauseScheduleCmd, resumeScheduleCmd, deleteScheduleCmd} {
+		cmd.Flags().StringVar(&scheduleID, "id", "", "ID of the schedule (required)")
+		cmd.MarkFlagRequired("id")
+	}
+}
+
+func createSchedule() {
+	// Prepare request body
+	requestBody, err := json.Marshal(map[string]interface{}{
+		"name":    scheduleName,
+		"cron":    scheduleCron,
+		"overlap": scheduleOverlap,
+		"job": map[string]interface{}{
+			"name":         scheduleName,
+			"namespace":    namespace,
+			"command":      jobCommand,
+			"args":         jobArgs,
+			"cpu_cores":    jobCPU,
+			"memory_mb":    jobMemory,
+			"policy":       jobPolicy,
+			"priority":     jobPrio,
+			"max_attempts": jobMaxAttempts,
+			"timeout":      jobTimeout,
+			"selector":     jobSelector,
+			"preferences":  preferences(),
+			"tolerations":  jobTolerations,
+			"labels":       labelMap(jobLabels),
+			"group":        jobGroup,
+			"affinity":     jobAffinity,
+		},
+	})
+	if err != nil {
+		exitWithError("Failed to create request: %v", err)
+	}
+
+	// Make API request
+	resp, err := http.Post(serverURL+"/schedules", "application/json", bytes.NewBuffer(requestBody))
+	if err != nil {
+		exitWithError("Failed to connect to server: %v", err)
+	}
+	defer resp.Body.Close()
+
+	// Read response
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		exitWithError("Failed to read response: %v", err)
+	}
+
+	// Check response status
+	if resp.StatusCode != http.StatusCreated {
+		exitWithError("Failed to create schedule: %s", body)
+	}
+
+	// Parse response
+	var response map[string]interface{}
+	if err := json.Unmarshal(body, &response); err != nil {
+		exitWithError("Failed to parse response: %v", err)
+	}
+
+	// Print schedule ID
+	fmt.Printf("Schedule created successfully. ID: %s, next run: %s\n", response["schedule_id"], response["next_run"])
+}
+
+func listSchedules() {
+	// Make API request
+	resp, err := http.Get(serverURL + "/schedules" + namespaceQuery())
+	if err != nil {
+		exitWithError("Failed to connect to server: %v", err)
+	}
+	defer resp.Body.Close()
+
+	// Read response
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		exitWithError("Failed to read response: %v", err)
+	}
+
+	// Check response status
+	if resp.StatusCode != http.StatusOK {
+		exitWithError("Failed to list schedules: %s", body)
+	}
+
+	// Parse response
+	var schedules []map[string]interface{}
+	if err := json.Unmarshal(body, &schedules); err != nil {
+		exitWithError("Failed to parse response: %v", err)
+	}
+
+	// Pretty print schedules
+	prettyJSON, err := json.MarshalIndent(schedules, "", "  ")
+	if err != nil {
+		exitWithError("Failed to format response: %v", err)
+	}
+
+	fmt.Println(string(prettyJSON))
+}
+
+func getSchedule() {
+	// Make API request
+	resp, err := http.Get(serverURL + "/schedules/" + scheduleID)
+	if err != nil {
+		exitWithError("Failed to connect to server: %v", err)
+	}
+	defer resp.Body.Close()
+
+	// Read response
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		exitWithError("Failed to read response: %v", err)
+	}
+
+	// Check response status
+	if resp.StatusCode != http.StatusOK {
+		exitWithError("Failed to get schedule: %s", body)
+	}
+
+	// Parse response
+	var schedule map[string]interface{}
+	if err := json.Unmarshal(body, &schedule); err != nil {
+		exitWithError("Failed to parse response: %v", err)
+	}
+
+	// Pretty print schedule information
+	prettyJSON, err := json.MarshalIndent(schedule, "", "  ")
+	if err != nil {
+		exitWithError("Failed to format response: %v", err)
+	}
+
+	fmt.Println(string(prettyJSON))
+}
+
+// scheduleAction sends a request that changes the schedule given by --id,
+// such as pausing or deleting it
+func scheduleAction(method, suffix, verb, done string) {
+	// Make API request
+	req, err := http.NewRequest(method, serverURL+"/schedules/"+scheduleID+suffix, nil)
+	if err != nil {
+		exitWithError("Failed to create request: %v", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		exitWithError("Failed to connect to server: %v", err)
+	}
+	defer resp.Body.Close()
+
+	// Read response
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		exitWithError("Failed to read response: %v", err)
+	}
+
+	// Check response status
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		exitWithError("Failed to %s schedule: %s", verb, body)
+	}
+
+	fmt.Printf("Schedule %s %s\n", scheduleID, done)
+}
@@ -0,0 +1,179 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	tokenName      string
+	tokenKind      string
+	tokenRole      string
+	tokenScope     []string
+	tokenExpiresIn string
+	tokenID        string
+
+	tokenCmd = &cobra.Command{
+		Use:   "token",
+		Short: "Manage API tokens",
+		Long:  `Issue, list and revoke the tokens used to authenticate with the server. Requires an admin token.`,
+	}
+
+	createTokenCmd = &cobra.Command{
+		Use:   "create",
+		Short: "Issue a new token",
+		Long: `Issue an admin, user or worker token. Worker tokens are for worker agents and only work on
+the endpoints agents use. User tokens have a role: viewers can only read, submitters can also
+submit jobs, cancel their own and manage schedules, operators can also cancel anyone's jobs and
+manage workers, and admins can do everything. Any token can be limited to some namespaces with
+--scope. The secret is printed once and cannot be shown again.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			createToken()
+		},
+	}
+
+	listTokensCmd = &cobra.Command{
+		Use:   "list",
+		Short: "List all tokens",
+		Long:  `List all issued tokens, without their secrets.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			listTokens()
+		},
+	}
+
+	revokeTokenCmd = &cobra.Command{
+		Use:   "revoke",
+		Short: "Revoke a token",
+		Long:  `Revoke a token, so its secret is no longer accepted.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			revokeToken()
+		},
+	}
+)
+
+func init() {
+	// Add subcommands to token command
+	tokenCmd.AddCommand(createTokenCmd)
+	tokenCmd.AddCommand(listTokensCmd)
+	tokenCmd.AddCommand(revokeTokenCmd)
+
+	// Flags for create token command
+	createTokenCmd.Flags().StringVar(&tokenName, "name", "", "What the token is for, e.g. who it is issued to (required)")
+	createTokenCmd.Flags().StringVar(&tokenKind, "kind", "user", "Kind of token: admin, user or worker")
+	createTokenCmd.Flags().StringVar(&tokenRole, "role", "", "Role of a user token: viewer, submitter, operator or admin (default: submitter)")
+	createTokenCmd.Flags().StringArrayVar(&tokenScope, "scope", []string{}, "Namespace the token may act in (can be specified multiple times; default: all)")
+	createTokenCmd.Flags().StringVar(&tokenExpiresIn, "expires-in", "", "How long the token is valid, e.g. 720h (default: never expires)")
+	createTokenCmd.MarkFlagRequired("name")
+
+	// Flags for revoke token command
+	revokeTokenCmd.Flags().StringVar(&tokenID, "id", "", "ID of the token (required)")
+	revokeTokenCmd.MarkFlagRequired("id")
+}
+
+func createToken() {
+	// Prepare request body
+	requestBody, err := json.Marshal(map[string]interface{}{
+		"name":       tokenName,
+		"kind":       tokenKind,
+		"role":       tokenRole,
+		"namespaces": tokenScope,
+		"expires_in": tokenExpiresIn,
+	})
+	if err != nil {
+		exitWithError("Failed to create request: %v", err)
+	}
+
+	// Make API request
+	resp, err := http.Post(serverURL+"/tokens", "application/json", bytes.NewBuffer(requestBody))
+	if err != nil {
+		exitWithError("Failed to connect to server: %v", err)
+	}
+	defer resp.Body.Close()
+
+	// Read response
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		exitWithError("Failed to read response: %v", err)
+	}
+
+	// Check response status
+	if resp.StatusCode != http.StatusCreated {
+		exitWithError("Failed to create token: %s", body)
+	}
+
+	// Parse response
+	var response map[string]interface{}
+	if err := json.Unmarshal(body, &response); err != nil {
+		exitWithError("Failed to parse response: %v", err)
+	}
+
+	// Print the secret, which the server does not keep
+	fmt.Printf("Token created successfully. ID: %s, role: %s\n", response["token_id"], response["role"])
+	fmt.Printf("Secret (shown only once): %s\n", response["secret"])
+}
+
+func listTokens() {
+	// Make API request
+	resp, err := http.Get(serverURL + "/tokens")
+	if err != nil {
+		exitWithError("Failed to connect to server: %v", err)
+	}
+	defer resp.Body.Close()
+
+	// Read response
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		exitWithError("Failed to read response: %v", err)
+	}
+
+	// Check response status
+	if resp.StatusCode != http.StatusOK {
+		exitWithError("Failed to list tokens: %s", body)
+	}
+
+	// Parse response
+	var tokens []map[string]interface{}
+	if err := json.Unmarshal(body, &tokens); err != nil {
+		exitWithError("Failed to parse response: %v", err)
+	}
+
+	// Pretty print tokens
+	prettyJSON, err := json.MarshalIndent(tokens, "", "  ")
+	if err != nil {
+		exitWithError("Failed to format response: %v", err)
+	}
+
+	fmt.Println(string(prettyJSON))
+}
+
+func revokeToken() {
+	// Make API request
+	req, err := http.NewRequest(http.MethodDelete, serverURL+"/tokens/"+url.PathEscape(tokenID), nil)
+	if err != nil {
+		exitWithError("Failed to create request: %v", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		exitWithError("Failed to connect to server: %v", err)
+	}
+	defer resp.Body.Close()
+
+	// Read response
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		exitWithError("Failed to read response: %v", err)
+	}
+
+	// Check response status
+	if resp.StatusCode != http.StatusNoContent {
+		exitWithError("Failed to revoke token: %s", body)
+	}
+
+	fmt.Printf("Token %s revoked\n", tokenID)
+}
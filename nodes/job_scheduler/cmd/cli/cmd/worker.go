@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strings"
 
 	"github.com/spf13/cobra"
 )
@@ -15,17 +17,24 @@ var (
 	workerCPU    int
 	workerMemory int
 	workerID     string
+	workerLabels []string
+	workerTaints []string
+	workerTaint  string
+	workerKey    string
+	workerWait   string
+	workerForce  bool
 
 	workerCmd = &cobra.Command{
 		Use:   "worker",
 		Short: "Manage workers in the scheduler",
-		Long:  `Register, list, and get information about workers in the scheduler.`,
+		Long:  `Register, list, cordon, drain and remove workers in the scheduler.`,
 	}
 
 	registerWorkerCmd = &cobra.Command{
 		Use:   "register",
 		Short: "Register a new worker",
-		Long:  `Register a new worker with the specified name, CPU cores, and memory.`,
+		Long: `Register a new worker with the specified name, CPU cores, and memory.
+With --namespace it only runs that namespace's jobs; otherwise it is shared by all of them.`,
 		Run: func(cmd *cobra.Command, args []string) {
 			registerWorker()
 		},
@@ -34,23 +43,100 @@ var (
 	listWorkersCmd = &cobra.Command{
 		Use:   "list",
 		Short: "List all workers",
-		Long:  `List all registered workers in the scheduler.`,
+		Long:  `List all registered workers in the scheduler, or with --namespace the ones that run its jobs.`,
 		Run: func(cmd *cobra.Command, args []string) {
 			listWorkers()
 		},
 	}
+
+	cordonWorkerCmd = &cobra.Command{
+		Use:   "cordon",
+		Short: "Stop placing new jobs on a worker",
+		Long:  `Take a worker out of rotation. Jobs it is already running carry on.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			workerAction("/cordon", "cordon")
+		},
+	}
+
+	uncordonWorkerCmd = &cobra.Command{
+		Use:   "uncordon",
+		Short: "Put a worker back in rotation",
+		Long:  `Let the scheduler place jobs on a cordoned or drained worker again.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			workerAction("/uncordon", "uncordon")
+		},
+	}
+
+	drainWorkerCmd = &cobra.Command{
+		Use:   "drain",
+		Short: "Cordon a worker and let its jobs finish",
+		Long: `Stop placing new jobs on a worker and let the jobs it is running finish,
+after which it is cordoned. With --wait, block until they have finished or the wait runs out.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			drainWorker()
+		},
+	}
+
+	taintWorkerCmd = &cobra.Command{
+		Use:   "taint",
+		Short: "Add a taint to a worker",
+		Long: `Keep jobs off a worker unless they tolerate the taint. With NoSchedule they are never
+placed there; with PreferNoSchedule only when no other worker has room.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			taintWorker()
+		},
+	}
+
+	untaintWorkerCmd = &cobra.Command{
+		Use:   "untaint",
+		Short: "Remove taints from a worker",
+		Long:  `Remove every taint with the given key from a worker.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			untaintWorker()
+		},
+	}
+
+	removeWorkerCmd = &cobra.Command{
+		Use:   "remove",
+		Short: "Remove a worker",
+		Long: `Remove a worker from the scheduler. A worker that still has jobs running is only
+removed with --force, which puts its jobs back in the queue; drain it first to avoid that.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			removeWorker()
+		},
+	}
 )
 
 func init() {
 	// Add subcommands to worker command
 	workerCmd.AddCommand(registerWorkerCmd)
 	workerCmd.AddCommand(listWorkersCmd)
+	workerCmd.AddCommand(cordonWorkerCmd)
+	workerCmd.AddCommand(uncordonWorkerCmd)
+	workerCmd.AddCommand(drainWorkerCmd)
+	workerCmd.AddCommand(taintWorkerCmd)
+	workerCmd.AddCommand(untaintWorkerCmd)
+	workerCmd.AddCommand(removeWorkerCmd)
 
 	// Flags for register worker command
 	registerWorkerCmd.Flags().StringVar(&workerName, "name", "", "Name of the worker (required)")
 	registerWorkerCmd.Flags().IntVar(&workerCPU, "cpu", 1, "Number of CPU cores")
 	registerWorkerCmd.Flags().IntVar(&workerMemory, "memory", 1024, "Available memory in MB")
+	registerWorkerCmd.Flags().StringArrayVar(&workerLabels, "label", []string{}, "Label as key=value that jobs can select the worker by (can be specified multiple times)")
+	registerWorkerCmd.Flags().StringArrayVar(&workerTaints, "taint", []string{}, "Taint as key=value:Effect keeping jobs that do not tolerate it off the worker (can be specified multiple times)")
 	registerWorkerCmd.MarkFlagRequired("name")
+
+	// Flags for commands that act on a single worker
+	for _, cmd := range []*cobra.Command{cordonWorkerCmd, uncordonWorkerCmd, drainWorkerCmd, taintWorkerCmd, untaintWorkerCmd, removeWorkerCmd} {
+		cmd.Flags().StringVar(&workerID, "id", "", "ID of the worker (required)")
+		cmd.MarkFlagRequired("id")
+	}
+	drainWorkerCmd.Flags().StringVar(&workerWait, "wait", "", "How long to wait for running jobs to finish, e.g. 10m (default: don't wait)")
+	taintWorkerCmd.Flags().StringVar(&workerTaint, "taint", "", "Taint to add, as key=value:Effect with Effect NoSchedule or PreferNoSchedule (required)")
+	taintWorkerCmd.MarkFlagRequired("taint")
+	untaintWorkerCmd.Flags().StringVar(&workerKey, "key", "", "Key of the taints to remove (required)")
+	untaintWorkerCmd.MarkFlagRequired("key")
+	removeWorkerCmd.Flags().BoolVar(&workerForce, "force", false, "Remove the worker even if it is running jobs, requeueing them")
 }
 
 func registerWorker() {
@@ -59,6 +145,9 @@ func registerWorker() {
 		"name":      workerName,
 		"cpu_cores": workerCPU,
 		"memory_mb": workerMemory,
+		"labels":    labelMap(workerLabels),
+		"taints":    workerTaints,
+		"namespace": namespace,
 	})
 	if err != nil {
 		exitWithError("Failed to create request: %v", err)
@@ -92,9 +181,22 @@ func registerWorker() {
 	fmt.Printf("Worker registered successfully. ID: %s\n", response["worker_id"])
 }
 
+// labelMap turns --label flags into the request's labels
+func labelMap(labelFlags []string) map[string]string {
+	labels := make(map[string]string, len(labelFlags))
+	for _, label := range labelFlags {
+		key, value, found := strings.Cut(label, "=")
+		if !found {
+			exitWithError("Label %q must be of the form key=value", label)
+		}
+		labels[key] = value
+	}
+	return labels
+}
+
 func listWorkers() {
 	// Make API request
-	resp, err := http.Get(serverURL + "/workers")
+	resp, err := http.Get(serverURL + "/workers" + namespaceQuery())
 	if err != nil {
 		exitWithError("Failed to connect to server: %v", err)
 	}
@@ -125,3 +227,143 @@ func listWorkers() {
 
 	fmt.Println(string(prettyJSON))
 }
+
+// workerAction sends a request that changes the state of the worker given by
+// --id, such as cordoning it, and prints its new status
+func workerAction(suffix, verb string) {
+	// Make API request
+	resp, err := http.Post(serverURL+"/workers/"+workerID+suffix, "application/json", nil)
+	if err != nil {
+		exitWithError("Failed to connect to server: %v", err)
+	}
+	defer resp.Body.Close()
+
+	// Read response
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		exitWithError("Failed to read response: %v", err)
+	}
+
+	// Check response status
+	if resp.StatusCode != http.StatusOK {
+		exitWithError("Failed to %s worker: %s", verb, body)
+	}
+
+	// Parse response
+	var response map[string]interface{}
+	if err := json.Unmarshal(body, &response); err != nil {
+		exitWithError("Failed to parse response: %v", err)
+	}
+
+	fmt.Printf("Worker %s is %s\n", workerID, response["status"])
+}
+
+func drainWorker() {
+	suffix := "/drain"
+	if workerWait != "" {
+		suffix += "?wait=" + url.QueryEscape(workerWait)
+	}
+	workerAction(suffix, "drain")
+}
+
+func taintWorker() {
+	// Prepare request body
+	requestBody, err := json.Marshal(map[string]interface{}{
+		"taint": workerTaint,
+	})
+	if err != nil {
+		exitWithError("Failed to create request: %v", err)
+	}
+
+	// Make API request
+	resp, err := http.Post(serverURL+"/workers/"+workerID+"/taints", "application/json", bytes.NewBuffer(requestBody))
+	if err != nil {
+		exitWithError("Failed to connect to server: %v", err)
+	}
+	defer resp.Body.Close()
+
+	printTaints(resp, "taint")
+}
+
+func untaintWorker() {
+	// Make API request
+	req, err := http.NewRequest(http.MethodDelete, serverURL+"/workers/"+workerID+"/taints/"+url.PathEscape(workerKey), nil)
+	if err != nil {
+		exitWithError("Failed to create request: %v", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		exitWithError("Failed to connect to server: %v", err)
+	}
+	defer resp.Body.Close()
+
+	printTaints(resp, "untaint")
+}
+
+// printTaints prints the taints a worker is left with after changing them
+func printTaints(resp *http.Response, verb string) {
+	// Read response
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		exitWithError("Failed to read response: %v", err)
+	}
+
+	// Check response status
+	if resp.StatusCode != http.StatusOK {
+		exitWithError("Failed to %s worker: %s", verb, body)
+	}
+
+	// Parse response
+	var response struct {
+		Taints []struct {
+			Key, Value, Effect string
+		} `json:"taints"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		exitWithError("Failed to parse response: %v", err)
+	}
+
+	if len(response.Taints) == 0 {
+		fmt.Printf("Worker %s has no taints\n", workerID)
+		return
+	}
+	fmt.Printf("Worker %s taints:\n", workerID)
+	for _, taint := range response.Taints {
+		if taint.Value == "" {
+			fmt.Printf("  %s:%s\n", taint.Key, taint.Effect)
+		} else {
+			fmt.Printf("  %s=%s:%s\n", taint.Key, taint.Value, taint.Effect)
+		}
+	}
+}
+
+func removeWorker() {
+	requestURL := serverURL + "/workers/" + workerID
+	if workerForce {
+		requestURL += "?force=true"
+	}
+
+	// Make API request
+	req, err := http.NewRequest(http.MethodDelete, requestURL, nil)
+	if err != nil {
+		exitWithError("Failed to create request: %v", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		exitWithError("Failed to connect to server: %v", err)
+	}
+	defer resp.Body.Close()
+
+	// Read response
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		exitWithError("Failed to read response: %v", err)
+	}
+
+	// Check response status
+	if resp.StatusCode != http.StatusNoContent {
+		exitWithError("Failed to remove worker: %s", body)
+	}
+
+	fmt.Printf("Worker %s removed\n", workerID)
+}
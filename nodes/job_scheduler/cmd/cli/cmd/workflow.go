@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	workflowFile string
+	workflowID   string
+
+	workflowCmd = &cobra.Command{
+		Use:   "workflow",
+		Short: "Manage workflows in the scheduler",
+		Long:  `Submit workflows of jobs that depend on each other, and follow their progress.`,
+	}
+
+	submitWorkflowCmd = &cobra.Command{
+		Use:   "submit",
+		Short: "Submit a workflow",
+		Long: `Submit a workflow described in a JSON file, or "-" for stdin. The file holds
+a name and a list of jobs with the same fields as POST /jobs, where each job
+may list the names of the jobs it depends on in "depends_on".`,
+		Run: func(cmd *cobra.Command, args []string) {
+			submitWorkflow()
+		},
+	}
+
+	getWorkflowCmd = &cobra.Command{
+		Use:   "get",
+		Short: "Get the status of a workflow",
+		Long:  `Get the status of a workflow and of every job in it by ID.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			getWorkflow()
+		},
+	}
+)
+
+func init() {
+	// Add subcommands to workflow command
+	workflowCmd.AddCommand(submitWorkflowCmd)
+	workflowCmd.AddCommand(getWorkflowCmd)
+
+	// Flags for submit workflow command
+	submitWorkflowCmd.Flags().StringVar(&workflowFile, "file", "", "JSON file describing the workflow, or - for stdin (required)")
+	submitWorkflowCmd.MarkFlagRequired("file")
+
+	// Flags for get workflow command
+	getWorkflowCmd.Flags().StringVar(&workflowID, "id", "", "ID of the workflow (required)")
+	getWorkflowCmd.MarkFlagRequired("id")
+}
+
+func submitWorkflow() {
+	// Read the workflow definition
+	var definition []byte
+	var err error
+	if workflowFile == "-" {
+		definition, err = io.ReadAll(os.Stdin)
+	} else {
+		definition, err = os.ReadFile(workflowFile)
+	}
+	if err != nil {
+		exitWithError("Failed to read workflow file: %v", err)
+	}
+	if !json.Valid(definition) {
+		exitWithError("Workflow file is not valid JSON")
+	}
+
+	// --namespace applies unless the file names a namespace itself
+	if namespace != "" {
+		var workflow map[string]interface{}
+		if err := json.Unmarshal(definition, &workflow); err != nil {
+			exitWithError("Workflow file must contain a JSON object: %v", err)
+		}
+		if _, set := workflow["namespace"]; !set {
+			workflow["namespace"] = namespace
+		}
+		if definition, err = json.Marshal(workflow); err != nil {
+			exitWithError("Failed to create request: %v", err)
+		}
+	}
+
+	// Make API request
+	resp, err := http.Post(serverURL+"/workflows", "application/json", bytes.NewBuffer(definition))
+	if err != nil {
+		exitWithError("Failed to connect to server: %v", err)
+	}
+	defer resp.Body.Close()
+
+	// Read response
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		exitWithError("Failed to read response: %v", err)
+	}
+
+	// Check response status
+	if resp.StatusCode != http.StatusCreated {
+		exitWithError("Failed to submit workflow: %s", body)
+	}
+
+	// Parse response
+	var response struct {
+		WorkflowID string            `json:"workflow_id"`
+		Jobs       map[string]string `json:"jobs"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		exitWithError("Failed to parse response: %v", err)
+	}
+
+	// Print workflow and job IDs
+	fmt.Printf("Workflow submitted successfully. ID: %s\n", response.WorkflowID)
+	for name, id := range response.Jobs {
+		fmt.Printf("  %s: %s\n", name, id)
+	}
+}
+
+func getWorkflow() {
+	// Make API request
+	resp, err := http.Get(serverURL + "/workflows/" + workflowID)
+	if err != nil {
+		exitWithError("Failed to connect to server: %v", err)
+	}
+	defer resp.Body.Close()
+
+	// Read response
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		exitWithError("Failed to read response: %v", err)
+	}
+
+	// Check response status
+	if resp.StatusCode != http.StatusOK {
+		exitWithError("Failed to get workflow: %s", body)
+	}
+
+	// Parse response
+	var workflow map[string]interface{}
+	if err := json.Unmarshal(body, &workflow); err != nil {
+		exitWithError("Failed to parse response: %v", err)
+	}
+
+	// Pretty print workflow information
+	prettyJSON, err := json.MarshalIndent(workflow, "", "  ")
+	if err != nil {
+		exitWithError("Failed to format response: %v", err)
+	}
+
+	fmt.Println(string(prettyJSON))
+}
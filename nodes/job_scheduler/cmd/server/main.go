@@ -1,11 +1,28 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"log"
+	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
+	"github.com/gin-contrib/sse"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/Shishir_grez/coltnode/nodes/job_scheduler/internal/auth"
+	"github.com/Shishir_grez/coltnode/nodes/job_scheduler/internal/labels"
+	"github.com/Shishir_grez/coltnode/nodes/job_scheduler/internal/logs"
 	"github.com/Shishir_grez/coltnode/nodes/job_scheduler/internal/queue"
 	"github.com/Shishir_grez/coltnode/nodes/job_scheduler/internal/scheduler"
 	"github.com/Shishir_grez/coltnode/nodes/job_scheduler/internal/storage"
@@ -13,52 +30,421 @@ import (
 )
 
 func main() {
+	logDir := flag.String("log-dir", "logs", "Directory where job output is stored")
+	logMaxBytes := flag.Int64("log-max-bytes", 1<<20, "Size at which a job's stdout or stderr file is rotated")
+	logMaxFiles := flag.Int("log-max-files", 3, "Number of rotated files kept per job stream")
+	storageBackend := flag.String("storage", "memory", "Storage backend: memory, file or wal")
+	dataFile := flag.String("data-file", "coltnode.json", "State file used by the file storage backend")
+	walDir := flag.String("wal-dir", "data", "Directory for the write-ahead log and snapshots of the wal storage backend")
+	walCompactEvery := flag.Int("wal-compact-every", 1000, "Number of log entries after which the wal backend writes a snapshot")
+	fairShare := flag.Bool("fair-share", false, "Share capacity between namespaces by their weights rather than placing queued jobs strictly by priority; priority then only orders jobs within a namespace")
+	policy := flag.String("policy", scheduler.DefaultPolicy, fmt.Sprintf("Default scheduling policy, one of %v", scheduler.PolicyNames()))
+	priorityAging := flag.Duration("priority-aging", queue.DefaultAging, "Time a waiting job needs to gain one priority level (0 disables aging)")
+	heartbeatTimeout := flag.Duration("heartbeat-timeout", 30*time.Second, "Time without a heartbeat after which a worker is marked offline")
+	cancelGrace := flag.Duration("cancel-grace", 10*time.Second, "Default time a cancelled job gets to exit after SIGTERM before SIGKILL")
+	defaultTimeout := flag.Duration("default-timeout", 0, "Execution timeout for jobs that do not set one (0 means no limit)")
+	defaultStartWithin := flag.Duration("default-start-within", 0, "Jobs that do not set start_by expire if not started this long after submission (0 means never)")
+	walSync := flag.Bool("wal-sync", false, "fsync the write-ahead log after every entry to also survive power loss")
+	authEnabled := flag.Bool("auth", true, "Require a bearer token on every API request")
+	adminToken := flag.String("admin-token", os.Getenv("COLTNODE_ADMIN_TOKEN"), "Admin token accepted besides issued ones, e.g. to create the first tokens (default $COLTNODE_ADMIN_TOKEN)")
+	adminTokenFile := flag.String("admin-token-file", "admin-token", "File the admin token created when none is configured is written to, readable only by this user")
+	shutdownTimeout := flag.Duration("shutdown-timeout", 30*time.Second, "Time in-flight requests and the scheduler get to finish when the server is stopped")
+	flag.Parse()
+	
+	if *heartbeatTimeout <= 0 {
+		log.Fatalf("Invalid -heartbeat-timeout %s: it must be positive", *heartbeatTimeout)
+	}
+	
 	// Initialize components
 	jobQueue := queue.NewJobQueue()
-	memoryStorage := storage.NewMemoryStorage()
-	jobScheduler := scheduler.NewScheduler(jobQueue, memoryStorage)
+	jobQueue.SetAging(*priorityAging)
+	jobStorage, err := openStorage(*storageBackend, *dataFile, *walDir, *walCompactEvery, *walSync)
+	if err != nil {
+		log.Fatalf("Failed to open storage: %v", err)
+	}
+	jobScheduler := scheduler.NewScheduler(jobQueue, jobStorage)
+	if err := jobScheduler.SetDefaultPolicy(*policy); err != nil {
+		log.Fatalf("Invalid -policy: %v", err)
+	}
+	jobScheduler.SetFairShare(*fairShare)
+	
+	// Put back any jobs that were still waiting when the server last stopped
+	if err := jobScheduler.Recover(); err != nil {
+		log.Fatalf("Failed to recover job queue: %v", err)
+	}
+	
+	logStore, err := logs.NewStore(*logDir, *logMaxBytes, *logMaxFiles)
+	if err != nil {
+		log.Fatalf("Failed to open log directory: %v", err)
+	}
+	
+	authenticator, err := auth.New(jobStorage, *adminToken)
+	if err != nil {
+		log.Fatalf("Failed to load API tokens: %v", err)
+	}
+	if *authEnabled && !authenticator.HasAdmin() {
+		// Otherwise nobody could create tokens, or use the API at all
+		_, secret, err := authenticator.Issue("initial admin token", models.TokenAdmin, "", nil, 0)
+		if err != nil {
+			log.Fatalf("Failed to create an admin token: %v", err)
+		}
+		// Kept out of stdout, which often ends up in captured process logs
+		if err := writeSecret(*adminTokenFile, secret); err != nil {
+			log.Fatalf("Failed to write the admin token: %v", err)
+		}
+		log.Printf("No admin token was configured, created one and wrote it to %s", *adminTokenFile)
+	} else if !*authEnabled {
+		log.Println("Authentication is disabled, anyone who can reach the server can use the API")
+	}
 	
 	// Start the scheduler
 	jobScheduler.Start()
+	jobScheduler.StartReaper(*heartbeatTimeout)
+	jobScheduler.StartDeadlineWatcher(time.Second, *cancelGrace)
+	jobScheduler.StartScheduleRunner(time.Second, *cancelGrace)
 	
 	// Set up Gin router
 	router := gin.Default()
 	
-	// API endpoints
-	router.POST("/jobs", func(c *gin.Context) {
-		var jobRequest struct {
-			Name    string   `json:"name" binding:"required"`
-			Command string   `json:"command" binding:"required"`
-			Args    []string `json:"args"`
+	// Every request needs a bearer token, unless authentication is turned off
+	router.Use(func(c *gin.Context) {
+		if !*authEnabled {
+			c.Next()
+			return
+		}
+		secret, found := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		token, err := authenticator.Authenticate(secret)
+		if !found || err != nil {
+			c.Header("WWW-Authenticate", `Bearer realm="coltnode"`)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Missing or invalid API token"})
+			return
+		}
+		c.Set("token", token)
+		c.Next()
+	})
+	
+	// require limits a route to tokens whose role allows the action
+	require := func(action string) gin.HandlerFunc {
+		return func(c *gin.Context) {
+			if !*authEnabled {
+				c.Next()
+				return
+			}
+			token := c.MustGet("token").(*models.Token)
+			if !auth.Allows(token, action) {
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": fmt.Sprintf("Role %s is not allowed to %s", auth.RoleOf(token), action)})
+				return
+			}
+			c.Next()
+		}
+	}
+	
+	// owner returns the ID of the token a request was made with, or "" if
+	// authentication is off
+	owner := func(c *gin.Context) string {
+		if !*authEnabled {
+			return ""
+		}
+		return c.MustGet("token").(*models.Token).ID
+	}
+	
+	// visible reports whether the request's token may act in a namespace
+	visible := func(c *gin.Context, namespace string) bool {
+		return !*authEnabled || auth.InScope(c.MustGet("token").(*models.Token), namespace)
+	}
+	
+	// inScope is visible for requests that act in a namespace: it answers
+	// those the token may not act in, and reports whether it did not
+	inScope := func(c *gin.Context, namespace string) bool {
+		if visible(c, namespace) {
+			return true
+		}
+		token := c.MustGet("token").(*models.Token)
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": fmt.Sprintf("Token is limited to namespaces %s", strings.Join(token.Namespaces, ", "))})
+		return false
+	}
+	
+	// jobScope, scheduleScope, workerScope and namespaceScope keep tokens
+	// limited to some namespaces from the :id or :name they act on if it is
+	// in another one
+	jobScope := func(c *gin.Context) {
+		if !*authEnabled {
+			return
+		}
+		job, err := jobScheduler.Job(c.Param("id"))
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+			return
+		}
+		inScope(c, models.NamespaceOf(job.Namespace))
+	}
+	scheduleScope := func(c *gin.Context) {
+		if !*authEnabled {
+			return
+		}
+		schedule, err := jobScheduler.Schedule(c.Param("id"))
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Schedule not found"})
+			return
+		}
+		inScope(c, models.NamespaceOf(schedule.Namespace))
+	}
+	workerScope := func(c *gin.Context) {
+		if !*authEnabled {
+			return
+		}
+		worker, err := jobScheduler.Worker(c.Param("id"))
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Worker not found"})
+			return
+		}
+		// Shared workers have no namespace, so only unlimited tokens reach them
+		inScope(c, worker.Namespace)
+	}
+	namespaceScope := func(c *gin.Context) {
+		inScope(c, c.Param("name"))
+	}
+	
+	// actsAs keeps a worker token to the worker it registered: it answers
+	// requests made as any other worker, and reports whether it did not.
+	// Other tokens allowed to run jobs may act as any worker.
+	actsAs := func(c *gin.Context, workerID string) bool {
+		if !*authEnabled {
+			return true
+		}
+		token := c.MustGet("token").(*models.Token)
+		if token.Kind != models.TokenWorker || (token.WorkerID != "" && token.WorkerID == workerID) {
+			return true
+		}
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Worker token may only act as the worker it registered"})
+		return false
+	}
+	
+	// ownWorker and ownJob apply actsAs to the worker in :id, and to the
+	// worker the job in :id is assigned to
+	ownWorker := func(c *gin.Context) {
+		actsAs(c, c.Param("id"))
+	}
+	ownJob := func(c *gin.Context) {
+		if !*authEnabled {
+			return
+		}
+		job, err := jobScheduler.Job(c.Param("id"))
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+			return
+		}
+		actsAs(c, job.WorkerID)
+	}
+	
+	// ownSchedule lets only the token that created the schedule in :id, or
+	// one allowed to manage anyone's schedules, change it
+	ownSchedule := func(c *gin.Context) {
+		if !*authEnabled || auth.Allows(c.MustGet("token").(*models.Token), auth.ActionManageAnySchedules) {
+			return
+		}
+		schedule, err := jobScheduler.Schedule(c.Param("id"))
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Schedule not found"})
+			return
+		}
+		// The schedule's jobs belong to whoever created it
+		if schedule.Template.Owner != owner(c) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Only the token that created the schedule, or an operator, can change it"})
+		}
+	}
+	
+	// New work is refused once the server starts shutting down
+	var shuttingDown atomic.Bool
+	acceptingJobs := func(c *gin.Context) {
+		if shuttingDown.Load() {
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "Server is shutting down"})
+			return
+		}
+		c.Next()
+	}
+	
+	// namespaceError answers a request that named an unknown namespace or
+	// would take one over its quota, and reports whether err was either
+	namespaceError := func(c *gin.Context, err error) bool {
+		switch {
+		case errors.Is(err, scheduler.ErrUnknownNamespace):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, scheduler.ErrQuotaExceeded):
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+		default:
+			return false
+		}
+		return true
+	}
+	
+	// newJob builds a job from a request, applying the server defaults
+	newJob := func(jobRequest jobRequest) (*models.Job, error) {
+		job := models.NewJob(jobRequest.Name, jobRequest.Command, jobRequest.Args)
+		job.ID = uuid.New().String()
+		
+		// Jobs reserve one core unless they say otherwise
+		job.Resources = models.Resources{CPUCores: 1, MemoryMB: jobRequest.MemoryMB}
+		if jobRequest.CPUCores != nil {
+			job.Resources.CPUCores = *jobRequest.CPUCores
+		}
+		if job.Resources.CPUCores < 0 || job.Resources.MemoryMB < 0 {
+			return nil, errors.New("Resource requirements must not be negative")
 		}
 		
+		if jobRequest.Policy != "" && !jobScheduler.HasPolicy(jobRequest.Policy) {
+			return nil, fmt.Errorf("Unknown scheduling policy %q", jobRequest.Policy)
+		}
+		job.Policy = jobRequest.Policy
+		job.Namespace = jobRequest.Namespace
+		
+		// Restrict and rank the workers the job may run on by their labels
+		selector, err := labels.Parse(jobRequest.Selector)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid selector: %v", err)
+		}
+		job.Selector = selector
+		for _, preferenceRequest := range jobRequest.Preferences {
+			selector, err := labels.Parse(preferenceRequest.Selector)
+			if err != nil {
+				return nil, fmt.Errorf("Invalid preference: %v", err)
+			}
+			weight := preferenceRequest.Weight
+			if weight == 0 {
+				weight = 1
+			}
+			if weight < 0 {
+				return nil, errors.New("Preference weights must not be negative")
+			}
+			job.Preferences = append(job.Preferences, models.Preference{Selector: selector, Weight: weight})
+		}
+		for _, expr := range jobRequest.Tolerations {
+			toleration, err := labels.ParseToleration(expr)
+			if err != nil {
+				return nil, fmt.Errorf("Invalid toleration: %v", err)
+			}
+			job.Tolerations = append(job.Tolerations, toleration)
+		}
+		
+		// Place the job relative to other running jobs
+		if err := labels.Validate(jobRequest.Labels); err != nil {
+			return nil, fmt.Errorf("Invalid labels: %v", err)
+		}
+		job.Labels = jobRequest.Labels
+		job.Group = jobRequest.Group
+		for _, expr := range jobRequest.Affinity {
+			rule, err := labels.ParseAffinity(expr)
+			if err != nil {
+				return nil, fmt.Errorf("Invalid affinity rule: %v", err)
+			}
+			job.Affinity = append(job.Affinity, rule)
+		}
+		
+		if jobRequest.Priority != nil {
+			if *jobRequest.Priority < models.MinPriority || *jobRequest.Priority > models.MaxPriority {
+				return nil, fmt.Errorf("Priority must be between %d and %d", models.MinPriority, models.MaxPriority)
+			}
+			job.Priority = *jobRequest.Priority
+		}
+		
+		backoff, err := parseBackoff(jobRequest.Backoff, jobRequest.BackoffDelay, jobRequest.BackoffMaxDelay)
+		if err != nil {
+			return nil, err
+		}
+		job.Backoff = backoff
+		if jobRequest.MaxAttempts < 0 {
+			return nil, errors.New("max_attempts must not be negative")
+		}
+		if jobRequest.MaxAttempts > 0 {
+			job.MaxAttempts = jobRequest.MaxAttempts
+		}
+		
+		// Delayed jobs are held back until run_at, or until delay after submission
+		if jobRequest.RunAt != nil && jobRequest.Delay != "" {
+			return nil, errors.New("Only one of run_at and delay may be set")
+		}
+		if jobRequest.RunAt != nil {
+			job.RunAt = *jobRequest.RunAt
+		}
+		if jobRequest.Delay != "" {
+			delay, err := time.ParseDuration(jobRequest.Delay)
+			if err != nil || delay < 0 {
+				return nil, fmt.Errorf("Invalid delay %q", jobRequest.Delay)
+			}
+			job.RunAt = job.SubmitTime.Add(delay)
+		}
+		
+		// Timeouts and deadlines fall back to the server defaults
+		job.Timeout = *defaultTimeout
+		if jobRequest.Timeout != "" {
+			if job.Timeout, err = time.ParseDuration(jobRequest.Timeout); err != nil || job.Timeout < 0 {
+				return nil, fmt.Errorf("Invalid timeout %q", jobRequest.Timeout)
+			}
+		}
+		if *defaultStartWithin > 0 {
+			// Counted from when the job may first run
+			job.StartBy = job.SubmitTime.Add(*defaultStartWithin)
+			if job.RunAt.After(job.SubmitTime) {
+				job.StartBy = job.RunAt.Add(*defaultStartWithin)
+			}
+		}
+		if jobRequest.StartBy != nil {
+			job.StartBy = *jobRequest.StartBy
+		}
+		if jobRequest.FinishBy != nil {
+			job.FinishBy = *jobRequest.FinishBy
+		}
+		return job, nil
+	}
+	
+	// API endpoints
+	router.POST("/jobs", require(auth.ActionSubmit), acceptingJobs, func(c *gin.Context) {
+		var jobRequest jobRequest
+		
 		if err := c.ShouldBindJSON(&jobRequest); err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
+		if len(jobRequest.DependsOn) > 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "depends_on is only supported for jobs submitted in a workflow"})
+			return
+		}
 		
-		job := models.NewJob(jobRequest.Name, jobRequest.Command, jobRequest.Args)
-		job.ID = uuid.New().String()
+		job, err := newJob(jobRequest)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if !inScope(c, models.NamespaceOf(job.Namespace)) {
+			return
+		}
+		job.Owner = owner(c)
 		
-		// Save the job
-		if err := memoryStorage.SaveJob(job); err != nil {
+		// Save the job and add it to the queue, or hold it until its run_at
+		if err := jobScheduler.Submit(job); err != nil {
+			if namespaceError(c, err) {
+				return
+			}
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save job"})
 			return
 		}
 		
-		// Add to queue
-		jobQueue.Enqueue(job)
-		
-		c.JSON(http.StatusCreated, gin.H{
+		response := gin.H{
 			"job_id": job.ID,
+			"namespace": job.Namespace,
 			"status": job.Status,
-		})
+			"priority": job.Priority,
+		}
+		if !job.RunAt.IsZero() {
+			response["run_at"] = job.RunAt
+		}
+		c.JSON(http.StatusCreated, response)
 	})
 	
-	router.GET("/jobs/:id", func(c *gin.Context) {
+	router.GET("/jobs/:id", require(auth.ActionView), jobScope, func(c *gin.Context) {
 		jobID := c.Param("id")
 		
-		job, err := memoryStorage.GetJob(jobID)
+		job, err := jobScheduler.Job(jobID)
 		if err != nil {
 			log.Printf("Error getting job %s: %v", jobID, err)
 			c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
@@ -68,23 +454,474 @@ func main() {
 		c.JSON(http.StatusOK, job)
 	})
 	
-	// Add endpoint for listing all jobs
-	router.GET("/jobs", func(c *gin.Context) {
-		jobs, err := memoryStorage.GetAllJobs()
+	// Add endpoint for listing all jobs, or those in one namespace (?namespace=)
+	router.GET("/jobs", require(auth.ActionView), func(c *gin.Context) {
+		jobs, err := jobScheduler.Jobs()
 		if err != nil {
 			log.Printf("Error getting all jobs: %v", err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get jobs"})
 			return
 		}
 		
+		namespace := c.Query("namespace")
+		jobs = slices.DeleteFunc(jobs, func(job *models.Job) bool {
+			return (namespace != "" && !inNamespace(job.Namespace, namespace)) || !visible(c, models.NamespaceOf(job.Namespace))
+		})
+		
 		c.JSON(http.StatusOK, jobs)
 	})
 	
-	router.POST("/workers", func(c *gin.Context) {
+	router.POST("/workflows", require(auth.ActionSubmit), acceptingJobs, func(c *gin.Context) {
+		var workflowRequest struct {
+			Name      string       `json:"name" binding:"required"`
+			Namespace string       `json:"namespace"` // Namespace of the workflow and all of its jobs
+			Jobs      []jobRequest `json:"jobs" binding:"required,min=1,dive"`
+		}
+		
+		if err := c.ShouldBindJSON(&workflowRequest); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if !inScope(c, models.NamespaceOf(workflowRequest.Namespace)) {
+			return
+		}
+		
+		// Jobs refer to each other by name within the workflow
+		jobs := make([]*models.Job, 0, len(workflowRequest.Jobs))
+		idsByName := make(map[string]string)
+		for _, jobRequest := range workflowRequest.Jobs {
+			if _, exists := idsByName[jobRequest.Name]; exists {
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Duplicate job name %q in workflow", jobRequest.Name)})
+				return
+			}
+			job, err := newJob(jobRequest)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Job %q: %v", jobRequest.Name, err)})
+				return
+			}
+			job.Owner = owner(c)
+			idsByName[job.Name] = job.ID
+			jobs = append(jobs, job)
+		}
+		for i, jobRequest := range workflowRequest.Jobs {
+			for _, name := range jobRequest.DependsOn {
+				parentID, exists := idsByName[name]
+				if !exists {
+					c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Job %q depends on unknown job %q", jobRequest.Name, name)})
+					return
+				}
+				jobs[i].DependsOn = append(jobs[i].DependsOn, parentID)
+			}
+		}
+		
+		workflow := &models.Workflow{
+			ID:         uuid.New().String(),
+			Name:       workflowRequest.Name,
+			Namespace:  workflowRequest.Namespace,
+			SubmitTime: time.Now(),
+		}
+		if err := jobScheduler.SubmitWorkflow(workflow, jobs); err != nil {
+			if errors.Is(err, scheduler.ErrInvalidWorkflow) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			if namespaceError(c, err) {
+				return
+			}
+			log.Printf("Error submitting workflow %s: %v", workflow.ID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save workflow"})
+			return
+		}
+		
+		c.JSON(http.StatusCreated, gin.H{
+			"workflow_id": workflow.ID,
+			"namespace": workflow.Namespace,
+			"jobs": idsByName,
+		})
+	})
+	
+	router.GET("/workflows/:id", require(auth.ActionView), func(c *gin.Context) {
+		workflowID := c.Param("id")
+		
+		workflow, err := jobStorage.GetWorkflow(workflowID)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Workflow not found"})
+			return
+		}
+		if !inScope(c, models.NamespaceOf(workflow.Namespace)) {
+			return
+		}
+		
+		jobs := make([]*models.Job, 0, len(workflow.JobIDs))
+		for _, jobID := range workflow.JobIDs {
+			job, err := jobScheduler.Job(jobID)
+			if err != nil {
+				log.Printf("Error getting job %s of workflow %s: %v", jobID, workflowID, err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get workflow jobs"})
+				return
+			}
+			jobs = append(jobs, job)
+		}
+		
+		// One node per job, with its dependencies by ID
+		type workflowNode struct {
+			JobID     string   `json:"job_id"`
+			Name      string   `json:"name"`
+			Status    string   `json:"status"`
+			DependsOn []string `json:"depends_on"`
+			Error     string   `json:"error,omitempty"`
+		}
+		nodes := make([]workflowNode, 0, len(jobs))
+		for _, job := range jobs {
+			nodes = append(nodes, workflowNode{
+				JobID:     job.ID,
+				Name:      job.Name,
+				Status:    job.Status,
+				DependsOn: job.DependsOn,
+				Error:     job.Error,
+			})
+		}
+		
+		c.JSON(http.StatusOK, gin.H{
+			"workflow_id": workflow.ID,
+			"name": workflow.Name,
+			"status": models.WorkflowStatus(jobs),
+			"submit_time": workflow.SubmitTime,
+			"jobs": nodes,
+		})
+	})
+	
+	router.POST("/jobs/:id/cancel", require(auth.ActionSubmit), jobScope, func(c *gin.Context) {
+		jobID := c.Param("id")
+		
+		grace, err := time.ParseDuration(c.DefaultQuery("grace", cancelGrace.String()))
+		if err != nil || grace < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid grace period"})
+			return
+		}
+		
+		// Submitters may only cancel their own jobs
+		if *authEnabled && !auth.Allows(c.MustGet("token").(*models.Token), auth.ActionCancelAny) {
+			job, err := jobScheduler.Job(jobID)
+			if err != nil {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+				return
+			}
+			if job.Owner != owner(c) {
+				c.JSON(http.StatusForbidden, gin.H{"error": "Only the token that submitted the job, or an operator, can cancel it"})
+				return
+			}
+		}
+		
+		job, err := jobScheduler.CancelJob(jobID, grace)
+		if err != nil {
+			log.Printf("Error cancelling job %s: %v", jobID, err)
+			switch err {
+			case scheduler.ErrJobFinished:
+				c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			default:
+				if _, getErr := jobScheduler.Job(jobID); getErr != nil {
+					c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+					return
+				}
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel job"})
+			}
+			return
+		}
+		
+		c.JSON(http.StatusOK, gin.H{
+			"job_id": job.ID,
+			"status": job.Status,
+		})
+	})
+	
+	// Endpoint for workers to report the outcome of a job
+	router.POST("/jobs/:id/result", require(auth.ActionRunJobs), jobScope, ownJob, func(c *gin.Context) {
+		jobID := c.Param("id")
+		
+		var result models.JobResult
+		if err := c.ShouldBindJSON(&result); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		
+		if err := jobScheduler.CompleteJob(jobID, result); err != nil {
+			log.Printf("Error completing job %s: %v", jobID, err)
+			switch err {
+			case scheduler.ErrInvalidResult:
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			case scheduler.ErrJobNotAssigned:
+				c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			default:
+				c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+			}
+			return
+		}
+		
+		c.JSON(http.StatusOK, gin.H{"job_id": jobID, "status": result.Status})
+	})
+	
+	// Endpoint for workers to upload lines of job output
+	router.POST("/jobs/:id/logs", require(auth.ActionRunJobs), jobScope, ownJob, func(c *gin.Context) {
+		jobID := c.Param("id")
+		
+		var logRequest struct {
+			WorkerID string   `json:"worker_id" binding:"required"`
+			Stream   string   `json:"stream" binding:"required"`
+			Lines    []string `json:"lines"`
+		}
+		
+		if err := c.ShouldBindJSON(&logRequest); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		
+		job, err := jobScheduler.Job(jobID)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+			return
+		}
+		if job.WorkerID != logRequest.WorkerID {
+			c.JSON(http.StatusConflict, gin.H{"error": scheduler.ErrJobNotAssigned.Error()})
+			return
+		}
+		
+		if err := logStore.Append(jobID, logRequest.Stream, logRequest.Lines); err != nil {
+			if err == logs.ErrInvalidStream {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			log.Printf("Error writing logs for job %s: %v", jobID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to write logs"})
+			return
+		}
+		
+		c.Status(http.StatusNoContent)
+	})
+	
+	// Endpoint for reading job output, optionally following it over SSE
+	router.GET("/jobs/:id/logs", require(auth.ActionView), jobScope, func(c *gin.Context) {
+		jobID := c.Param("id")
+		
+		if _, err := jobScheduler.Job(jobID); err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+			return
+		}
+		
+		streams := []string{logs.Stdout, logs.Stderr}
+		if stream := c.Query("stream"); stream != "" && stream != "all" {
+			streams = []string{stream}
+		}
+		offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+		if err != nil || offset < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid offset"})
+			return
+		}
+		tail, err := strconv.Atoi(c.DefaultQuery("tail", "0"))
+		if err != nil || tail < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tail"})
+			return
+		}
+		
+		pages := make(map[string]*logs.Page)
+		for _, stream := range streams {
+			page, err := logStore.Read(jobID, stream, offset, tail)
+			if err != nil {
+				if err == logs.ErrInvalidStream {
+					c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+					return
+				}
+				log.Printf("Error reading logs for job %s: %v", jobID, err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read logs"})
+				return
+			}
+			pages[stream] = page
+		}
+		
+		if c.Query("follow") != "true" {
+			c.JSON(http.StatusOK, pages)
+			return
+		}
+		
+		// Follow mode: send what we have, then stream new lines until the job finishes
+		c.Stream(func(w io.Writer) bool {
+			for _, stream := range streams {
+				page := pages[stream]
+				for i, line := range page.Lines {
+					c.Render(-1, sse.Event{
+						Id:    strconv.Itoa(page.Offset + i),
+						Event: stream,
+						Data:  line,
+					})
+				}
+			}
+			
+			job, err := jobScheduler.Job(jobID)
+			if err != nil {
+				return false
+			}
+			finished := job.Finished()
+			
+			// Wait for any stream to grow, re-checking the job status every second
+			ctx, cancel := context.WithTimeout(c.Request.Context(), time.Second)
+			defer cancel()
+			for _, stream := range streams {
+				go func(stream string) {
+					if logStore.Wait(ctx, jobID, stream, pages[stream].NextOffset) == nil {
+						cancel()
+					}
+				}(stream)
+			}
+			<-ctx.Done()
+			if c.Request.Context().Err() != nil {
+				return false
+			}
+			
+			grew := false
+			for _, stream := range streams {
+				page, err := logStore.Read(jobID, stream, pages[stream].NextOffset, 0)
+				if err != nil {
+					return false
+				}
+				grew = grew || len(page.Lines) > 0
+				pages[stream] = page
+			}
+			
+			if finished && !grew {
+				c.SSEvent("end", job.Status)
+				return false
+			}
+			return true
+		})
+	})
+	
+	router.POST("/schedules", require(auth.ActionManageSchedules), acceptingJobs, func(c *gin.Context) {
+		var scheduleRequest struct {
+			Name    string     `json:"name" binding:"required"`
+			Cron    string     `json:"cron" binding:"required"`
+			Overlap string     `json:"overlap"`
+			Job     jobRequest `json:"job" binding:"required"`
+		}
+		
+		if err := c.ShouldBindJSON(&scheduleRequest); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if scheduleRequest.Job.StartBy != nil || scheduleRequest.Job.FinishBy != nil || scheduleRequest.Job.RunAt != nil || len(scheduleRequest.Job.DependsOn) > 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "start_by, finish_by, run_at and depends_on cannot be used in a schedule's job"})
+			return
+		}
+		
+		template, err := newJob(scheduleRequest.Job)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if !inScope(c, models.NamespaceOf(template.Namespace)) {
+			return
+		}
+		// The jobs the schedule creates belong to whoever created it
+		template.Owner = owner(c)
+		
+		schedule := &models.Schedule{
+			ID:         uuid.New().String(),
+			Name:       scheduleRequest.Name,
+			Cron:       scheduleRequest.Cron,
+			Overlap:    scheduleRequest.Overlap,
+			Namespace:  template.Namespace,
+			Template:   *template,
+			CreateTime: time.Now(),
+		}
+		if err := jobScheduler.CreateSchedule(schedule); err != nil {
+			if errors.Is(err, scheduler.ErrInvalidSchedule) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			if namespaceError(c, err) {
+				return
+			}
+			log.Printf("Error creating schedule %s: %v", schedule.ID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save schedule"})
+			return
+		}
+		
+		c.JSON(http.StatusCreated, gin.H{
+			"schedule_id": schedule.ID,
+			"namespace": schedule.Namespace,
+			"next_run": schedule.NextRun,
+		})
+	})
+	
+	router.GET("/schedules", require(auth.ActionView), func(c *gin.Context) {
+		schedules, err := jobScheduler.Schedules()
+		if err != nil {
+			log.Printf("Error getting all schedules: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get schedules"})
+			return
+		}
+		
+		namespace := c.Query("namespace")
+		schedules = slices.DeleteFunc(schedules, func(schedule *models.Schedule) bool {
+			return (namespace != "" && !inNamespace(schedule.Namespace, namespace)) || !visible(c, models.NamespaceOf(schedule.Namespace))
+		})
+		
+		c.JSON(http.StatusOK, schedules)
+	})
+	
+	router.GET("/schedules/:id", require(auth.ActionView), scheduleScope, func(c *gin.Context) {
+		schedule, err := jobScheduler.Schedule(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Schedule not found"})
+			return
+		}
+		
+		c.JSON(http.StatusOK, schedule)
+	})
+	
+	router.POST("/schedules/:id/pause", require(auth.ActionManageSchedules), scheduleScope, ownSchedule, func(c *gin.Context) {
+		schedule, err := jobScheduler.PauseSchedule(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Schedule not found"})
+			return
+		}
+		
+		c.JSON(http.StatusOK, gin.H{
+			"schedule_id": schedule.ID,
+			"paused": schedule.Paused,
+		})
+	})
+	
+	router.POST("/schedules/:id/resume", require(auth.ActionManageSchedules), scheduleScope, ownSchedule, func(c *gin.Context) {
+		schedule, err := jobScheduler.ResumeSchedule(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Schedule not found"})
+			return
+		}
+		
+		c.JSON(http.StatusOK, gin.H{
+			"schedule_id": schedule.ID,
+			"paused": schedule.Paused,
+			"next_run": schedule.NextRun,
+		})
+	})
+	
+	router.DELETE("/schedules/:id", require(auth.ActionManageSchedules), scheduleScope, ownSchedule, func(c *gin.Context) {
+		if err := jobScheduler.DeleteSchedule(c.Param("id")); err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Schedule not found"})
+			return
+		}
+		
+		c.Status(http.StatusNoContent)
+	})
+	
+	router.POST("/workers", require(auth.ActionRegisterWorker), func(c *gin.Context) {
 		var workerRequest struct {
 			Name     string `json:"name" binding:"required"`
 			CPUCores int    `json:"cpu_cores" binding:"required"`
 			MemoryMB int    `json:"memory_mb" binding:"required"`
+			Labels   map[string]string `json:"labels"`
+			Taints   []string          `json:"taints"` // e.g. "dedicated=ml:NoSchedule"
+			Namespace string           `json:"namespace"` // Only run this namespace's jobs; empty to run any
 		}
 		
 		if err := c.ShouldBindJSON(&workerRequest); err != nil {
@@ -92,39 +929,723 @@ func main() {
 			return
 		}
 		
+		if err := labels.Validate(workerRequest.Labels); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		
+		// Shared workers have no namespace, so only unlimited tokens register them
+		if !inScope(c, workerRequest.Namespace) {
+			return
+		}
+		
 		worker := models.NewWorker(workerRequest.Name, workerRequest.CPUCores, workerRequest.MemoryMB)
+		worker.Labels = workerRequest.Labels
+		worker.Namespace = workerRequest.Namespace
+		for _, expr := range workerRequest.Taints {
+			taint, err := labels.ParseTaint(expr)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			worker.Taints = append(worker.Taints, taint)
+		}
 		
 		// Register the worker
 		if err := jobScheduler.RegisterWorker(worker); err != nil {
+			if namespaceError(c, err) {
+				return
+			}
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to register worker"})
 			return
 		}
 		
+		// From now on a worker token may only act as this worker
+		if *authEnabled {
+			if token := c.MustGet("token").(*models.Token); token.Kind == models.TokenWorker {
+				if err := authenticator.BindWorker(token.ID, worker.ID); err != nil {
+					log.Printf("Error binding token %s to worker %s: %v", token.ID, worker.ID, err)
+					c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to register worker"})
+					return
+				}
+			}
+		}
+		
 		c.JSON(http.StatusCreated, gin.H{
 			"worker_id": worker.ID,
 			"status": worker.Status,
 		})
 	})
 	
-	router.GET("/workers", func(c *gin.Context) {
-		workers, err := memoryStorage.GetAvailableWorkers()
+	router.POST("/workers/:id/heartbeat", require(auth.ActionRunJobs), workerScope, ownWorker, func(c *gin.Context) {
+		workerID := c.Param("id")
+		
+		worker, err := jobScheduler.Heartbeat(workerID)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Worker not found"})
+			return
+		}
+		
+		c.JSON(http.StatusOK, gin.H{
+			"worker_id": worker.ID,
+			"status": worker.Status,
+		})
+	})
+	
+	// List all workers, or those that run a namespace's jobs (?namespace=),
+	// which includes the shared ones. Tokens limited to some namespaces see
+	// theirs and the shared ones.
+	router.GET("/workers", require(auth.ActionView), func(c *gin.Context) {
+		workers, err := jobScheduler.Workers()
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get workers"})
 			return
 		}
 		
-		c.JSON(http.StatusOK, workers)
+		namespace := c.Query("namespace")
+		workers = slices.DeleteFunc(workers, func(worker *models.Worker) bool {
+			if worker.Namespace == "" {
+				return false
+			}
+			return (namespace != "" && worker.Namespace != namespace) || !visible(c, worker.Namespace)
+		})
+		
+		// Show free capacity next to what is allocated
+		type workerView struct {
+			*models.Worker
+			Free models.Resources
+		}
+		views := make([]workerView, 0, len(workers))
+		for _, worker := range workers {
+			views = append(views, workerView{Worker: worker, Free: worker.Free()})
+		}
+		
+		c.JSON(http.StatusOK, views)
+	})
+	
+	// Take a worker out of rotation; jobs it is running carry on
+	router.POST("/workers/:id/cordon", require(auth.ActionManageWorkers), workerScope, func(c *gin.Context) {
+		worker, err := jobScheduler.CordonWorker(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Worker not found"})
+			return
+		}
+		
+		c.JSON(http.StatusOK, gin.H{
+			"worker_id": worker.ID,
+			"status": worker.Status,
+		})
+	})
+	
+	router.POST("/workers/:id/uncordon", require(auth.ActionManageWorkers), workerScope, func(c *gin.Context) {
+		worker, err := jobScheduler.UncordonWorker(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Worker not found"})
+			return
+		}
+		
+		c.JSON(http.StatusOK, gin.H{
+			"worker_id": worker.ID,
+			"status": worker.Status,
+		})
+	})
+	
+	// Keep jobs that do not tolerate the taint off a worker
+	router.POST("/workers/:id/taints", require(auth.ActionManageWorkers), workerScope, func(c *gin.Context) {
+		var taintRequest struct {
+			Taint string `json:"taint" binding:"required"` // key=value:Effect
+		}
+		
+		if err := c.ShouldBindJSON(&taintRequest); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		
+		taint, err := labels.ParseTaint(taintRequest.Taint)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		
+		worker, err := jobScheduler.TaintWorker(c.Param("id"), taint)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Worker not found"})
+			return
+		}
+		
+		c.JSON(http.StatusOK, gin.H{
+			"worker_id": worker.ID,
+			"taints": worker.Taints,
+		})
+	})
+	
+	router.DELETE("/workers/:id/taints/:key", require(auth.ActionManageWorkers), workerScope, func(c *gin.Context) {
+		worker, err := jobScheduler.UntaintWorker(c.Param("id"), c.Param("key"))
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Worker not found"})
+			return
+		}
+		
+		c.JSON(http.StatusOK, gin.H{
+			"worker_id": worker.ID,
+			"taints": worker.Taints,
+		})
+	})
+	
+	// Cordon a worker and let its running jobs finish, optionally waiting for them (?wait=5m)
+	router.POST("/workers/:id/drain", require(auth.ActionManageWorkers), workerScope, func(c *gin.Context) {
+		workerID := c.Param("id")
+		
+		wait, err := time.ParseDuration(c.DefaultQuery("wait", "0s"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid wait duration"})
+			return
+		}
+		
+		worker, err := jobScheduler.DrainWorker(workerID)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Worker not found"})
+			return
+		}
+		
+		if wait > 0 {
+			ctx, cancel := context.WithTimeout(c.Request.Context(), wait)
+			defer cancel()
+			
+			// Still draining when the wait runs out; the status below says so
+			jobScheduler.WaitWorkerDrained(ctx, workerID)
+			if worker, err = jobScheduler.Worker(workerID); err != nil {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Worker not found"})
+				return
+			}
+		}
+		
+		c.JSON(http.StatusOK, gin.H{
+			"worker_id": worker.ID,
+			"status": worker.Status,
+		})
+	})
+	
+	// Remove a worker; ?force=true requeues the jobs it is still running
+	router.DELETE("/workers/:id", require(auth.ActionManageWorkers), workerScope, func(c *gin.Context) {
+		err := jobScheduler.RemoveWorker(c.Param("id"), c.Query("force") == "true")
+		if err != nil {
+			if err == scheduler.ErrWorkerBusy {
+				c.JSON(http.StatusConflict, gin.H{"error": "Worker still has jobs running, drain it first or use force=true"})
+				return
+			}
+			c.JSON(http.StatusNotFound, gin.H{"error": "Worker not found"})
+			return
+		}
+		
+		c.Status(http.StatusNoContent)
+	})
+	
+	// Namespaces partition jobs, schedules and workers, each with its own quota
+	router.POST("/namespaces", require(auth.ActionManageNamespaces), func(c *gin.Context) {
+		var namespaceRequest struct {
+			Name   string `json:"name" binding:"required"`
+			Weight int    `json:"weight"` // Share of the cluster relative to other namespaces, defaults to 1
+			quotaRequest
+		}
+		
+		if err := c.ShouldBindJSON(&namespaceRequest); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err := labels.Validate(map[string]string{"namespace": namespaceRequest.Name}); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid namespace name %q", namespaceRequest.Name)})
+			return
+		}
+		if !inScope(c, namespaceRequest.Name) {
+			return
+		}
+		quota, err := namespaceRequest.quota()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if namespaceRequest.Weight < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Weight cannot be negative"})
+			return
+		}
+		
+		namespace := &models.Namespace{Name: namespaceRequest.Name, Quota: quota, Weight: namespaceRequest.Weight}
+		if err := jobScheduler.CreateNamespace(namespace); err != nil {
+			if err == scheduler.ErrNamespaceExists {
+				c.JSON(http.StatusConflict, gin.H{"error": "Namespace already exists"})
+				return
+			}
+			log.Printf("Error creating namespace %s: %v", namespace.Name, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save namespace"})
+			return
+		}
+		
+		c.JSON(http.StatusCreated, namespace)
+	})
+	
+	// Show each namespace's quota next to what its jobs use
+	type namespaceView struct {
+		*models.Namespace
+		Usage models.Usage
+	}
+	
+	router.GET("/namespaces", require(auth.ActionView), func(c *gin.Context) {
+		namespaces, err := jobScheduler.Namespaces()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get namespaces"})
+			return
+		}
+		
+		views := make([]namespaceView, 0, len(namespaces))
+		for _, namespace := range namespaces {
+			if !visible(c, namespace.Name) {
+				continue
+			}
+			usage, err := jobScheduler.Usage(namespace.Name)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get namespace usage"})
+				return
+			}
+			views = append(views, namespaceView{Namespace: namespace, Usage: usage})
+		}
+		
+		c.JSON(http.StatusOK, views)
+	})
+	
+	router.GET("/namespaces/:name", require(auth.ActionView), namespaceScope, func(c *gin.Context) {
+		namespace, err := jobScheduler.Namespace(c.Param("name"))
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Namespace not found"})
+			return
+		}
+		usage, err := jobScheduler.Usage(namespace.Name)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get namespace usage"})
+			return
+		}
+		
+		c.JSON(http.StatusOK, namespaceView{Namespace: namespace, Usage: usage})
+	})
+	
+	router.PUT("/namespaces/:name/quota", require(auth.ActionManageNamespaces), namespaceScope, func(c *gin.Context) {
+		var quotaRequest quotaRequest
+		
+		if err := c.ShouldBindJSON(&quotaRequest); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		quota, err := quotaRequest.quota()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		
+		namespace, err := jobScheduler.SetQuota(c.Param("name"), quota)
+		if err != nil {
+			if errors.Is(err, scheduler.ErrUnknownNamespace) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Namespace not found"})
+				return
+			}
+			log.Printf("Error setting quota of namespace %s: %v", c.Param("name"), err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save namespace"})
+			return
+		}
+		
+		c.JSON(http.StatusOK, namespace)
+	})
+	
+	// Change a namespace's share of the cluster under fair-share scheduling
+	router.PUT("/namespaces/:name/weight", require(auth.ActionManageNamespaces), namespaceScope, func(c *gin.Context) {
+		var weightRequest struct {
+			Weight int `json:"weight" binding:"required,min=1"`
+		}
+		
+		if err := c.ShouldBindJSON(&weightRequest); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		
+		namespace, err := jobScheduler.SetWeight(c.Param("name"), weightRequest.Weight)
+		if err != nil {
+			if errors.Is(err, scheduler.ErrUnknownNamespace) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Namespace not found"})
+				return
+			}
+			log.Printf("Error setting weight of namespace %s: %v", c.Param("name"), err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save namespace"})
+			return
+		}
+		
+		c.JSON(http.StatusOK, namespace)
+	})
+	
+	// Delete a namespace once it has no unfinished jobs, schedules or workers
+	router.DELETE("/namespaces/:name", require(auth.ActionManageNamespaces), namespaceScope, func(c *gin.Context) {
+		if err := jobScheduler.DeleteNamespace(c.Param("name")); err != nil {
+			if errors.Is(err, scheduler.ErrNamespaceInUse) {
+				c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusNotFound, gin.H{"error": "Namespace not found"})
+			return
+		}
+		
+		c.Status(http.StatusNoContent)
+	})
+	
+	// Issue a token; its secret is only ever shown in this response
+	router.POST("/tokens", require(auth.ActionManageTokens), func(c *gin.Context) {
+		var tokenRequest struct {
+			Name       string   `json:"name" binding:"required"`
+			Kind       string   `json:"kind" binding:"required"`
+			Role       string   `json:"role"`       // Role of a user token, defaults to submitter
+			Namespaces []string `json:"namespaces"` // Namespaces the token may act in; all of them if empty
+			ExpiresIn  string   `json:"expires_in"` // e.g. 720h; never expires if empty
+		}
+		
+		if err := c.ShouldBindJSON(&tokenRequest); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		
+		for _, namespace := range tokenRequest.Namespaces {
+			if err := labels.Validate(map[string]string{"namespace": namespace}); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid namespace name %q", namespace)})
+				return
+			}
+		}
+		
+		var ttl time.Duration
+		if tokenRequest.ExpiresIn != "" {
+			expiresIn, err := time.ParseDuration(tokenRequest.ExpiresIn)
+			if err != nil || expiresIn <= 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid expires_in %q", tokenRequest.ExpiresIn)})
+				return
+			}
+			ttl = expiresIn
+		}
+		
+		token, secret, err := authenticator.Issue(tokenRequest.Name, tokenRequest.Kind, tokenRequest.Role, tokenRequest.Namespaces, ttl)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		log.Printf("Issued %s token %s (%s) with role %s", token.Kind, token.ID, token.Name, auth.RoleOf(token))
+		
+		c.JSON(http.StatusCreated, gin.H{
+			"token_id": token.ID,
+			"kind": token.Kind,
+			"role": auth.RoleOf(token),
+			"namespaces": token.Namespaces,
+			"secret": secret,
+			"expires_at": token.ExpiresAt,
+		})
+	})
+	
+	router.GET("/tokens", require(auth.ActionManageTokens), func(c *gin.Context) {
+		// Leave out the hashes of the secrets
+		type tokenView struct {
+			ID         string
+			Name       string
+			Kind       string
+			Role       string
+			Namespaces []string
+			CreateTime time.Time
+			ExpiresAt  time.Time
+		}
+		tokens := authenticator.Tokens()
+		views := make([]tokenView, 0, len(tokens))
+		for _, token := range tokens {
+			views = append(views, tokenView{ID: token.ID, Name: token.Name, Kind: token.Kind, Role: auth.RoleOf(token), Namespaces: token.Namespaces, CreateTime: token.CreateTime, ExpiresAt: token.ExpiresAt})
+		}
+		
+		c.JSON(http.StatusOK, views)
+	})
+	
+	router.DELETE("/tokens/:id", require(auth.ActionManageTokens), func(c *gin.Context) {
+		if err := authenticator.Revoke(c.Param("id")); err != nil {
+			if err == auth.ErrUnknownToken {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Token not found"})
+				return
+			}
+			log.Printf("Error revoking token %s: %v", c.Param("id"), err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke token"})
+			return
+		}
+		log.Printf("Revoked token %s", c.Param("id"))
+		
+		c.Status(http.StatusNoContent)
+	})
+	
+	// Queue depth and how long jobs waited before being placed
+	router.GET("/metrics", require(auth.ActionView), func(c *gin.Context) {
+		c.JSON(http.StatusOK, jobScheduler.Metrics())
+	})
+	
+	// drainStatus reports whether the scheduler is drained and how much work is left
+	drainStatus := func(c *gin.Context) {
+		jobs, err := jobScheduler.Jobs()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get jobs"})
+			return
+		}
+		
+		running := 0
+		for _, job := range jobs {
+			if job.Status == "running" || job.Status == "cancelling" {
+				running++
+			}
+		}
+		
+		c.JSON(http.StatusOK, gin.H{
+			"draining": jobScheduler.Draining(),
+			"running": running,
+			"queued": jobQueue.Size(),
+		})
+	}
+	
+	// Stop placing queued jobs while running ones finish, e.g. before a rolling upgrade
+	router.POST("/admin/drain", require(auth.ActionAdminister), func(c *gin.Context) {
+		jobScheduler.Drain()
+		drainStatus(c)
+	})
+	
+	router.POST("/admin/resume", require(auth.ActionAdminister), func(c *gin.Context) {
+		jobScheduler.Resume()
+		drainStatus(c)
+	})
+	
+	router.GET("/admin/drain", require(auth.ActionAdminister), drainStatus)
+	
+	// Long-poll endpoint for worker agents to pick up their next job or cancellation
+	router.GET("/workers/:id/assignment", require(auth.ActionRunJobs), workerScope, ownWorker, func(c *gin.Context) {
+		workerID := c.Param("id")
+		
+		wait, err := time.ParseDuration(c.DefaultQuery("wait", "30s"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid wait duration"})
+			return
+		}
+		
+		ctx, cancel := context.WithTimeout(c.Request.Context(), wait)
+		defer cancel()
+		
+		assignment, err := jobScheduler.NextAssignment(ctx, workerID)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Worker not found"})
+			return
+		}
+		if assignment == nil {
+			c.Status(http.StatusNoContent)
+			return
+		}
+		
+		c.JSON(http.StatusOK, assignment)
 	})
 	
 	// Print server info
 	fmt.Println("Job Scheduler Server started on :8080")
-	fmt.Println("Available endpoints:")
-	fmt.Println("  POST /jobs - Create a new job")
-	fmt.Println("  GET /jobs - List all jobs")
+	fmt.Println("Available endpoints, with an Authorization: Bearer TOKEN header whose role allows them:")
+	fmt.Println("  POST /jobs - Create a new job, optionally delayed with run_at or delay and placed by selector or affinity")
+	fmt.Println("  GET /jobs - List all jobs (?namespace=)")
 	fmt.Println("  GET /jobs/:id - Get job details")
-	fmt.Println("  POST /workers - Register a new worker")
-	fmt.Println("  GET /workers - List all workers")
+	fmt.Println("  GET /jobs/:id/logs - Get job output (?stream=, offset=, tail=, follow=true)")
+	fmt.Println("  POST /jobs/:id/logs - Upload job output (worker agents)")
+	fmt.Println("  POST /jobs/:id/cancel - Cancel a job (?grace=10s)")
+	fmt.Println("  POST /jobs/:id/result - Report a job result (worker agents)")
+	fmt.Println("  POST /workflows - Submit jobs with dependencies between them")
+	fmt.Println("  GET /workflows/:id - Get the status of every job in a workflow")
+	fmt.Println("  POST /schedules - Create a recurring job from a cron expression")
+	fmt.Println("  GET /schedules - List all schedules (?namespace=)")
+	fmt.Println("  GET /schedules/:id - Get schedule details")
+	fmt.Println("  POST /schedules/:id/pause - Pause a schedule")
+	fmt.Println("  POST /schedules/:id/resume - Resume a paused schedule")
+	fmt.Println("  DELETE /schedules/:id - Delete a schedule")
+	fmt.Println("  POST /workers - Register a new worker, optionally with labels, taints and a namespace")
+	fmt.Println("  GET /workers - List all workers (?namespace= for those running a namespace's jobs)")
+	fmt.Println("  POST /workers/:id/cordon - Stop placing new jobs on a worker")
+	fmt.Println("  POST /workers/:id/uncordon - Put a cordoned or drained worker back in rotation")
+	fmt.Println("  POST /workers/:id/taints - Add a taint to a worker")
+	fmt.Println("  DELETE /workers/:id/taints/:key - Remove a worker's taints with the given key")
+	fmt.Println("  POST /workers/:id/drain - Cordon a worker and let its jobs finish (?wait=5m)")
+	fmt.Println("  DELETE /workers/:id - Remove a worker (?force=true requeues its running jobs)")
+	fmt.Println("  POST /workers/:id/heartbeat - Report that a worker is alive (worker agents)")
+	fmt.Println("  GET /workers/:id/assignment - Wait for the next job or cancellation (worker agents)")
+	fmt.Println("  POST /namespaces - Create a namespace, optionally with a quota and weight")
+	fmt.Println("  GET /namespaces - List namespaces with their quota and usage")
+	fmt.Println("  GET /namespaces/:name - Get a namespace's quota and usage")
+	fmt.Println("  PUT /namespaces/:name/quota - Set a namespace's quota")
+	fmt.Println("  PUT /namespaces/:name/weight - Set a namespace's fair share of the cluster")
+	fmt.Println("  DELETE /namespaces/:name - Delete an unused namespace")
+	fmt.Println("  POST /tokens - Issue an admin, user or worker API token, optionally with a role and limited to namespaces (admins)")
+	fmt.Println("  GET /tokens - List API tokens (admins)")
+	fmt.Println("  DELETE /tokens/:id - Revoke an API token (admins)")
+	fmt.Println("  GET /metrics - Queue depth and job wait times")
+	fmt.Println("  POST /admin/drain - Stop placing new jobs while running ones finish")
+	fmt.Println("  POST /admin/resume - Start placing jobs again after a drain")
+	fmt.Println("  GET /admin/drain - Show whether the server is drained and how many jobs are still running")
 	
 	// Start the server
-	router.Run(":8080")
+	server := &http.Server{Addr: ":8080", Handler: router}
+	// Long-lived requests such as followed logs and assignment polls end as soon as shutdown begins
+	requestCtx, cancelRequests := context.WithCancel(context.Background())
+	server.BaseContext = func(net.Listener) context.Context { return requestCtx }
+	server.RegisterOnShutdown(cancelRequests)
+	go func() {
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("Server failed: %v", err)
+		}
+	}()
+	
+	// Wait for SIGINT or SIGTERM
+	signalCtx, stopSignals := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	<-signalCtx.Done()
+	stopSignals()
+	log.Println("Shutting down, press Ctrl+C again to force")
+	
+	// Refuse new jobs, let the scheduler finish its current pass, then let
+	// in-flight requests complete before flushing storage
+	shuttingDown.Store(true)
+	ctx, cancel := context.WithTimeout(context.Background(), *shutdownTimeout)
+	defer cancel()
+	if err := jobScheduler.Stop(ctx); err != nil {
+		log.Printf("Scheduler did not stop cleanly: %v", err)
+	}
+	if err := server.Shutdown(ctx); err != nil {
+		log.Printf("HTTP server did not shut down cleanly: %v", err)
+	}
+	if closer, ok := jobStorage.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			log.Printf("Failed to close storage: %v", err)
+		}
+	}
+	log.Println("Server stopped")
+}
+
+// jobRequest is the body of POST /jobs, and of each job in POST /workflows
+type jobRequest struct {
+	Name            string   `json:"name" binding:"required"`
+	Namespace       string   `json:"namespace"` // Defaults to the default namespace; ignored in workflows, which set their own
+	Command         string   `json:"command" binding:"required"`
+	Args            []string `json:"args"`
+	CPUCores        *int     `json:"cpu_cores"`
+	MemoryMB        int      `json:"memory_mb"`
+	Policy          string   `json:"policy"`
+	Priority        *int     `json:"priority"`
+	MaxAttempts     int      `json:"max_attempts"`
+	Backoff         string   `json:"backoff"`
+	BackoffDelay    string   `json:"backoff_delay"`
+	BackoffMaxDelay string   `json:"backoff_max_delay"`
+	
+	Timeout  string     `json:"timeout"`
+	StartBy  *time.Time `json:"start_by"`
+	FinishBy *time.Time `json:"finish_by"`
+	RunAt    *time.Time `json:"run_at"`
+	Delay    string     `json:"delay"`
+	
+	Selector    string              `json:"selector"`    // Labels a worker must have, e.g. "disk=ssd,zone in (a,b),!gpu"
+	Preferences []preferenceRequest `json:"preferences"` // Labels that make a worker preferred
+	Tolerations []string            `json:"tolerations"` // Worker taints the job tolerates, e.g. "dedicated=ml:NoSchedule"
+	
+	Labels   map[string]string `json:"labels"`   // Labels other jobs' affinity rules can select this one by
+	Group    string            `json:"group"`    // Group other jobs' affinity rules can refer to
+	Affinity []string          `json:"affinity"` // Placement relative to other jobs, e.g. "same-worker:@cache"
+	
+	DependsOn []string `json:"depends_on"` // Names of other jobs in the same workflow
+}
+
+// preferenceRequest is a soft placement constraint in a jobRequest
+type preferenceRequest struct {
+	Selector string `json:"selector" binding:"required"`
+	Weight   int    `json:"weight"` // Defaults to 1
+}
+
+// quotaRequest sets a namespace's quota; zero or missing fields mean no limit
+type quotaRequest struct {
+	MaxRunning int `json:"max_running"`
+	MaxQueued  int `json:"max_queued"`
+	CPUCores   int `json:"cpu_cores"`
+	MemoryMB   int `json:"memory_mb"`
+}
+
+// quota checks the request and converts it to a models.Quota
+func (r quotaRequest) quota() (models.Quota, error) {
+	if r.MaxRunning < 0 || r.MaxQueued < 0 || r.CPUCores < 0 || r.MemoryMB < 0 {
+		return models.Quota{}, errors.New("Quota limits cannot be negative")
+	}
+	return models.Quota{
+		MaxRunning: r.MaxRunning,
+		MaxQueued:  r.MaxQueued,
+		Resources:  models.Resources{CPUCores: r.CPUCores, MemoryMB: r.MemoryMB},
+	}, nil
+}
+
+// inNamespace reports whether something with the given Namespace field is in
+// a namespace
+func inNamespace(field, namespace string) bool {
+	return models.NamespaceOf(field) == namespace
+}
+
+// storageBackend persists the scheduler's state and the API tokens
+type storageBackend interface {
+	scheduler.Storage
+	auth.Storage
+}
+
+// openStorage creates the storage backend selected on the command line
+func openStorage(backend, dataFile, walDir string, walCompactEvery int, walSync bool) (storageBackend, error) {
+	switch backend {
+	case "memory":
+		return storage.NewMemoryStorage(), nil
+	case "file":
+		return storage.NewFileStorage(dataFile)
+	case "wal":
+		return storage.NewWALStorage(walDir, walCompactEvery, walSync)
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", backend)
+	}
+}
+
+// writeSecret writes a secret to a file only its owner can read, replacing
+// the file if it exists
+func writeSecret(path, secret string) error {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintln(file, secret); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// parseBackoff builds a retry backoff from the fields of a job request
+func parseBackoff(strategy, delay, maxDelay string) (models.Backoff, error) {
+	backoff := models.Backoff{Strategy: models.BackoffFixed, Delay: 10 * time.Second}
+	
+	switch strategy {
+	case "":
+	case models.BackoffFixed, models.BackoffExponential:
+		backoff.Strategy = strategy
+	default:
+		return backoff, fmt.Errorf("backoff must be %s or %s", models.BackoffFixed, models.BackoffExponential)
+	}
+	
+	var err error
+	if delay != "" {
+		if backoff.Delay, err = time.ParseDuration(delay); err != nil || backoff.Delay < 0 {
+			return backoff, fmt.Errorf("invalid backoff_delay %q", delay)
+		}
+	}
+	if maxDelay != "" {
+		if backoff.MaxDelay, err = time.ParseDuration(maxDelay); err != nil || backoff.MaxDelay < 0 {
+			return backoff, fmt.Errorf("invalid backoff_max_delay %q", maxDelay)
+		}
+	}
+	return backoff, nil
 }
\ No newline at end of file
@@ -0,0 +1,230 @@
+// Package auth issues the bearer tokens API clients and worker agents
+// authenticate with, and checks the tokens they present
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Shishir_grez/coltnode/nodes/job_scheduler/pkg/models"
+	"github.com/google/uuid"
+)
+
+// secretPrefix starts every token secret, so leaked secrets are easy to spot
+const secretPrefix = "cnt_"
+
+// BootstrapID is the ID of the admin token given on the server's command
+// line, which is not stored and cannot be revoked
+const BootstrapID = "bootstrap"
+
+var (
+	// ErrInvalidToken is returned when a secret matches no token, or an expired one
+	ErrInvalidToken = errors.New("invalid or expired token")
+	// ErrUnknownToken is returned when revoking a token that does not exist
+	ErrUnknownToken = errors.New("token not found")
+)
+
+// Storage persists issued tokens
+type Storage interface {
+	SaveToken(*models.Token) error
+	DeleteToken(id string) error
+	GetAllTokens() ([]*models.Token, error)
+}
+
+// Authenticator issues, checks and revokes tokens
+type Authenticator struct {
+	storage   Storage
+	mu        sync.RWMutex
+	byHash    map[string]*models.Token // Issued tokens, keyed by the hash of their secret
+	adminHash string                   // Hash of the bootstrap admin secret, if one was given
+}
+
+// New creates an authenticator for the tokens in storage. If adminSecret is
+// not empty it is accepted as an admin token, e.g. to create the first tokens.
+func New(storage Storage, adminSecret string) (*Authenticator, error) {
+	tokens, err := storage.GetAllTokens()
+	if err != nil {
+		return nil, err
+	}
+
+	a := &Authenticator{storage: storage, byHash: make(map[string]*models.Token)}
+	for _, token := range tokens {
+		a.byHash[token.Hash] = token
+	}
+	if adminSecret != "" {
+		a.adminHash = hash(adminSecret)
+	}
+	return a, nil
+}
+
+// HasAdmin reports whether any admin token can be used: the bootstrap one or
+// an issued one that has not expired
+func (a *Authenticator) HasAdmin() bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if a.adminHash != "" {
+		return true
+	}
+	now := time.Now()
+	for _, token := range a.byHash {
+		if token.Kind == models.TokenAdmin && !token.Expired(now) {
+			return true
+		}
+	}
+	return false
+}
+
+// Issue creates a token of the given kind and returns it with its secret,
+// which is not stored and cannot be recovered later. Only user tokens have a
+// role, which defaults to submitter. A token limited to namespaces may only
+// act in those. A ttl of 0 means the token never expires.
+func (a *Authenticator) Issue(name, kind, role string, namespaces []string, ttl time.Duration) (*models.Token, string, error) {
+	switch kind {
+	case models.TokenUser:
+		if role == "" {
+			role = models.RoleSubmitter
+		}
+		if !IsRole(role) {
+			return nil, "", fmt.Errorf("role must be %s, %s, %s or %s", models.RoleViewer, models.RoleSubmitter, models.RoleOperator, models.RoleAdmin)
+		}
+	case models.TokenAdmin, models.TokenWorker:
+		if role != "" {
+			return nil, "", fmt.Errorf("only %s tokens have a role", models.TokenUser)
+		}
+	default:
+		return nil, "", fmt.Errorf("token kind must be %s, %s or %s", models.TokenAdmin, models.TokenUser, models.TokenWorker)
+	}
+	if slices.Contains(namespaces, "") {
+		return nil, "", errors.New("namespaces must not be empty")
+	}
+
+	secret, err := NewSecret()
+	if err != nil {
+		return nil, "", err
+	}
+
+	token := &models.Token{
+		ID:         uuid.New().String(),
+		Name:       name,
+		Kind:       kind,
+		Role:       role,
+		Namespaces: namespaces,
+		Hash:       hash(secret),
+		CreateTime: time.Now(),
+	}
+	if ttl > 0 {
+		token.ExpiresAt = token.CreateTime.Add(ttl)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if err := a.storage.SaveToken(token); err != nil {
+		return nil, "", err
+	}
+	a.byHash[token.Hash] = token
+	return token, secret, nil
+}
+
+// Authenticate returns the token a secret belongs to
+func (a *Authenticator) Authenticate(secret string) (*models.Token, error) {
+	if secret == "" {
+		return nil, ErrInvalidToken
+	}
+	h := hash(secret)
+
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if a.adminHash != "" && subtle.ConstantTimeCompare([]byte(h), []byte(a.adminHash)) == 1 {
+		return &models.Token{ID: BootstrapID, Name: "bootstrap admin token", Kind: models.TokenAdmin}, nil
+	}
+	token, exists := a.byHash[h]
+	if !exists || token.Expired(time.Now()) {
+		return nil, ErrInvalidToken
+	}
+	return token, nil
+}
+
+// BindWorker ties a worker token to the worker it registered, replacing any
+// worker it registered before. Tokens are replaced rather than changed, so
+// those already handed out by Authenticate are safe to read.
+func (a *Authenticator) BindWorker(tokenID, workerID string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for h, token := range a.byHash {
+		if token.ID != tokenID {
+			continue
+		}
+		if token.Kind != models.TokenWorker {
+			return fmt.Errorf("only %s tokens are bound to a worker", models.TokenWorker)
+		}
+		bound := *token
+		bound.WorkerID = workerID
+		if err := a.storage.SaveToken(&bound); err != nil {
+			return err
+		}
+		a.byHash[h] = &bound
+		return nil
+	}
+	return ErrUnknownToken
+}
+
+// Tokens returns every issued token, oldest first
+func (a *Authenticator) Tokens() []*models.Token {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	tokens := make([]*models.Token, 0, len(a.byHash))
+	for _, token := range a.byHash {
+		tokens = append(tokens, token)
+	}
+	sort.Slice(tokens, func(i, j int) bool {
+		return tokens[i].CreateTime.Before(tokens[j].CreateTime)
+	})
+	return tokens
+}
+
+// Revoke deletes a token, so its secret is no longer accepted
+func (a *Authenticator) Revoke(id string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for h, token := range a.byHash {
+		if token.ID == id {
+			if err := a.storage.DeleteToken(id); err != nil {
+				return err
+			}
+			delete(a.byHash, h)
+			return nil
+		}
+	}
+	return ErrUnknownToken
+}
+
+// NewSecret generates a random token secret
+func NewSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return secretPrefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hash returns the hex-encoded SHA-256 of a secret. Secrets are random and
+// long, so a fast unsalted hash is enough to keep them from being read back.
+func hash(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,76 @@
+package auth
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Shishir_grez/coltnode/nodes/job_scheduler/internal/storage"
+	"github.com/Shishir_grez/coltnode/nodes/job_scheduler/pkg/models"
+)
+
+func TestBindWorker(t *testing.T) {
+	tests := []struct {
+		name    string
+		kind    string
+		role    string
+		wantErr bool
+	}{
+		{name: "worker token", kind: models.TokenWorker},
+		{name: "user token", kind: models.TokenUser, role: models.RoleOperator, wantErr: true},
+		{name: "admin token", kind: models.TokenAdmin, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, err := New(storage.NewMemoryStorage(), "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			token, secret, err := a.Issue(tt.name, tt.kind, tt.role, nil, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			before, err := a.Authenticate(secret)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = a.BindWorker(token.ID, "w1")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("BindWorker() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			after, err := a.Authenticate(secret)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if after.WorkerID != "w1" {
+				t.Errorf("WorkerID = %q, want w1", after.WorkerID)
+			}
+			if before.WorkerID != "" {
+				t.Errorf("token handed out before binding changed to WorkerID %q", before.WorkerID)
+			}
+
+			// Registering again moves the binding
+			if err := a.BindWorker(token.ID, "w2"); err != nil {
+				t.Fatal(err)
+			}
+			if after, _ := a.Authenticate(secret); after.WorkerID != "w2" {
+				t.Errorf("WorkerID = %q after registering again, want w2", after.WorkerID)
+			}
+		})
+	}
+}
+
+func TestBindWorkerUnknownToken(t *testing.T) {
+	a, err := New(storage.NewMemoryStorage(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := a.BindWorker("missing", "w1"); !errors.Is(err, ErrUnknownToken) {
+		t.Errorf("BindWorker() error = %v, want %v", err, ErrUnknownToken)
+	}
+}
@@ -0,0 +1,86 @@
+package auth
+
+import (
+	"slices"
+
+	"github.com/Shishir_grez/coltnode/nodes/job_scheduler/pkg/models"
+)
+
+// Actions a token may be allowed to take
+const (
+	ActionView               = "view"                 // Read jobs, workflows, schedules, workers, namespaces, logs and metrics
+	ActionSubmit             = "submit"               // Submit jobs and workflows, and cancel one's own jobs
+	ActionCancelAny          = "cancel-any"           // Cancel jobs submitted with other tokens
+	ActionManageSchedules    = "manage-schedules"     // Create schedules, and pause, resume and delete one's own
+	ActionManageAnySchedules = "manage-any-schedules" // Pause, resume and delete schedules created with other tokens
+	ActionRegisterWorker     = "register-worker"      // Register a worker
+	ActionManageWorkers      = "manage-workers"       // Cordon, uncordon, taint, drain and remove workers
+	ActionRunJobs            = "run-jobs"             // Heartbeat, pick up assignments and report job output and results
+	ActionManageNamespaces   = "manage-namespaces"    // Create and delete namespaces, and set their quotas and weights
+	ActionManageTokens       = "manage-tokens"        // Issue, list and revoke tokens
+	ActionAdminister         = "administer"           // Drain and resume the whole scheduler
+)
+
+// roleWorker is the role of worker tokens, which only worker agents use
+const roleWorker = models.TokenWorker
+
+// permissions lists the actions each role allows
+var permissions = map[string][]string{
+	models.RoleViewer:    {ActionView},
+	models.RoleSubmitter: {ActionView, ActionSubmit, ActionManageSchedules},
+	models.RoleOperator: {
+		ActionView, ActionSubmit, ActionManageSchedules, ActionCancelAny, ActionManageAnySchedules, ActionRegisterWorker,
+		ActionManageWorkers,
+	},
+	models.RoleAdmin: {
+		ActionView, ActionSubmit, ActionManageSchedules, ActionCancelAny, ActionManageAnySchedules, ActionRegisterWorker,
+		ActionManageWorkers, ActionRunJobs, ActionManageNamespaces, ActionManageTokens, ActionAdminister,
+	},
+	roleWorker: {ActionRegisterWorker, ActionRunJobs},
+}
+
+// global actions are not about any one namespace, so tokens limited to some
+// namespaces may not take them, whatever their role
+var global = []string{ActionManageTokens, ActionAdminister}
+
+// IsRole reports whether a user token may be given the role
+func IsRole(role string) bool {
+	switch role {
+	case models.RoleViewer, models.RoleSubmitter, models.RoleOperator, models.RoleAdmin:
+		return true
+	}
+	return false
+}
+
+// RoleOf returns the role a token acts with. Admin tokens are admins and
+// worker tokens have a role of their own. User tokens issued before roles
+// existed are only viewers, so none gains access it was not given; reissue
+// them with a role to restore the rest.
+func RoleOf(token *models.Token) string {
+	switch token.Kind {
+	case models.TokenAdmin:
+		return models.RoleAdmin
+	case models.TokenWorker:
+		return roleWorker
+	}
+	if token.Role == "" {
+		return models.RoleViewer
+	}
+	return token.Role
+}
+
+// Allows reports whether a token's role allows an action. Whether the token
+// may take it in a particular namespace is up to InScope.
+func Allows(token *models.Token, action string) bool {
+	if len(token.Namespaces) > 0 && slices.Contains(global, action) {
+		return false
+	}
+	return slices.Contains(permissions[RoleOf(token)], action)
+}
+
+// InScope reports whether a token may act in a namespace. Tokens limited to
+// some namespaces may not act on what no namespace owns, such as shared
+// workers, which have an empty namespace.
+func InScope(token *models.Token, namespace string) bool {
+	return len(token.Namespaces) == 0 || slices.Contains(token.Namespaces, namespace)
+}
@@ -0,0 +1,64 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/Shishir_grez/coltnode/nodes/job_scheduler/pkg/models"
+)
+
+func TestAllows(t *testing.T) {
+	tests := []struct {
+		name   string
+		token  models.Token
+		action string
+		want   bool
+	}{
+		{name: "viewer views", token: models.Token{Kind: models.TokenUser, Role: models.RoleViewer}, action: ActionView, want: true},
+		{name: "viewer cannot submit", token: models.Token{Kind: models.TokenUser, Role: models.RoleViewer}, action: ActionSubmit, want: false},
+		{name: "submitter manages own schedules", token: models.Token{Kind: models.TokenUser, Role: models.RoleSubmitter}, action: ActionManageSchedules, want: true},
+		{name: "submitter cannot manage any schedules", token: models.Token{Kind: models.TokenUser, Role: models.RoleSubmitter}, action: ActionManageAnySchedules, want: false},
+		{name: "submitter cannot cancel any job", token: models.Token{Kind: models.TokenUser, Role: models.RoleSubmitter}, action: ActionCancelAny, want: false},
+		{name: "operator manages any schedules", token: models.Token{Kind: models.TokenUser, Role: models.RoleOperator}, action: ActionManageAnySchedules, want: true},
+		{name: "operator cannot manage namespaces", token: models.Token{Kind: models.TokenUser, Role: models.RoleOperator}, action: ActionManageNamespaces, want: false},
+		{name: "pre-role user token views", token: models.Token{Kind: models.TokenUser}, action: ActionView, want: true},
+		{name: "pre-role user token cannot submit", token: models.Token{Kind: models.TokenUser}, action: ActionSubmit, want: false},
+		{name: "pre-role user token cannot cancel any job", token: models.Token{Kind: models.TokenUser}, action: ActionCancelAny, want: false},
+		{name: "admin token manages tokens", token: models.Token{Kind: models.TokenAdmin}, action: ActionManageTokens, want: true},
+		{name: "namespaced admin cannot manage tokens", token: models.Token{Kind: models.TokenUser, Role: models.RoleAdmin, Namespaces: []string{"team-a"}}, action: ActionManageTokens, want: false},
+		{name: "namespaced admin manages namespaces", token: models.Token{Kind: models.TokenUser, Role: models.RoleAdmin, Namespaces: []string{"team-a"}}, action: ActionManageNamespaces, want: true},
+		{name: "worker runs jobs", token: models.Token{Kind: models.TokenWorker}, action: ActionRunJobs, want: true},
+		{name: "worker cannot view", token: models.Token{Kind: models.TokenWorker}, action: ActionView, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Allows(&tt.token, tt.action); got != tt.want {
+				t.Errorf("Allows(%s, %s) = %v, want %v", RoleOf(&tt.token), tt.action, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInScope(t *testing.T) {
+	tests := []struct {
+		name       string
+		namespaces []string
+		namespace  string
+		want       bool
+	}{
+		{name: "unlimited token", namespace: "team-a", want: true},
+		{name: "unlimited token on shared worker", namespace: "", want: true},
+		{name: "own namespace", namespaces: []string{"team-a"}, namespace: "team-a", want: true},
+		{name: "other namespace", namespaces: []string{"team-a"}, namespace: "team-b", want: false},
+		{name: "limited token on shared worker", namespaces: []string{"team-a"}, namespace: "", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := &models.Token{Kind: models.TokenUser, Namespaces: tt.namespaces}
+			if got := InScope(token, tt.namespace); got != tt.want {
+				t.Errorf("InScope(%v, %q) = %v, want %v", tt.namespaces, tt.namespace, got, tt.want)
+			}
+		})
+	}
+}
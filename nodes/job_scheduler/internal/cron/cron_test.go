@@ -0,0 +1,85 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		expr    string
+		wantErr bool
+	}{
+		{expr: "* * * * *"},
+		{expr: "0 0 * * 7"},
+		{expr: "  @weekly "},
+		{expr: "@YEARLY"},
+		{expr: "0-59/5 0-23 1-31 jan-dec sun-sat"},
+		{expr: "", wantErr: true},
+		{expr: "* * * *", wantErr: true},
+		{expr: "* * * * * *", wantErr: true},
+		{expr: "60 * * * *", wantErr: true},
+		{expr: "* 24 * * *", wantErr: true},
+		{expr: "* * 0 * *", wantErr: true},
+		{expr: "* * * 13 *", wantErr: true},
+		{expr: "* * * * 8", wantErr: true},
+		{expr: "*/0 * * * *", wantErr: true},
+		{expr: "*/x * * * *", wantErr: true},
+		{expr: "5-1 * * * *", wantErr: true},
+		{expr: "abc * * * *", wantErr: true},
+		{expr: "* * * foo *", wantErr: true},
+		{expr: "@sometimes", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := Parse(tt.expr)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Parse(%q) error = %v, wantErr %v", tt.expr, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNext(t *testing.T) {
+	// A Thursday
+	from := time.Date(2026, 1, 1, 10, 7, 30, 0, time.UTC)
+	at := func(month time.Month, day, hour, minute int) time.Time {
+		return time.Date(2026, month, day, hour, minute, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{expr: "* * * * *", want: at(1, 1, 10, 8)},
+		{expr: "0 * * * *", want: at(1, 1, 11, 0)},
+		{expr: "@hourly", want: at(1, 1, 11, 0)},
+		{expr: "*/15 * * * *", want: at(1, 1, 10, 15)},
+		{expr: "5/20 * * * *", want: at(1, 1, 10, 25)},
+		{expr: "0,30 * * * *", want: at(1, 1, 10, 30)},
+		{expr: "0 12 * * 1-5", want: at(1, 1, 12, 0)},
+		{expr: "30 9 * * *", want: at(1, 2, 9, 30)},
+		{expr: "@daily", want: at(1, 2, 0, 0)},
+		{expr: "0 0 * * mon", want: at(1, 5, 0, 0)},
+		{expr: "0 0 * * 7", want: at(1, 4, 0, 0)},
+		{expr: "0 0 * * 0", want: at(1, 4, 0, 0)},
+		// Either day field may match when both are restricted
+		{expr: "0 0 13 * fri", want: at(1, 2, 0, 0)},
+		{expr: "0 0 1 feb-mar *", want: at(2, 1, 0, 0)},
+		{expr: "@yearly", want: time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{expr: "0 0 30 2 *", want: time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			e, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := e.Next(from); !got.Equal(tt.want) {
+				t.Errorf("Next(%s) = %s, want %s", from, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,238 @@
+// Package labels parses worker labels and taints, and the label selectors and
+// tolerations jobs use to pick the workers they may run on
+package labels
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Shishir_grez/coltnode/nodes/job_scheduler/pkg/models"
+)
+
+var (
+	// Keys may contain a prefix separated by a slash, e.g. example.com/disk
+	keyPattern   = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9._/-]{0,61}[A-Za-z0-9])?$`)
+	valuePattern = regexp.MustCompile(`^([A-Za-z0-9]([A-Za-z0-9._-]{0,61}[A-Za-z0-9])?)?$`)
+)
+
+// Validate checks that every key and value in a set of labels is well formed
+func Validate(labels map[string]string) error {
+	for key, value := range labels {
+		if !keyPattern.MatchString(key) {
+			return fmt.Errorf("invalid label key %q", key)
+		}
+		if !valuePattern.MatchString(value) {
+			return fmt.Errorf("invalid value %q for label %s", value, key)
+		}
+	}
+	return nil
+}
+
+// ParseLabels parses a comma-separated list of key=value pairs such as
+// "disk=ssd,zone=eu-1"
+func ParseLabels(expr string) (map[string]string, error) {
+	labels := make(map[string]string)
+	for _, pair := range strings.Split(expr, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		key, value, found := strings.Cut(pair, "=")
+		if !found {
+			return nil, fmt.Errorf("label %q must be of the form key=value", pair)
+		}
+		labels[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	return labels, Validate(labels)
+}
+
+// Parse parses a selector made of comma-separated requirements, each one of
+//
+//	key=value, key==value   the label is set to value
+//	key!=value              the label is not set to value, or not set at all
+//	key in (a,b)            the label is set to one of the values
+//	key notin (a,b)         the label is not set to any of the values, or not set at all
+//	key                     the label is set, to any value
+//	!key                    the label is not set
+//
+// An empty expression gives an empty selector, which matches every worker.
+func Parse(expr string) (models.Selector, error) {
+	parts, err := splitRequirements(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	var selector models.Selector
+	for _, part := range parts {
+		requirement, err := parseRequirement(part)
+		if err != nil {
+			return nil, err
+		}
+		selector = append(selector, requirement)
+	}
+	return selector, nil
+}
+
+// splitRequirements splits a selector at the commas that are not inside the
+// parentheses of a set
+func splitRequirements(expr string) ([]string, error) {
+	var parts []string
+	depth, start := 0, 0
+	for i, r := range expr {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth < 0 {
+				return nil, fmt.Errorf("unbalanced parentheses in selector %q", expr)
+			}
+		case ',':
+			if depth == 0 {
+				parts = append(parts, expr[start:i])
+				start = i + 1
+			}
+		}
+	}
+	if depth != 0 {
+		return nil, fmt.Errorf("unbalanced parentheses in selector %q", expr)
+	}
+	parts = append(parts, expr[start:])
+
+	// Drop empty requirements, so "" and a trailing comma are accepted
+	requirements := parts[:0]
+	for _, part := range parts {
+		if part = strings.TrimSpace(part); part != "" {
+			requirements = append(requirements, part)
+		}
+	}
+	return requirements, nil
+}
+
+// parseRequirement parses a single requirement of a selector
+func parseRequirement(expr string) (models.Requirement, error) {
+	var requirement models.Requirement
+
+	if fields := strings.Fields(expr); len(fields) >= 2 && (fields[1] == models.SelectorIn || fields[1] == models.SelectorNotIn) {
+		requirement.Key = fields[0]
+		requirement.Operator = fields[1]
+		set := strings.TrimSpace(strings.Join(fields[2:], " "))
+		if !strings.HasPrefix(set, "(") || !strings.HasSuffix(set, ")") {
+			return requirement, fmt.Errorf("%q: the values after %s must be in parentheses", expr, fields[1])
+		}
+		for _, value := range strings.Split(set[1:len(set)-1], ",") {
+			requirement.Values = append(requirement.Values, strings.TrimSpace(value))
+		}
+	} else if key, value, found := strings.Cut(expr, "!="); found {
+		requirement = models.Requirement{Key: strings.TrimSpace(key), Operator: models.SelectorNotEquals, Values: []string{strings.TrimSpace(value)}}
+	} else if key, value, found := strings.Cut(expr, "="); found {
+		value = strings.TrimPrefix(value, "=")
+		requirement = models.Requirement{Key: strings.TrimSpace(key), Operator: models.SelectorEquals, Values: []string{strings.TrimSpace(value)}}
+	} else if key, found := strings.CutPrefix(expr, "!"); found {
+		requirement = models.Requirement{Key: strings.TrimSpace(key), Operator: models.SelectorNotExists}
+	} else {
+		requirement = models.Requirement{Key: expr, Operator: models.SelectorExists}
+	}
+
+	if !keyPattern.MatchString(requirement.Key) {
+		return requirement, fmt.Errorf("%q: invalid label key %q", expr, requirement.Key)
+	}
+	for _, value := range requirement.Values {
+		if !valuePattern.MatchString(value) {
+			return requirement, fmt.Errorf("%q: invalid label value %q", expr, value)
+		}
+	}
+	return requirement, nil
+}
+
+// ParseTaint parses a taint written as key=value:Effect, or key:Effect for a
+// taint without a value
+func ParseTaint(expr string) (models.Taint, error) {
+	expr = strings.TrimSpace(expr)
+	rest, effect, found := strings.Cut(expr, ":")
+	if !found {
+		return models.Taint{}, fmt.Errorf("taint %q must be of the form key=value:Effect", expr)
+	}
+	if effect != models.TaintNoSchedule && effect != models.TaintPreferNoSchedule {
+		return models.Taint{}, fmt.Errorf("taint %q: effect must be %s or %s", expr, models.TaintNoSchedule, models.TaintPreferNoSchedule)
+	}
+
+	key, value, _ := strings.Cut(rest, "=")
+	taint := models.Taint{Key: key, Value: value, Effect: effect}
+	if err := Validate(map[string]string{key: value}); err != nil {
+		return models.Taint{}, fmt.Errorf("taint %q: %v", expr, err)
+	}
+	return taint, nil
+}
+
+// ParseToleration parses a toleration written like the taints it tolerates:
+// key=value:Effect, or with the value left out to tolerate any value, or with
+// the effect left out to tolerate any effect. A lone * tolerates every taint.
+func ParseToleration(expr string) (models.Toleration, error) {
+	expr = strings.TrimSpace(expr)
+	if expr == "*" {
+		return models.Toleration{Operator: models.TolerationExists}, nil
+	}
+
+	rest, effect, _ := strings.Cut(expr, ":")
+	if effect != "" && effect != models.TaintNoSchedule && effect != models.TaintPreferNoSchedule {
+		return models.Toleration{}, fmt.Errorf("toleration %q: effect must be %s or %s", expr, models.TaintNoSchedule, models.TaintPreferNoSchedule)
+	}
+
+	toleration := models.Toleration{Key: rest, Operator: models.TolerationExists, Effect: effect}
+	if key, value, found := strings.Cut(rest, "="); found {
+		toleration = models.Toleration{Key: key, Operator: models.TolerationEqual, Value: value, Effect: effect}
+	}
+	if err := Validate(map[string]string{toleration.Key: toleration.Value}); err != nil {
+		return models.Toleration{}, fmt.Errorf("toleration %q: %v", expr, err)
+	}
+	return toleration, nil
+}
+
+// ParseAffinity parses an affinity rule written as TYPE:TARGET, where TYPE is
+// same-worker or different-worker for a hard rule, or prefer-same-worker or
+// prefer-different-worker for a soft one, optionally followed by =WEIGHT
+// (default 1). TARGET is @GROUP for the jobs in a group, or a selector over
+// job labels. For example "same-worker:@cache" or
+// "prefer-different-worker=5:app=web".
+func ParseAffinity(expr string) (models.AffinityRule, error) {
+	expr = strings.TrimSpace(expr)
+	kind, target, found := strings.Cut(expr, ":")
+	if !found || strings.TrimSpace(target) == "" {
+		return models.AffinityRule{}, fmt.Errorf("affinity rule %q must be of the form TYPE:TARGET", expr)
+	}
+
+	rule := models.AffinityRule{Hard: true}
+	if soft, found := strings.CutPrefix(kind, "prefer-"); found {
+		rule.Hard = false
+		rule.Weight = 1
+		kind = soft
+		if name, weight, found := strings.Cut(soft, "="); found {
+			kind = name
+			if _, err := fmt.Sscanf(weight, "%d", &rule.Weight); err != nil || rule.Weight < 0 {
+				return models.AffinityRule{}, fmt.Errorf("affinity rule %q: invalid weight %q", expr, weight)
+			}
+		}
+	}
+	if kind != models.AffinitySameWorker && kind != models.AffinityDifferentWorker {
+		return models.AffinityRule{}, fmt.Errorf("affinity rule %q: type must be %s or %s, optionally prefixed with prefer-", expr, models.AffinitySameWorker, models.AffinityDifferentWorker)
+	}
+	rule.Type = kind
+
+	target = strings.TrimSpace(target)
+	if group, found := strings.CutPrefix(target, "@"); found {
+		if !valuePattern.MatchString(group) || group == "" {
+			return models.AffinityRule{}, fmt.Errorf("affinity rule %q: invalid group %q", expr, group)
+		}
+		rule.Group = group
+		return rule, nil
+	}
+
+	selector, err := Parse(target)
+	if err != nil {
+		return models.AffinityRule{}, fmt.Errorf("affinity rule %q: %v", expr, err)
+	}
+	rule.Selector = selector
+	return rule, nil
+}
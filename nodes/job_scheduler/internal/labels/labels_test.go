@@ -0,0 +1,251 @@
+package labels
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Shishir_grez/coltnode/nodes/job_scheduler/pkg/models"
+)
+
+func TestParseLabels(t *testing.T) {
+	tests := []struct {
+		expr    string
+		want    map[string]string
+		wantErr bool
+	}{
+		{expr: "", want: map[string]string{}},
+		{expr: "disk=ssd", want: map[string]string{"disk": "ssd"}},
+		{expr: " disk = ssd , zone=eu-1,", want: map[string]string{"disk": "ssd", "zone": "eu-1"}},
+		{expr: "example.com/gpu=a100", want: map[string]string{"example.com/gpu": "a100"}},
+		{expr: "gpu=", want: map[string]string{"gpu": ""}},
+		{expr: "disk", wantErr: true},
+		{expr: "=ssd", wantErr: true},
+		{expr: "disk=s s d", wantErr: true},
+		{expr: "-disk=ssd", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			got, err := ParseLabels(tt.expr)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseLabels(%q) error = %v, wantErr %v", tt.expr, err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseLabels(%q) = %v, want %v", tt.expr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		expr    string
+		want    models.Selector
+		wantErr bool
+	}{
+		{expr: "", want: nil},
+		{expr: "disk=ssd", want: models.Selector{{Key: "disk", Operator: models.SelectorEquals, Values: []string{"ssd"}}}},
+		{expr: "disk==ssd", want: models.Selector{{Key: "disk", Operator: models.SelectorEquals, Values: []string{"ssd"}}}},
+		{expr: "disk!=hdd", want: models.Selector{{Key: "disk", Operator: models.SelectorNotEquals, Values: []string{"hdd"}}}},
+		{expr: "zone in (eu-1, eu-2)", want: models.Selector{{Key: "zone", Operator: models.SelectorIn, Values: []string{"eu-1", "eu-2"}}}},
+		{expr: "zone notin (us-1)", want: models.Selector{{Key: "zone", Operator: models.SelectorNotIn, Values: []string{"us-1"}}}},
+		{expr: "gpu", want: models.Selector{{Key: "gpu", Operator: models.SelectorExists}}},
+		{expr: "!gpu", want: models.Selector{{Key: "gpu", Operator: models.SelectorNotExists}}},
+		{expr: "disk=ssd, zone in (a,b),!gpu,", want: models.Selector{
+			{Key: "disk", Operator: models.SelectorEquals, Values: []string{"ssd"}},
+			{Key: "zone", Operator: models.SelectorIn, Values: []string{"a", "b"}},
+			{Key: "gpu", Operator: models.SelectorNotExists},
+		}},
+		{expr: "zone in (a,b", wantErr: true},
+		{expr: "zone in a,b)", wantErr: true},
+		{expr: "zone in a", wantErr: true},
+		{expr: "disk=s s d", wantErr: true},
+		{expr: "=ssd", wantErr: true},
+		{expr: "!", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			got, err := Parse(tt.expr)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse(%q) error = %v, wantErr %v", tt.expr, err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse(%q) = %#v, want %#v", tt.expr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSelectorMatches(t *testing.T) {
+	workerLabels := map[string]string{"disk": "ssd", "zone": "eu-1"}
+
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{expr: "", want: true},
+		{expr: "disk=ssd", want: true},
+		{expr: "disk=hdd", want: false},
+		{expr: "disk!=hdd", want: true},
+		{expr: "gpu!=a100", want: true},
+		{expr: "zone in (eu-1,eu-2)", want: true},
+		{expr: "zone notin (eu-1)", want: false},
+		{expr: "gpu notin (a100)", want: true},
+		{expr: "disk", want: true},
+		{expr: "gpu", want: false},
+		{expr: "!gpu", want: true},
+		{expr: "disk=ssd,gpu", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			selector, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := selector.Matches(workerLabels); got != tt.want {
+				t.Errorf("%q matches %v = %v, want %v", tt.expr, workerLabels, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTaint(t *testing.T) {
+	tests := []struct {
+		expr    string
+		want    models.Taint
+		wantErr bool
+	}{
+		{expr: "dedicated=ops:NoSchedule", want: models.Taint{Key: "dedicated", Value: "ops", Effect: models.TaintNoSchedule}},
+		{expr: " gpu:PreferNoSchedule ", want: models.Taint{Key: "gpu", Effect: models.TaintPreferNoSchedule}},
+		{expr: "dedicated=ops", wantErr: true},
+		{expr: "dedicated=ops:NoExecute", wantErr: true},
+		{expr: "dedicated=ops:", wantErr: true},
+		{expr: ":NoSchedule", wantErr: true},
+		{expr: "dedicated=o p s:NoSchedule", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			got, err := ParseTaint(tt.expr)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseTaint(%q) error = %v, wantErr %v", tt.expr, err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("ParseTaint(%q) = %+v, want %+v", tt.expr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseToleration(t *testing.T) {
+	tests := []struct {
+		expr    string
+		want    models.Toleration
+		wantErr bool
+	}{
+		{expr: "*", want: models.Toleration{Operator: models.TolerationExists}},
+		{expr: "dedicated=ops:NoSchedule", want: models.Toleration{Key: "dedicated", Operator: models.TolerationEqual, Value: "ops", Effect: models.TaintNoSchedule}},
+		{expr: "dedicated=ops", want: models.Toleration{Key: "dedicated", Operator: models.TolerationEqual, Value: "ops"}},
+		{expr: "dedicated:PreferNoSchedule", want: models.Toleration{Key: "dedicated", Operator: models.TolerationExists, Effect: models.TaintPreferNoSchedule}},
+		{expr: "dedicated", want: models.Toleration{Key: "dedicated", Operator: models.TolerationExists}},
+		{expr: "dedicated:NoExecute", wantErr: true},
+		{expr: "=ops", wantErr: true},
+		{expr: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			got, err := ParseToleration(tt.expr)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseToleration(%q) error = %v, wantErr %v", tt.expr, err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("ParseToleration(%q) = %+v, want %+v", tt.expr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTolerates(t *testing.T) {
+	taint := models.Taint{Key: "dedicated", Value: "ops", Effect: models.TaintNoSchedule}
+
+	tests := []struct {
+		toleration string
+		want       bool
+	}{
+		{toleration: "*", want: true},
+		{toleration: "dedicated=ops:NoSchedule", want: true},
+		{toleration: "dedicated=ops", want: true},
+		{toleration: "dedicated", want: true},
+		{toleration: "dedicated:NoSchedule", want: true},
+		{toleration: "dedicated=dev", want: false},
+		{toleration: "dedicated=ops:PreferNoSchedule", want: false},
+		{toleration: "gpu", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.toleration, func(t *testing.T) {
+			toleration, err := ParseToleration(tt.toleration)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := toleration.Tolerates(taint); got != tt.want {
+				t.Errorf("%q tolerates %s = %v, want %v", tt.toleration, taint, got, tt.want)
+			}
+			untolerated := models.Untolerated([]models.Taint{taint}, []models.Toleration{toleration}, models.TaintNoSchedule)
+			if tt.want == (len(untolerated) != 0) {
+				t.Errorf("Untolerated with %q = %v", tt.toleration, untolerated)
+			}
+		})
+	}
+}
+
+func TestParseAffinity(t *testing.T) {
+	tests := []struct {
+		expr    string
+		want    models.AffinityRule
+		wantErr bool
+	}{
+		{expr: "same-worker:@cache", want: models.AffinityRule{Type: models.AffinitySameWorker, Group: "cache", Hard: true}},
+		{expr: "different-worker:app=web", want: models.AffinityRule{
+			Type:     models.AffinityDifferentWorker,
+			Selector: models.Selector{{Key: "app", Operator: models.SelectorEquals, Values: []string{"web"}}},
+			Hard:     true,
+		}},
+		{expr: "prefer-same-worker:@cache", want: models.AffinityRule{Type: models.AffinitySameWorker, Group: "cache", Weight: 1}},
+		{expr: "prefer-different-worker=5:app in (web,api)", want: models.AffinityRule{
+			Type:     models.AffinityDifferentWorker,
+			Selector: models.Selector{{Key: "app", Operator: models.SelectorIn, Values: []string{"web", "api"}}},
+			Weight:   5,
+		}},
+		{expr: "same-worker", wantErr: true},
+		{expr: "same-worker: ", wantErr: true},
+		{expr: "same-node:@cache", wantErr: true},
+		{expr: "same-worker=5:@cache", wantErr: true},
+		{expr: "prefer-same-worker=x:@cache", wantErr: true},
+		{expr: "prefer-same-worker=-1:@cache", wantErr: true},
+		{expr: "same-worker:@", wantErr: true},
+		{expr: "same-worker:app in (web", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			got, err := ParseAffinity(tt.expr)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseAffinity(%q) error = %v, wantErr %v", tt.expr, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseAffinity(%q) = %+v, want %+v", tt.expr, got, tt.want)
+			}
+			// The rule is written back the way it parses
+			if again, err := ParseAffinity(got.String()); err != nil || !reflect.DeepEqual(again, got) {
+				t.Errorf("ParseAffinity(%q) = %+v, %v, want %+v", got.String(), again, err, got)
+			}
+		})
+	}
+}
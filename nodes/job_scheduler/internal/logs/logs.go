@@ -0,0 +1,286 @@
+package logs
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Streams a job can produce output on
+const (
+	Stdout = "stdout"
+	Stderr = "stderr"
+)
+
+var (
+	// ErrInvalidStream is returned for anything other than stdout or stderr
+	ErrInvalidStream = errors.New("stream must be stdout or stderr")
+	// ErrInvalidJobID is returned for job IDs that are not safe to use as a directory name
+	ErrInvalidJobID = errors.New("invalid job ID")
+)
+
+// Store keeps the captured output of every job on disk, one directory per job.
+// Each stream is written to its own file which is rotated once it reaches
+// maxBytes; at most maxFiles files are kept per stream and older lines are dropped.
+type Store struct {
+	dir      string
+	maxBytes int64
+	maxFiles int
+	streams  map[string]*streamLog // keyed by jobID/stream
+	mu       sync.Mutex
+}
+
+// streamLog tracks one stream of one job
+type streamLog struct {
+	path    string
+	size    int64         // Bytes in the current file
+	dropped int           // Lines lost to rotation, i.e. the offset of the first retained line
+	lines   []int         // Number of lines in each retained file, oldest first
+	notify  chan struct{} // Closed and replaced whenever lines are appended
+}
+
+// Page is a window of lines read from a stream
+type Page struct {
+	Stream     string   `json:"stream"`
+	Offset     int      `json:"offset"`      // Offset of the first line returned
+	NextOffset int      `json:"next_offset"` // Offset to pass to read the following lines
+	Truncated  bool     `json:"truncated"`   // True if lines before Offset were dropped by rotation
+	Lines      []string `json:"lines"`
+}
+
+// NewStore creates a log store rooted at dir
+func NewStore(dir string, maxBytes int64, maxFiles int) (*Store, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	if maxFiles < 1 {
+		maxFiles = 1
+	}
+	return &Store{
+		dir:      dir,
+		maxBytes: maxBytes,
+		maxFiles: maxFiles,
+		streams:  make(map[string]*streamLog),
+	}, nil
+}
+
+// Append writes lines to a job's stream, rotating the file when it grows past the cap
+func (s *Store) Append(jobID, stream string, lines []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	log, err := s.open(jobID, stream)
+	if err != nil {
+		return err
+	}
+
+	f, err := openAppend(log.path)
+	if err != nil {
+		return err
+	}
+	defer func() { f.Close() }()
+
+	for _, line := range lines {
+		line = strings.TrimRight(line, "\r\n")
+		if log.size > 0 && log.size+int64(len(line))+1 > s.maxBytes {
+			f.Close()
+			if err := s.rotate(log); err != nil {
+				return err
+			}
+			if f, err = openAppend(log.path); err != nil {
+				return err
+			}
+		}
+		if _, err := f.WriteString(line + "\n"); err != nil {
+			return err
+		}
+		log.size += int64(len(line)) + 1
+		log.lines[len(log.lines)-1]++
+	}
+
+	close(log.notify)
+	log.notify = make(chan struct{})
+	return nil
+}
+
+// Read returns the lines of a stream starting at offset. If tail is positive
+// only the last tail lines are returned instead. Only the files holding those
+// lines are read.
+func (s *Store) Read(jobID, stream string, offset, tail int) (*Page, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	log, err := s.open(jobID, stream)
+	if err != nil {
+		return nil, err
+	}
+
+	end := log.end()
+	if tail > 0 && end-tail > offset {
+		offset = end - tail
+	}
+	page := &Page{Stream: stream, Offset: offset, NextOffset: end, Lines: []string{}}
+	if offset < log.dropped {
+		page.Offset = log.dropped
+		page.Truncated = true
+	}
+	if page.Offset >= end {
+		page.Offset = end
+		return page, nil
+	}
+
+	// Files are oldest first; skip those that end before the requested offset
+	first := log.dropped // Offset of the first line in the current file
+	for i, n := range log.lines {
+		if first+n <= page.Offset {
+			first += n
+			continue
+		}
+		lines, err := readLines(rotatedPath(log.path, len(log.lines)-1-i))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		if skip := page.Offset - first; skip > 0 {
+			lines = lines[min(skip, len(lines)):]
+		}
+		page.Lines = append(page.Lines, lines...)
+		first += n
+	}
+	return page, nil
+}
+
+// Wait blocks until lines past offset are available on the stream or ctx is done
+func (s *Store) Wait(ctx context.Context, jobID, stream string, offset int) error {
+	s.mu.Lock()
+	log, err := s.open(jobID, stream)
+	if err != nil {
+		s.mu.Unlock()
+		return err
+	}
+	total := log.end()
+	notify := log.notify
+	s.mu.Unlock()
+
+	if total > offset {
+		return nil
+	}
+	select {
+	case <-notify:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// open returns the tracked state of a stream, scanning existing files on first use.
+// Callers must hold s.mu.
+func (s *Store) open(jobID, stream string) (*streamLog, error) {
+	if stream != Stdout && stream != Stderr {
+		return nil, ErrInvalidStream
+	}
+	if jobID == "" || jobID != filepath.Base(jobID) || strings.HasPrefix(jobID, ".") {
+		return nil, ErrInvalidJobID
+	}
+
+	key := jobID + "/" + stream
+	if log, exists := s.streams[key]; exists {
+		return log, nil
+	}
+
+	jobDir := filepath.Join(s.dir, jobID)
+	if err := os.MkdirAll(jobDir, 0o755); err != nil {
+		return nil, err
+	}
+
+	log := &streamLog{
+		path:   filepath.Join(jobDir, stream+".log"),
+		notify: make(chan struct{}),
+	}
+	// Pick up files left from before a restart, oldest first
+	for i := s.maxFiles - 1; i >= 0; i-- {
+		lines, err := readLines(rotatedPath(log.path, i))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		log.lines = append(log.lines, len(lines))
+	}
+	if len(log.lines) == 0 {
+		log.lines = []int{0}
+	}
+	if info, err := os.Stat(log.path); err == nil {
+		log.size = info.Size()
+	}
+
+	s.streams[key] = log
+	return log, nil
+}
+
+// rotate shifts stream.log to stream.log.1 and so on, dropping the oldest file.
+// Callers must hold s.mu.
+func (s *Store) rotate(log *streamLog) error {
+	if len(log.lines) == s.maxFiles {
+		if err := os.Remove(rotatedPath(log.path, s.maxFiles-1)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		log.dropped += log.lines[0]
+		log.lines = log.lines[1:]
+	}
+	for i := len(log.lines) - 1; i >= 0; i-- {
+		if err := os.Rename(rotatedPath(log.path, i), rotatedPath(log.path, i+1)); err != nil {
+			return err
+		}
+	}
+	log.lines = append(log.lines, 0)
+	log.size = 0
+	return nil
+}
+
+// end returns the offset just past the last line of the stream
+func (log *streamLog) end() int {
+	end := log.dropped
+	for _, n := range log.lines {
+		end += n
+	}
+	return end
+}
+
+// rotatedPath returns the name of the n-th rotated file, n = 0 being the live one
+func rotatedPath(path string, n int) string {
+	if n == 0 {
+		return path
+	}
+	return fmt.Sprintf("%s.%d", path, n)
+}
+
+func openAppend(path string) (*os.File, error) {
+	return os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+}
+
+func readLines(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	lines := make([]string, 0)
+	reader := bufio.NewReader(f)
+	for {
+		line, err := reader.ReadString('\n')
+		if line != "" {
+			lines = append(lines, strings.TrimSuffix(line, "\n"))
+		}
+		if err != nil {
+			break
+		}
+	}
+	return lines, nil
+}
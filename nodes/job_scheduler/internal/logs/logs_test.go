@@ -0,0 +1,194 @@
+package logs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+// lines returns "line-from" up to but not including "line-to"
+func lines(from, to int) []string {
+	out := make([]string, 0, to-from)
+	for i := from; i < to; i++ {
+		out = append(out, fmt.Sprintf("line-%d", i))
+	}
+	return out
+}
+
+func TestRead(t *testing.T) {
+	tests := []struct {
+		name          string
+		maxBytes      int64
+		offset, tail  int
+		wantOffset    int
+		wantLines     []string
+		wantTruncated bool
+	}{
+		{name: "everything", maxBytes: 1 << 20, wantOffset: 0, wantLines: lines(0, 10)},
+		{name: "from offset", maxBytes: 1 << 20, offset: 7, wantOffset: 7, wantLines: lines(7, 10)},
+		{name: "at the end", maxBytes: 1 << 20, offset: 10, wantOffset: 10, wantLines: []string{}},
+		{name: "past the end", maxBytes: 1 << 20, offset: 20, wantOffset: 10, wantLines: []string{}},
+		{name: "tail", maxBytes: 1 << 20, tail: 3, wantOffset: 7, wantLines: lines(7, 10)},
+		{name: "tail longer than the log", maxBytes: 1 << 20, tail: 50, wantOffset: 0, wantLines: lines(0, 10)},
+		{name: "offset after the tail", maxBytes: 1 << 20, offset: 9, tail: 3, wantOffset: 9, wantLines: lines(9, 10)},
+		// Two 7 byte lines per file and three files kept, so lines 0-3 are rotated away
+		{name: "rotated from start", maxBytes: 14, wantOffset: 4, wantLines: lines(4, 10), wantTruncated: true},
+		{name: "rotated from retained offset", maxBytes: 14, offset: 5, wantOffset: 5, wantLines: lines(5, 10)},
+		{name: "rotated tail", maxBytes: 14, tail: 2, wantOffset: 8, wantLines: lines(8, 10)},
+		{name: "rotated tail past retained lines", maxBytes: 14, tail: 8, wantOffset: 4, wantLines: lines(4, 10), wantTruncated: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, err := NewStore(t.TempDir(), tt.maxBytes, 3)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// Appended in batches so rotation happens both within and between calls
+			for _, batch := range [][]string{lines(0, 3), lines(3, 4), lines(4, 10)} {
+				if err := store.Append("job-1", Stdout, batch); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			page, err := store.Read("job-1", Stdout, tt.offset, tt.tail)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if page.Offset != tt.wantOffset || page.NextOffset != 10 || page.Truncated != tt.wantTruncated {
+				t.Errorf("offset = %d, next = %d, truncated = %v, want %d, 10, %v", page.Offset, page.NextOffset, page.Truncated, tt.wantOffset, tt.wantTruncated)
+			}
+			if !slices.Equal(page.Lines, tt.wantLines) {
+				t.Errorf("lines = %v, want %v", page.Lines, tt.wantLines)
+			}
+		})
+	}
+}
+
+func TestReadOnlyNeededFiles(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewStore(dir, 14, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Append("job-1", Stdout, lines(0, 10)); err != nil {
+		t.Fatal(err)
+	}
+	// Lines 4 and 5 are in the oldest file; an extra line in it would shift
+	// every later line if it were read
+	if err := os.WriteFile(filepath.Join(dir, "job-1", "stdout.log.2"), []byte("line-4\nline-5\nextra\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		offset    int
+		wantLines []string
+	}{
+		{offset: 6, wantLines: lines(6, 10)},
+		{offset: 8, wantLines: lines(8, 10)},
+		{offset: 9, wantLines: lines(9, 10)},
+	}
+	for _, tt := range tests {
+		page, err := store.Read("job-1", Stdout, tt.offset, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if page.Offset != tt.offset || !slices.Equal(page.Lines, tt.wantLines) {
+			t.Errorf("Read(%d) = %d, %v, want %d, %v", tt.offset, page.Offset, page.Lines, tt.offset, tt.wantLines)
+		}
+	}
+}
+
+func TestInvalidStreams(t *testing.T) {
+	store, err := NewStore(t.TempDir(), 1<<20, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		jobID, stream string
+		want          error
+	}{
+		{jobID: "job-1", stream: "stdin", want: ErrInvalidStream},
+		{jobID: "", stream: Stdout, want: ErrInvalidJobID},
+		{jobID: "../job-1", stream: Stdout, want: ErrInvalidJobID},
+		{jobID: "..", stream: Stderr, want: ErrInvalidJobID},
+		{jobID: ".hidden", stream: Stderr, want: ErrInvalidJobID},
+	}
+	for _, tt := range tests {
+		t.Run(tt.jobID+"/"+tt.stream, func(t *testing.T) {
+			if err := store.Append(tt.jobID, tt.stream, []string{"x"}); !errors.Is(err, tt.want) {
+				t.Errorf("Append() error = %v, want %v", err, tt.want)
+			}
+			if _, err := store.Read(tt.jobID, tt.stream, 0, 0); !errors.Is(err, tt.want) {
+				t.Errorf("Read() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestStreamsAreSeparate(t *testing.T) {
+	store, err := NewStore(t.TempDir(), 1<<20, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Append("job-1", Stdout, []string{"out\n"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Append("job-1", Stderr, []string{"err\r\n"}); err != nil {
+		t.Fatal(err)
+	}
+
+	for stream, want := range map[string]string{Stdout: "out", Stderr: "err"} {
+		page, err := store.Read("job-1", stream, 0, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(page.Lines, []string{want}) {
+			t.Errorf("%s = %q, want [%q]", stream, page.Lines, want)
+		}
+	}
+}
+
+func TestWait(t *testing.T) {
+	store, err := NewStore(t.TempDir(), 1<<20, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Append("job-1", Stdout, lines(0, 2)); err != nil {
+		t.Fatal(err)
+	}
+
+	// Lines past the offset are already there
+	if err := store.Wait(context.Background(), "job-1", Stdout, 1); err != nil {
+		t.Errorf("Wait() with lines available = %v", err)
+	}
+
+	// Nothing new arrives before the deadline
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := store.Wait(ctx, "job-1", Stdout, 2); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Wait() with no new lines = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	// An append wakes the waiter
+	done := make(chan error, 1)
+	go func() { done <- store.Wait(context.Background(), "job-1", Stdout, 2) }()
+	time.Sleep(10 * time.Millisecond)
+	if err := store.Append("job-1", Stdout, lines(2, 3)); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Wait() after append = %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Wait() did not return after an append")
+	}
+}
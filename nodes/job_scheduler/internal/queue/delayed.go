@@ -0,0 +1,112 @@
+package queue
+
+import (
+	"container/heap"
+	"sync"
+	"time"
+
+	"github.com/Shishir_grez/coltnode/nodes/job_scheduler/pkg/models"
+)
+
+// DelayedQueue holds jobs that may not run before a given time, ordered by
+// that time. Jobs are moved to a JobQueue once they are due.
+type DelayedQueue struct {
+	jobs delayedHeap
+	wake chan struct{} // Signalled when a job is added, as it may be due sooner than the rest
+	mu   sync.Mutex
+}
+
+// NewDelayedQueue creates a new empty delayed queue
+func NewDelayedQueue() *DelayedQueue {
+	return &DelayedQueue{
+		wake: make(chan struct{}, 1),
+	}
+}
+
+// Add holds a job until the given time
+func (q *DelayedQueue) Add(job *models.Job, at time.Time) {
+	q.mu.Lock()
+	heap.Push(&q.jobs, delayedJob{job: job, at: at})
+	q.mu.Unlock()
+
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// PopDue removes and returns every job due at or before now, earliest first
+func (q *DelayedQueue) PopDue(now time.Time) []*models.Job {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var due []*models.Job
+	for len(q.jobs) > 0 && !q.jobs[0].at.After(now) {
+		due = append(due, heap.Pop(&q.jobs).(delayedJob).job)
+	}
+	return due
+}
+
+// NextDue returns when the earliest job is due.
+// Returns false if the queue is empty.
+func (q *DelayedQueue) NextDue() (time.Time, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.jobs) == 0 {
+		return time.Time{}, false
+	}
+	return q.jobs[0].at, true
+}
+
+// Wake returns a channel that receives after a job is added
+func (q *DelayedQueue) Wake() <-chan struct{} {
+	return q.wake
+}
+
+// Remove takes the job with the given ID out of the queue.
+// Returns false if the job was not held.
+func (q *DelayedQueue) Remove(jobID string) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for i, item := range q.jobs {
+		if item.job.ID == jobID {
+			heap.Remove(&q.jobs, i)
+			return true
+		}
+	}
+	return false
+}
+
+// Size returns the number of jobs being held
+func (q *DelayedQueue) Size() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return len(q.jobs)
+}
+
+// delayedJob is a job in the heap along with when it is due
+type delayedJob struct {
+	job *models.Job
+	at  time.Time
+}
+
+// delayedHeap implements heap.Interface ordering jobs by due time
+type delayedHeap []delayedJob
+
+func (h delayedHeap) Len() int { return len(h) }
+
+func (h delayedHeap) Less(i, j int) bool { return h[i].at.Before(h[j].at) }
+
+func (h delayedHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *delayedHeap) Push(x any) { *h = append(*h, x.(delayedJob)) }
+
+func (h *delayedHeap) Pop() any {
+	old := *h
+	last := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return last
+}
@@ -0,0 +1,69 @@
+package queue
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/Shishir_grez/coltnode/nodes/job_scheduler/pkg/models"
+)
+
+func TestDelayedQueue(t *testing.T) {
+	base := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		remove   string
+		now      time.Duration // Relative to base
+		wantDue  []string
+		wantNext time.Duration // Relative to base; negative when nothing is left
+	}{
+		{name: "nothing due yet", now: -time.Second, wantDue: nil, wantNext: 0},
+		{name: "due at run at", now: 0, wantDue: []string{"first"}, wantNext: time.Minute},
+		{name: "due after run at, earliest first", now: time.Minute + time.Second, wantDue: []string{"first", "second"}, wantNext: time.Hour},
+		{name: "everything due", now: 2 * time.Hour, wantDue: []string{"first", "second", "third"}, wantNext: -1},
+		{name: "removed job is never due", remove: "second", now: 2 * time.Hour, wantDue: []string{"first", "third"}, wantNext: -1},
+		{name: "removing an unknown job", remove: "missing", now: time.Minute, wantDue: []string{"first", "second"}, wantNext: time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := NewDelayedQueue()
+			// Added out of order
+			q.Add(&models.Job{ID: "third"}, base.Add(time.Hour))
+			q.Add(&models.Job{ID: "first"}, base)
+			q.Add(&models.Job{ID: "second"}, base.Add(time.Minute))
+
+			select {
+			case <-q.Wake():
+			default:
+				t.Errorf("adding jobs did not signal Wake()")
+			}
+
+			if tt.remove != "" {
+				if got, want := q.Remove(tt.remove), tt.remove != "missing"; got != want {
+					t.Errorf("Remove(%q) = %v, want %v", tt.remove, got, want)
+				}
+			}
+
+			var due []string
+			for _, job := range q.PopDue(base.Add(tt.now)) {
+				due = append(due, job.ID)
+			}
+			if !slices.Equal(due, tt.wantDue) {
+				t.Errorf("PopDue() = %v, want %v", due, tt.wantDue)
+			}
+
+			next, ok := q.NextDue()
+			if tt.wantNext < 0 {
+				if ok || q.Size() != 0 {
+					t.Errorf("NextDue() = %s, %v with %d jobs left, want an empty queue", next, ok, q.Size())
+				}
+				return
+			}
+			if !ok || !next.Equal(base.Add(tt.wantNext)) {
+				t.Errorf("NextDue() = %s, %v, want %s", next, ok, base.Add(tt.wantNext))
+			}
+		})
+	}
+}
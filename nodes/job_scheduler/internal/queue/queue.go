@@ -1,30 +1,53 @@
 package queue
 
 import (
+	"container/heap"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/Shishir_grez/coltnode/nodes/job_scheduler/pkg/models"
 )
 
-// JobQueue represents a thread-safe FIFO queue for jobs
+// DefaultAging is how long a job waits to gain one priority level by default
+const DefaultAging = time.Minute
+
+// JobQueue represents a thread-safe priority queue for jobs.
+// Higher priority jobs are dequeued first and jobs of the same priority in
+// submission order. To keep low priority jobs from starving, a waiting job
+// gains one priority level for every aging interval since it was submitted.
 type JobQueue struct {
-	jobs []*models.Job
+	jobs jobHeap
+	seq  uint64 // Enqueue counter, breaks ties between identical jobs
 	mu   sync.Mutex
 }
 
 // NewJobQueue creates a new empty job queue
 func NewJobQueue() *JobQueue {
 	return &JobQueue{
-		jobs: make([]*models.Job, 0),
+		jobs: jobHeap{aging: DefaultAging, index: make(map[string]int)},
 	}
 }
 
-// Enqueue adds a job to the queue
+// SetAging changes how long a job waits to gain one priority level; 0 disables aging
+func (q *JobQueue) SetAging(aging time.Duration) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.jobs.aging = aging
+	heap.Init(&q.jobs)
+}
+
+// Enqueue adds a job to the queue. A job that is already queued keeps its place.
 func (q *JobQueue) Enqueue(job *models.Job) {
 	q.mu.Lock()
 	defer q.mu.Unlock()
-	
-	q.jobs = append(q.jobs, job)
+
+	if _, exists := q.jobs.index[job.ID]; exists {
+		return
+	}
+	q.seq++
+	heap.Push(&q.jobs, queuedJob{job: job, seq: q.seq})
 }
 
 // Dequeue removes and returns the next job from the queue
@@ -32,14 +55,12 @@ func (q *JobQueue) Enqueue(job *models.Job) {
 func (q *JobQueue) Dequeue() *models.Job {
 	q.mu.Lock()
 	defer q.mu.Unlock()
-	
-	if len(q.jobs) == 0 {
+
+	if len(q.jobs.items) == 0 {
 		return nil
 	}
-	
-	job := q.jobs[0]
-	q.jobs = q.jobs[1:]
-	return job
+
+	return heap.Pop(&q.jobs).(queuedJob).job
 }
 
 // Peek returns the next job without removing it
@@ -47,18 +68,102 @@ func (q *JobQueue) Dequeue() *models.Job {
 func (q *JobQueue) Peek() *models.Job {
 	q.mu.Lock()
 	defer q.mu.Unlock()
-	
-	if len(q.jobs) == 0 {
+
+	if len(q.jobs.items) == 0 {
 		return nil
 	}
-	
-	return q.jobs[0]
+
+	return q.jobs.items[0].job
+}
+
+// Remove takes the job with the given ID out of the queue.
+// Returns false if the job was not queued.
+func (q *JobQueue) Remove(jobID string) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	i, exists := q.jobs.index[jobID]
+	if !exists {
+		return false
+	}
+	heap.Remove(&q.jobs, i)
+	return true
+}
+
+// Jobs returns every queued job in the order they would be dequeued, leaving
+// them in the queue
+func (q *JobQueue) Jobs() []*models.Job {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	sorted := jobHeap{items: append([]queuedJob(nil), q.jobs.items...), aging: q.jobs.aging}
+	sort.Slice(sorted.items, sorted.Less)
+
+	jobs := make([]*models.Job, len(sorted.items))
+	for i, item := range sorted.items {
+		jobs[i] = item.job
+	}
+	return jobs
 }
 
 // Size returns the number of jobs in the queue
 func (q *JobQueue) Size() int {
 	q.mu.Lock()
 	defer q.mu.Unlock()
-	
-	return len(q.jobs)
-}
\ No newline at end of file
+
+	return len(q.jobs.items)
+}
+
+// queuedJob is a job in the heap along with the order it was enqueued in
+type queuedJob struct {
+	job *models.Job
+	seq uint64
+}
+
+// jobHeap implements heap.Interface ordering jobs by effective priority.
+// Aging a job by one level per interval since submission is the same as
+// treating it as if it had been submitted priority*aging earlier, which
+// gives a fixed sort key that does not change while jobs wait.
+type jobHeap struct {
+	items []queuedJob
+	aging time.Duration
+	index map[string]int // Position of each job in items, keyed by job ID
+}
+
+func (h jobHeap) Len() int { return len(h.items) }
+
+func (h jobHeap) Less(i, j int) bool {
+	a, b := h.items[i], h.items[j]
+	if h.aging > 0 {
+		aTime := a.job.SubmitTime.Add(-time.Duration(a.job.Priority) * h.aging)
+		bTime := b.job.SubmitTime.Add(-time.Duration(b.job.Priority) * h.aging)
+		if !aTime.Equal(bTime) {
+			return aTime.Before(bTime)
+		}
+	} else if a.job.Priority != b.job.Priority {
+		return a.job.Priority > b.job.Priority
+	}
+	if !a.job.SubmitTime.Equal(b.job.SubmitTime) {
+		return a.job.SubmitTime.Before(b.job.SubmitTime)
+	}
+	return a.seq < b.seq
+}
+
+func (h jobHeap) Swap(i, j int) {
+	h.items[i], h.items[j] = h.items[j], h.items[i]
+	h.index[h.items[i].job.ID] = i
+	h.index[h.items[j].job.ID] = j
+}
+
+func (h *jobHeap) Push(x any) {
+	item := x.(queuedJob)
+	h.index[item.job.ID] = len(h.items)
+	h.items = append(h.items, item)
+}
+
+func (h *jobHeap) Pop() any {
+	last := h.items[len(h.items)-1]
+	h.items = h.items[:len(h.items)-1]
+	delete(h.index, last.job.ID)
+	return last
+}
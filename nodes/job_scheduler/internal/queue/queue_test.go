@@ -0,0 +1,158 @@
+package queue
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/Shishir_grez/coltnode/nodes/job_scheduler/pkg/models"
+)
+
+func TestDequeueOrder(t *testing.T) {
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	type submitted struct {
+		id       string
+		priority int
+		age      time.Duration // How long before base the job was submitted
+	}
+	tests := []struct {
+		name  string
+		aging time.Duration
+		jobs  []submitted
+		want  []string
+	}{
+		{
+			name:  "higher priority first",
+			aging: 0,
+			jobs:  []submitted{{"low", 1, 0}, {"high", 9, 0}, {"mid", 5, 0}},
+			want:  []string{"high", "mid", "low"},
+		},
+		{
+			name:  "same priority in submission order",
+			aging: 0,
+			jobs:  []submitted{{"second", 5, time.Minute}, {"first", 5, 2 * time.Minute}, {"third", 5, 0}},
+			want:  []string{"first", "second", "third"},
+		},
+		{
+			name:  "identical jobs in enqueue order",
+			aging: time.Minute,
+			jobs:  []submitted{{"a", 5, 0}, {"b", 5, 0}, {"c", 5, 0}},
+			want:  []string{"a", "b", "c"},
+		},
+		{
+			name:  "without aging an old low priority job waits",
+			aging: 0,
+			jobs:  []submitted{{"old", 1, time.Hour}, {"new", 3, 0}},
+			want:  []string{"new", "old"},
+		},
+		{
+			name:  "aging lifts a job that waited long enough",
+			aging: time.Minute,
+			jobs:  []submitted{{"old", 1, 3 * time.Minute}, {"new", 3, 0}},
+			want:  []string{"old", "new"},
+		},
+		{
+			name:  "aging not yet enough",
+			aging: time.Minute,
+			jobs:  []submitted{{"old", 1, time.Minute}, {"new", 3, 0}},
+			want:  []string{"new", "old"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := NewJobQueue()
+			q.SetAging(tt.aging)
+			for _, s := range tt.jobs {
+				q.Enqueue(&models.Job{ID: s.id, Priority: s.priority, SubmitTime: base.Add(-s.age)})
+			}
+
+			var listed []string
+			for _, job := range q.Jobs() {
+				listed = append(listed, job.ID)
+			}
+			if !slices.Equal(listed, tt.want) {
+				t.Errorf("Jobs() = %v, want %v", listed, tt.want)
+			}
+
+			var got []string
+			for job := q.Dequeue(); job != nil; job = q.Dequeue() {
+				got = append(got, job.ID)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("dequeued %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPeek(t *testing.T) {
+	q := NewJobQueue()
+	if job := q.Peek(); job != nil {
+		t.Errorf("Peek() on an empty queue = %v, want nil", job)
+	}
+
+	a := &models.Job{ID: "a", Priority: 5}
+	b := &models.Job{ID: "b", Priority: 9}
+	q.Enqueue(a)
+	q.Enqueue(b)
+	q.Enqueue(a)
+	if q.Size() != 2 {
+		t.Fatalf("Size() = %d after enqueuing a job twice, want 2", q.Size())
+	}
+
+	tests := []struct {
+		wantPeek *models.Job
+		wantSize int
+	}{
+		{wantPeek: b, wantSize: 2},
+		{wantPeek: a, wantSize: 1},
+		{wantPeek: nil, wantSize: 0},
+	}
+	for i, tt := range tests {
+		if job := q.Peek(); job != tt.wantPeek {
+			t.Errorf("step %d: Peek() = %v, want %v", i, job, tt.wantPeek)
+		}
+		if size := q.Size(); size != tt.wantSize {
+			t.Errorf("step %d: Size() = %d, want %d", i, size, tt.wantSize)
+		}
+		if job := q.Dequeue(); job != tt.wantPeek {
+			t.Errorf("step %d: Dequeue() = %v, want %v", i, job, tt.wantPeek)
+		}
+	}
+}
+
+func TestRemove(t *testing.T) {
+	tests := []struct {
+		name   string
+		remove string
+		want   bool
+		left   []string // IDs left, in dequeue order
+	}{
+		{name: "first", remove: "high", want: true, left: []string{"mid", "low"}},
+		{name: "middle", remove: "mid", want: true, left: []string{"high", "low"}},
+		{name: "last", remove: "low", want: true, left: []string{"high", "mid"}},
+		{name: "not queued", remove: "missing", want: false, left: []string{"high", "mid", "low"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := NewJobQueue()
+			q.Enqueue(&models.Job{ID: "low", Priority: 1})
+			q.Enqueue(&models.Job{ID: "high", Priority: 9})
+			q.Enqueue(&models.Job{ID: "mid", Priority: 5})
+
+			if got := q.Remove(tt.remove); got != tt.want {
+				t.Errorf("Remove(%q) = %v, want %v", tt.remove, got, tt.want)
+			}
+			var left []string
+			for job := q.Dequeue(); job != nil; job = q.Dequeue() {
+				left = append(left, job.ID)
+			}
+			if !slices.Equal(left, tt.left) {
+				t.Errorf("left %v, want %v", left, tt.left)
+			}
+		})
+	}
+}
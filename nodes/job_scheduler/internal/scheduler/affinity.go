@@ -0,0 +1,75 @@
+package scheduler
+
+import "github.com/Shishir_grez/coltnode/nodes/job_scheduler/pkg/models"
+
+// placedJobs returns the jobs placed on each worker, keyed by worker ID, for
+// evaluating a job's affinity rules. It returns nil if the job has none.
+// Callers must hold s.mu.
+func (s *Scheduler) placedJobs(job *models.Job) map[string][]*models.Job {
+	if len(job.Affinity) == 0 {
+		return nil
+	}
+	return s.index.placedJobs()
+}
+
+// affinityAllows reports whether placing a job on a worker satisfies all of
+// its hard affinity rules. A same-worker rule that no placed job matches is
+// satisfied anywhere if the job matches it itself, so the first job of a
+// group that is to be kept together can start.
+func affinityAllows(job *models.Job, worker *models.Worker, placed map[string][]*models.Job) bool {
+	for _, rule := range job.Affinity {
+		if !rule.Hard {
+			continue
+		}
+
+		here := hosts(placed[worker.ID], rule)
+		switch rule.Type {
+		case models.AffinitySameWorker:
+			if !here && (!rule.Matches(job) || placedAnywhere(placed, rule)) {
+				return false
+			}
+		case models.AffinityDifferentWorker:
+			if here {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// affinityScore adds up the weights of a job's soft affinity rules that hold
+// on a worker
+func affinityScore(job *models.Job, worker *models.Worker, placed map[string][]*models.Job) int {
+	score := 0
+	for _, rule := range job.Affinity {
+		if rule.Hard {
+			continue
+		}
+
+		here := hosts(placed[worker.ID], rule)
+		if (rule.Type == models.AffinitySameWorker) == here {
+			score += rule.Weight
+		}
+	}
+	return score
+}
+
+// hosts reports whether any of the jobs is one the rule is about
+func hosts(jobs []*models.Job, rule models.AffinityRule) bool {
+	for _, job := range jobs {
+		if rule.Matches(job) {
+			return true
+		}
+	}
+	return false
+}
+
+// placedAnywhere reports whether any placed job is one the rule is about
+func placedAnywhere(placed map[string][]*models.Job, rule models.AffinityRule) bool {
+	for _, jobs := range placed {
+		if hosts(jobs, rule) {
+			return true
+		}
+	}
+	return false
+}
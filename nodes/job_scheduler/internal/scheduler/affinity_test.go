@@ -0,0 +1,82 @@
+package scheduler
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Shishir_grez/coltnode/nodes/job_scheduler/internal/queue"
+	"github.com/Shishir_grez/coltnode/nodes/job_scheduler/internal/storage"
+	"github.com/Shishir_grez/coltnode/nodes/job_scheduler/pkg/models"
+)
+
+func TestAffinityPlacement(t *testing.T) {
+	sameCache := models.AffinityRule{Type: models.AffinitySameWorker, Group: "cache", Hard: true}
+	apartFromCache := models.AffinityRule{Type: models.AffinityDifferentWorker, Group: "cache", Hard: true}
+	apartFromWeb := models.AffinityRule{
+		Type:     models.AffinityDifferentWorker,
+		Selector: models.Selector{{Key: "app", Operator: models.SelectorEquals, Values: []string{"web"}}},
+		Hard:     true,
+	}
+
+	tests := []struct {
+		name       string
+		cacheOn    []string // Workers running a job in group cache
+		webOn      []string // Workers running a job labelled app=web
+		group      string   // Group of the job being placed
+		rules      []models.AffinityRule
+		wantWorker string // Empty if the job stays queued
+		wantReason string
+	}{
+		{name: "no rules", cacheOn: []string{"b"}, wantWorker: "a"},
+		{name: "same worker", cacheOn: []string{"b"}, rules: []models.AffinityRule{sameCache}, wantWorker: "b"},
+		{name: "same worker before the group started", rules: []models.AffinityRule{sameCache}, wantReason: "queued: no active worker satisfies"},
+		{name: "first of its own group", group: "cache", rules: []models.AffinityRule{sameCache}, wantWorker: "a"},
+		{name: "different worker", cacheOn: []string{"a"}, rules: []models.AffinityRule{apartFromCache}, wantWorker: "b"},
+		{name: "different worker everywhere taken", cacheOn: []string{"a", "b"}, rules: []models.AffinityRule{apartFromCache}, wantReason: "queued: no active worker satisfies"},
+		{name: "different worker by labels", webOn: []string{"a"}, rules: []models.AffinityRule{apartFromWeb}, wantWorker: "b"},
+		{name: "preferred same worker", cacheOn: []string{"b"}, rules: []models.AffinityRule{{Type: models.AffinitySameWorker, Group: "cache", Weight: 1}}, wantWorker: "b"},
+		{name: "preferred different worker", cacheOn: []string{"a"}, rules: []models.AffinityRule{{Type: models.AffinityDifferentWorker, Group: "cache", Weight: 1}}, wantWorker: "b"},
+		{name: "preference gives way", cacheOn: []string{"a", "b"}, rules: []models.AffinityRule{{Type: models.AffinityDifferentWorker, Group: "cache", Weight: 1}}, wantWorker: "a"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewScheduler(queue.NewJobQueue(), storage.NewMemoryStorage())
+			registerNamed(t, s, "a")
+			registerNamed(t, s, "b")
+
+			// Jobs already running, as affinity rules see them
+			running := func(workerID string, edit func(*models.Job)) {
+				job := models.NewJob("running", "sleep", []string{"60"})
+				job.Status = "running"
+				job.WorkerID = workerID
+				edit(job)
+				if err := s.storage.SaveJob(job); err != nil {
+					t.Fatal(err)
+				}
+			}
+			for _, workerID := range tt.cacheOn {
+				running(workerID, func(job *models.Job) { job.Group = "cache" })
+			}
+			for _, workerID := range tt.webOn {
+				running(workerID, func(job *models.Job) { job.Labels = map[string]string{"app": "web"} })
+			}
+
+			// Round robin would otherwise start at a
+			job := models.NewJob("job", "true", nil)
+			job.Policy = PolicyRoundRobin
+			job.Group = tt.group
+			job.Affinity = tt.rules
+			submitJob(t, s, job)
+			scheduleQueued(t, s)
+
+			got := storedJob(t, s, job.ID)
+			if got.WorkerID != tt.wantWorker {
+				t.Errorf("job placed on %q, want %q", got.WorkerID, tt.wantWorker)
+			}
+			if tt.wantWorker == "" && (got.Status != "pending" || !strings.HasPrefix(got.Reason, tt.wantReason)) {
+				t.Errorf("job is %q with reason %q, want pending with reason starting %q", got.Status, got.Reason, tt.wantReason)
+			}
+		})
+	}
+}
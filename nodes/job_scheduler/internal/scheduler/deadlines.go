@@ -0,0 +1,70 @@
+package scheduler
+
+import (
+	"log"
+	"time"
+
+	"github.com/Shishir_grez/coltnode/nodes/job_scheduler/pkg/models"
+)
+
+// deadlineSlack is how long past its deadline a running job may go before
+// the scheduler steps in. Workers enforce deadlines themselves; this only
+// catches jobs whose worker did not.
+const deadlineSlack = 5 * time.Second
+
+// StartDeadlineWatcher checks every interval for queued jobs that can no
+// longer start in time, which expire, and running jobs past their deadline,
+// which their workers are told to stop
+func (s *Scheduler) StartDeadlineWatcher(interval time.Duration, gracePeriod time.Duration) {
+	s.goEvery(interval, func() {
+		if err := s.enforceDeadlines(gracePeriod); err != nil {
+			log.Printf("Error enforcing deadlines: %v", err)
+		}
+	})
+}
+
+// enforceDeadlines runs a single pass of the deadline watcher
+func (s *Scheduler) enforceDeadlines(gracePeriod time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for _, job := range s.index.activeJobs() {
+		switch job.Status {
+		case "waiting", "pending":
+			if job.MissedStart(now) || (!job.FinishBy.IsZero() && now.After(job.FinishBy)) {
+				if err := s.expire(job); err != nil {
+					return err
+				}
+			}
+		case "running":
+			deadline := job.Deadline(job.StartTime)
+			if deadline.IsZero() || now.Before(deadline.Add(deadlineSlack)) || s.timingOut[job.ID] {
+				continue
+			}
+
+			select {
+			case s.assignmentQueue(job.WorkerID) <- models.Assignment{Cancel: job.ID, GracePeriod: gracePeriod}:
+				log.Printf("Job %s ran past its deadline, stopping it", job.ID)
+				s.timingOut[job.ID] = true
+			default:
+				// Try again on the next pass
+			}
+		}
+	}
+	return nil
+}
+
+// expire ends a pending or waiting job that can no longer start in time.
+// Callers must hold s.mu.
+func (s *Scheduler) expire(job *models.Job) error {
+	log.Printf("Job %s expired before it could start", job.ID)
+	s.jobQueue.Remove(job.ID)
+	s.delayed.Remove(job.ID)
+	job.Status = "expired"
+	job.EndTime = time.Now()
+	if err := s.storage.UpdateJob(job); err != nil {
+		return err
+	}
+	return s.releaseDependents(job)
+}
@@ -0,0 +1,176 @@
+package scheduler
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Shishir_grez/coltnode/nodes/job_scheduler/internal/queue"
+	"github.com/Shishir_grez/coltnode/nodes/job_scheduler/internal/storage"
+	"github.com/Shishir_grez/coltnode/nodes/job_scheduler/pkg/models"
+)
+
+func TestEnforceDeadlines(t *testing.T) {
+	tests := []struct {
+		name       string
+		running    bool
+		retry      bool          // Whether the job already had a failed attempt
+		startBy    time.Duration // Relative to now; 0 leaves it unset
+		finishBy   time.Duration // Relative to now; 0 leaves it unset
+		timeout    time.Duration
+		started    time.Duration // How long ago a running job started
+		wantStatus string
+		wantStop   bool // Whether the worker is told to stop the job
+	}{
+		{name: "queued in time", startBy: time.Minute, wantStatus: "pending"},
+		{name: "queued past start by", startBy: -time.Second, wantStatus: "expired"},
+		{name: "retry past start by", retry: true, startBy: -time.Second, wantStatus: "pending"},
+		{name: "retry past finish by", retry: true, finishBy: -time.Second, wantStatus: "expired"},
+		{name: "queued past finish by", finishBy: -time.Second, wantStatus: "expired"},
+		{name: "running without a deadline", running: true, started: time.Hour, wantStatus: "running"},
+		{name: "running within its timeout", running: true, timeout: time.Minute, started: 30 * time.Second, wantStatus: "running"},
+		{name: "running within the slack", running: true, timeout: time.Minute, started: time.Minute + time.Second, wantStatus: "running"},
+		{name: "running past its timeout", running: true, timeout: time.Minute, started: time.Hour, wantStatus: "running", wantStop: true},
+		{name: "running past finish by", running: true, finishBy: -time.Minute, started: time.Hour, wantStatus: "running", wantStop: true},
+		// Start by only matters until the job starts
+		{name: "running past start by", running: true, startBy: -time.Minute, started: time.Hour, wantStatus: "running"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jobQueue := queue.NewJobQueue()
+			s := NewScheduler(jobQueue, storage.NewMemoryStorage())
+			worker := models.NewWorker("w1", 2, 1024)
+			if err := s.RegisterWorker(worker); err != nil {
+				t.Fatal(err)
+			}
+			job := models.NewJob("sleep", "sleep", []string{"3600"})
+			job.Timeout = tt.timeout
+			if tt.retry {
+				job.Attempts = []models.Attempt{{WorkerID: worker.ID, Status: "failed", ExitCode: 1}}
+			}
+			submitJob(t, s, job)
+
+			if tt.running {
+				scheduleQueued(t, s)
+				if assignment := nextAssignment(t, s, worker.ID); assignment == nil || assignment.Job == nil {
+					t.Fatalf("worker got %+v, want the job", assignment)
+				}
+				storedJob(t, s, job.ID).StartTime = time.Now().Add(-tt.started)
+			}
+			// Deadlines are set after placement, so a queued job is still queued
+			// and a running one is past them
+			stored := storedJob(t, s, job.ID)
+			if tt.startBy != 0 {
+				stored.StartBy = time.Now().Add(tt.startBy)
+			}
+			if tt.finishBy != 0 {
+				stored.FinishBy = time.Now().Add(tt.finishBy)
+			}
+
+			if err := s.enforceDeadlines(time.Second); err != nil {
+				t.Fatal(err)
+			}
+
+			got := storedJob(t, s, job.ID)
+			if got.Status != tt.wantStatus {
+				t.Errorf("status = %q, want %q", got.Status, tt.wantStatus)
+			}
+			if tt.wantStatus == "expired" && jobQueue.Size() != 0 {
+				t.Errorf("expired job is still queued")
+			}
+			assignment := nextAssignment(t, s, worker.ID)
+			if stopped := assignment != nil && assignment.Cancel == job.ID; stopped != tt.wantStop {
+				t.Fatalf("worker got %+v, want a stop instruction: %v", assignment, tt.wantStop)
+			}
+			if !tt.wantStop {
+				return
+			}
+
+			// Told once only, and the job times out whatever the worker reports
+			if err := s.enforceDeadlines(time.Second); err != nil {
+				t.Fatal(err)
+			}
+			if again := nextAssignment(t, s, worker.ID); again != nil {
+				t.Errorf("worker got a second instruction %+v", again)
+			}
+			if err := s.CompleteJob(job.ID, models.JobResult{WorkerID: worker.ID, Status: "failed", ExitCode: -1}); err != nil {
+				t.Fatal(err)
+			}
+			if got := storedJob(t, s, job.ID); got.Status != "timed_out" {
+				t.Errorf("status after the worker reported = %q, want timed_out", got.Status)
+			}
+		})
+	}
+}
+
+func TestTimedOutResult(t *testing.T) {
+	tests := []struct {
+		result     string
+		wantStatus string
+		wantErr    error
+	}{
+		{result: "timed_out", wantStatus: "timed_out"},
+		{result: "completed", wantStatus: "completed"},
+		{result: "expired", wantStatus: "running", wantErr: ErrInvalidResult},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.result, func(t *testing.T) {
+			s := NewScheduler(queue.NewJobQueue(), storage.NewMemoryStorage())
+			worker := models.NewWorker("w1", 2, 1024)
+			if err := s.RegisterWorker(worker); err != nil {
+				t.Fatal(err)
+			}
+			job := models.NewJob("sleep", "sleep", []string{"3600"})
+			job.Timeout = time.Second
+			submitJob(t, s, job)
+			scheduleQueued(t, s)
+			nextAssignment(t, s, worker.ID)
+
+			if err := s.CompleteJob(job.ID, models.JobResult{WorkerID: worker.ID, Status: tt.result}); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CompleteJob() error = %v, want %v", err, tt.wantErr)
+			}
+			if got := storedJob(t, s, job.ID); got.Status != tt.wantStatus {
+				t.Errorf("status = %q, want %q", got.Status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestScheduleJobPastStartBy(t *testing.T) {
+	tests := []struct {
+		name       string
+		retry      bool
+		wantStatus string
+	}{
+		{name: "first attempt", wantStatus: "expired"},
+		// The job started in time once, so its retry may start late
+		{name: "retry", retry: true, wantStatus: "running"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewScheduler(queue.NewJobQueue(), storage.NewMemoryStorage())
+			worker := models.NewWorker("w1", 2, 1024)
+			if err := s.RegisterWorker(worker); err != nil {
+				t.Fatal(err)
+			}
+			job := models.NewJob("late", "true", nil)
+			job.StartBy = time.Now().Add(-time.Second)
+			if tt.retry {
+				job.Attempts = []models.Attempt{{WorkerID: worker.ID, Status: "failed", ExitCode: 1}}
+			}
+			submitJob(t, s, job)
+			scheduleQueued(t, s)
+
+			if got := storedJob(t, s, job.ID); got.Status != tt.wantStatus {
+				t.Errorf("status = %q, want %q", got.Status, tt.wantStatus)
+			}
+			assignment := nextAssignment(t, s, worker.ID)
+			if started := assignment != nil && assignment.Job != nil && assignment.Job.ID == job.ID; started != (tt.wantStatus == "running") {
+				t.Errorf("worker got %+v, want the job started: %v", assignment, tt.wantStatus == "running")
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package scheduler
+
+import (
+	"time"
+
+	"github.com/Shishir_grez/coltnode/nodes/job_scheduler/pkg/models"
+)
+
+// Submit saves a new pending job and queues it, holding it back until its
+// RunAt if that is still ahead. It fails with ErrQuotaExceeded if the job's
+// namespace may not queue another job or could never run this one. The
+// scheduler keeps its own copy, so the caller may go on reading job.
+func (s *Scheduler) Submit(job *models.Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.admit([]*models.Job{job}); err != nil {
+		return err
+	}
+	job = job.Clone()
+	if err := s.storage.SaveJob(job); err != nil {
+		return err
+	}
+	s.enqueue(job)
+	return nil
+}
+
+// enqueue adds a pending job to the queue, or to the delayed queue if it may
+// not run yet
+func (s *Scheduler) enqueue(job *models.Job) {
+	if at := job.NotBefore(); time.Now().Before(at) {
+		s.enqueueAt(job, at)
+		return
+	}
+	s.ready(job)
+}
+
+// ready adds a job that may run now to the job queue and wakes the scheduling
+// loop. The time it became ready is kept to measure how long it waits.
+func (s *Scheduler) ready(job *models.Job) {
+	if job.QueuedAt.IsZero() {
+		job.QueuedAt = time.Now()
+	}
+	s.jobQueue.Enqueue(job)
+	s.notify()
+}
+
+// enqueueAt holds a pending job back and adds it to the queue at the given time
+func (s *Scheduler) enqueueAt(job *models.Job, at time.Time) {
+	s.delayed.Add(job, at)
+}
+
+// promoteDelayed moves jobs from the delayed queue to the job queue as they
+// become due, sleeping until the earliest one or until a job is added.
+// It returns once the scheduler is stopped.
+func (s *Scheduler) promoteDelayed() {
+	for {
+		for _, job := range s.delayed.PopDue(time.Now()) {
+			s.mu.Lock()
+			// The job may have been cancelled while it waited
+			if job.Status == "pending" {
+				s.ready(job)
+			}
+			s.mu.Unlock()
+		}
+
+		var timer *time.Timer
+		var due <-chan time.Time
+		if next, ok := s.delayed.NextDue(); ok {
+			timer = time.NewTimer(time.Until(next))
+			due = timer.C
+		}
+		select {
+		case <-due:
+		case <-s.delayed.Wake():
+		case <-s.ctx.Done():
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+		if s.ctx.Err() != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Shishir_grez/coltnode/nodes/job_scheduler/internal/queue"
+	"github.com/Shishir_grez/coltnode/nodes/job_scheduler/internal/storage"
+	"github.com/Shishir_grez/coltnode/nodes/job_scheduler/pkg/models"
+)
+
+func TestDelayedSubmission(t *testing.T) {
+	tests := []struct {
+		name       string
+		runIn      time.Duration // RunAt relative to submission; 0 leaves it unset
+		cancel     bool
+		wantHeld   bool
+		wantQueued bool // Whether the job reaches the job queue
+		wantStatus string
+	}{
+		{name: "no run at", wantQueued: true, wantStatus: "pending"},
+		{name: "run at in the past", runIn: -time.Minute, wantQueued: true, wantStatus: "pending"},
+		{name: "run at ahead", runIn: 200 * time.Millisecond, wantHeld: true, wantQueued: true, wantStatus: "pending"},
+		{name: "cancelled while held", runIn: 200 * time.Millisecond, cancel: true, wantHeld: true, wantStatus: "cancelled"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jobQueue := queue.NewJobQueue()
+			s := NewScheduler(jobQueue, storage.NewMemoryStorage())
+			go s.promoteDelayed()
+
+			job := models.NewJob("later", "true", nil)
+			if tt.runIn != 0 {
+				job.RunAt = job.SubmitTime.Add(tt.runIn)
+			}
+			submitJob(t, s, job)
+
+			if held := s.delayed.Size() == 1 && jobQueue.Size() == 0; held != tt.wantHeld {
+				t.Fatalf("held back = %v, want %v", held, tt.wantHeld)
+			}
+			if tt.cancel {
+				if _, err := s.CancelJob(job.ID, time.Second); err != nil {
+					t.Fatal(err)
+				}
+				if s.delayed.Size() != 0 {
+					t.Errorf("cancelled job is still held")
+				}
+			}
+
+			// Wait past the run at for the job to be promoted
+			deadline := time.Now().Add(time.Second)
+			for jobQueue.Size() == 0 && time.Now().Before(deadline) {
+				time.Sleep(10 * time.Millisecond)
+			}
+			if queued := jobQueue.Size() == 1; queued != tt.wantQueued {
+				t.Fatalf("queued = %v, want %v", queued, tt.wantQueued)
+			}
+			if tt.wantQueued && tt.wantHeld && time.Now().Before(job.RunAt) {
+				t.Errorf("job was promoted before its run at")
+			}
+			if got := storedJob(t, s, job.ID); got.Status != tt.wantStatus {
+				t.Errorf("status = %q, want %q", got.Status, tt.wantStatus)
+			}
+		})
+	}
+}
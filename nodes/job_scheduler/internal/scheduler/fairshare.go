@@ -0,0 +1,156 @@
+package scheduler
+
+import (
+	"log"
+	"sort"
+
+	"github.com/Shishir_grez/coltnode/nodes/job_scheduler/pkg/models"
+)
+
+// tenant is a namespace competing for capacity in a scheduling pass
+type tenant struct {
+	name      string
+	weight    int
+	allocated models.Resources // Reserved by the namespace's running jobs
+	jobs      []*models.Job    // Its queued jobs still to be tried this pass, in priority order
+}
+
+// SetFairShare turns fair-share scheduling across namespaces on or off. It is
+// off by default, and queued jobs are tried strictly in priority order,
+// whatever their namespace. When on, the namespace furthest below its fair
+// share goes first and priority only orders jobs within a namespace, so a
+// high-priority job can wait behind a lower-priority one from a namespace
+// that uses less of the cluster.
+func (s *Scheduler) SetFairShare(enabled bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.fairShare = enabled
+	s.notify()
+}
+
+// SetWeight sets a namespace's weight, its share of the cluster relative to
+// the other namespaces with queued jobs under fair-share scheduling
+func (s *Scheduler) SetWeight(name string, weight int) (*models.Namespace, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	namespace, err := s.namespace(name)
+	if err != nil {
+		return nil, err
+	}
+
+	namespace.Weight = weight
+	if err := s.saveNamespace(namespace); err != nil {
+		return nil, err
+	}
+
+	log.Printf("Weight of namespace %s set to %d", name, weight)
+	s.notify()
+	return namespace.Clone(), nil
+}
+
+// scheduleFairly tries to place queued jobs using weighted Dominant Resource
+// Fairness: each job is taken from the namespace whose running jobs hold the
+// smallest share of the cluster's CPU or memory, whichever share is larger,
+// divided by the namespace's weight. Within a namespace jobs keep their
+// priority order. A namespace whose next job cannot be placed does not block
+// the others, and namespaces with nothing queued do not count, so capacity a
+// namespace leaves idle goes to whoever can use it.
+func (s *Scheduler) scheduleFairly(jobs []*models.Job) {
+	tenants, capacity, err := s.tenants(jobs)
+	if err != nil {
+		log.Printf("Error working out fair shares: %v", err)
+		return
+	}
+
+	for {
+		if s.ctx.Err() != nil {
+			return
+		}
+
+		var next *tenant
+		for _, t := range tenants {
+			if len(t.jobs) > 0 && (next == nil || t.share(capacity) < next.share(capacity)) {
+				next = t
+			}
+		}
+		if next == nil {
+			return
+		}
+
+		job := next.jobs[0]
+		next.jobs = next.jobs[1:]
+		placed, err := s.ScheduleJob(job)
+		if err != nil {
+			log.Printf("Error scheduling job %s: %v", job.ID, err)
+		}
+		if placed {
+			next.allocated = next.allocated.Add(job.Resources)
+		}
+	}
+}
+
+// tenants groups queued jobs by namespace, in name order so ties between
+// equal shares are broken the same way every pass, and returns them with
+// the resources their running jobs hold and the capacity of the active
+// workers
+func (s *Scheduler) tenants(jobs []*models.Job) ([]*tenant, models.Resources, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var capacity models.Resources
+	workers, err := s.storage.GetAvailableWorkers()
+	if err != nil {
+		return nil, capacity, err
+	}
+	for _, worker := range workers {
+		capacity = capacity.Add(worker.Resources)
+	}
+
+	byName := make(map[string]*tenant)
+	var tenants []*tenant
+	for _, job := range jobs {
+		name := models.NamespaceOf(job.Namespace)
+		t, exists := byName[name]
+		if !exists {
+			t = &tenant{name: name, weight: 1}
+			if namespace, err := s.namespace(name); err == nil && namespace.Weight > 0 {
+				t.weight = namespace.Weight
+			}
+			byName[name] = t
+			tenants = append(tenants, t)
+		}
+		t.jobs = append(t.jobs, job)
+	}
+	if len(tenants) < 2 {
+		return tenants, capacity, nil
+	}
+
+	for _, t := range tenants {
+		t.allocated = s.usage(t.name).Resources
+	}
+
+	sort.Slice(tenants, func(i, j int) bool {
+		return tenants[i].name < tenants[j].name
+	})
+	return tenants, capacity, nil
+}
+
+// share is a tenant's weighted dominant share of the cluster
+func (t *tenant) share(capacity models.Resources) float64 {
+	return dominantShare(t.allocated, capacity) / float64(t.weight)
+}
+
+// dominantShare returns the larger of the shares of the cluster's CPU and
+// memory that an allocation takes up
+func dominantShare(allocated, capacity models.Resources) float64 {
+	share := 0.0
+	if capacity.CPUCores > 0 {
+		share = float64(allocated.CPUCores) / float64(capacity.CPUCores)
+	}
+	if capacity.MemoryMB > 0 {
+		share = max(share, float64(allocated.MemoryMB)/float64(capacity.MemoryMB))
+	}
+	return share
+}
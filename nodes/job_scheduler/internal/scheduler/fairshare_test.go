@@ -0,0 +1,93 @@
+package scheduler
+
+import (
+	"testing"
+
+	"github.com/Shishir_grez/coltnode/nodes/job_scheduler/internal/queue"
+	"github.com/Shishir_grez/coltnode/nodes/job_scheduler/internal/storage"
+	"github.com/Shishir_grez/coltnode/nodes/job_scheduler/pkg/models"
+)
+
+func TestDominantShare(t *testing.T) {
+	tests := []struct {
+		name      string
+		allocated models.Resources
+		capacity  models.Resources
+		want      float64
+	}{
+		{name: "nothing allocated", allocated: models.Resources{}, capacity: models.Resources{CPUCores: 4, MemoryMB: 1024}, want: 0},
+		{name: "CPU dominates", allocated: models.Resources{CPUCores: 2, MemoryMB: 256}, capacity: models.Resources{CPUCores: 4, MemoryMB: 1024}, want: 0.5},
+		{name: "memory dominates", allocated: models.Resources{CPUCores: 1, MemoryMB: 768}, capacity: models.Resources{CPUCores: 4, MemoryMB: 1024}, want: 0.75},
+		{name: "no memory capacity", allocated: models.Resources{CPUCores: 1, MemoryMB: 512}, capacity: models.Resources{CPUCores: 4}, want: 0.25},
+		{name: "no capacity at all", allocated: models.Resources{CPUCores: 1}, capacity: models.Resources{}, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := dominantShare(tt.allocated, tt.capacity); got != tt.want {
+				t.Errorf("dominantShare(%+v, %+v) = %v, want %v", tt.allocated, tt.capacity, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFairShareOrdering(t *testing.T) {
+	tests := []struct {
+		name      string
+		fairShare bool
+		wantFirst string // Namespace whose queued job gets the last free CPU
+	}{
+		{name: "priority order by default", fairShare: false, wantFirst: "busy"},
+		{name: "least loaded namespace under fair share", fairShare: true, wantFirst: "idle"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewScheduler(queue.NewJobQueue(), storage.NewMemoryStorage())
+			s.SetFairShare(tt.fairShare)
+			for _, name := range []string{"busy", "idle"} {
+				if err := s.CreateNamespace(&models.Namespace{Name: name}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := s.RegisterWorker(models.NewWorker("w1", 3, 1024)); err != nil {
+				t.Fatal(err)
+			}
+
+			submit := func(namespace string, cpu, priority int) *models.Job {
+				job := models.NewJob(namespace, "true", nil)
+				job.Namespace = namespace
+				job.Resources = models.Resources{CPUCores: cpu}
+				job.Priority = priority
+				if err := s.Submit(job); err != nil {
+					t.Fatal(err)
+				}
+				return job
+			}
+
+			// The busy namespace already holds two of the three CPUs
+			submit("busy", 2, models.DefaultPriority)
+			s.schedulePending()
+
+			queued := map[string]*models.Job{
+				"busy": submit("busy", 1, models.MaxPriority),
+				"idle": submit("idle", 1, models.MinPriority),
+			}
+			s.schedulePending()
+
+			for namespace, job := range queued {
+				got, err := s.Job(job.ID)
+				if err != nil {
+					t.Fatal(err)
+				}
+				want := "pending"
+				if namespace == tt.wantFirst {
+					want = "running"
+				}
+				if got.Status != want {
+					t.Errorf("job in %s is %q, want %q", namespace, got.Status, want)
+				}
+			}
+		})
+	}
+}
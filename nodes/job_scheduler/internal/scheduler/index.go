@@ -0,0 +1,172 @@
+package scheduler
+
+import (
+	"sync"
+
+	"github.com/Shishir_grez/coltnode/nodes/job_scheduler/pkg/models"
+)
+
+// jobIndex keeps what the scheduler needs to know about the jobs that have
+// not finished: each namespace's usage and the jobs placed on each worker.
+// Scheduling passes read it instead of scanning every job ever submitted,
+// and it drops jobs as they finish, so it only grows with the active ones.
+type jobIndex struct {
+	mu      sync.Mutex
+	active  map[string]*models.Job            // Jobs that have not finished, by ID
+	counted map[string]indexedJob             // What each active job was last counted as, by ID
+	usage   map[string]models.Usage           // By namespace
+	placed  map[string]map[string]*models.Job // Running and cancelling jobs, by worker ID and job ID
+}
+
+// indexedJob is what a job adds to the index, kept so it can be taken off
+// again once the job changes
+type indexedJob struct {
+	namespace string
+	workerID  string // Set while the job is placed
+	running   bool   // Running or cancelling, as opposed to queued
+	resources models.Resources
+}
+
+func newJobIndex() *jobIndex {
+	return &jobIndex{
+		active:  make(map[string]*models.Job),
+		counted: make(map[string]indexedJob),
+		usage:   make(map[string]models.Usage),
+		placed:  make(map[string]map[string]*models.Job),
+	}
+}
+
+// reset rebuilds the index from every job in storage
+func (x *jobIndex) reset(jobs []*models.Job) {
+	x.mu.Lock()
+	x.active = make(map[string]*models.Job)
+	x.counted = make(map[string]indexedJob)
+	x.usage = make(map[string]models.Usage)
+	x.placed = make(map[string]map[string]*models.Job)
+	x.mu.Unlock()
+
+	for _, job := range jobs {
+		x.update(job)
+	}
+}
+
+// update recounts a job that was saved or changed
+func (x *jobIndex) update(job *models.Job) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if old, exists := x.counted[job.ID]; exists {
+		usage := x.usage[old.namespace]
+		if old.running {
+			usage.Running--
+			usage.Resources = usage.Resources.Sub(old.resources)
+		} else {
+			usage.Queued--
+		}
+		x.usage[old.namespace] = usage
+		if old.workerID != "" {
+			delete(x.placed[old.workerID], job.ID)
+			if len(x.placed[old.workerID]) == 0 {
+				delete(x.placed, old.workerID)
+			}
+		}
+		delete(x.counted, job.ID)
+		delete(x.active, job.ID)
+	}
+
+	entry := indexedJob{namespace: models.NamespaceOf(job.Namespace), resources: job.Resources}
+	switch job.Status {
+	case "running", "cancelling":
+		entry.running = true
+		entry.workerID = job.WorkerID
+	case "pending", "waiting":
+	default:
+		return
+	}
+
+	usage := x.usage[entry.namespace]
+	if entry.running {
+		usage.Running++
+		usage.Resources = usage.Resources.Add(entry.resources)
+	} else {
+		usage.Queued++
+	}
+	x.usage[entry.namespace] = usage
+	if entry.workerID != "" {
+		if x.placed[entry.workerID] == nil {
+			x.placed[entry.workerID] = make(map[string]*models.Job)
+		}
+		x.placed[entry.workerID][job.ID] = job
+	}
+	x.counted[job.ID] = entry
+	x.active[job.ID] = job
+}
+
+// namespaceUsage returns what a namespace's jobs currently use
+func (x *jobIndex) namespaceUsage(name string) models.Usage {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	return x.usage[name]
+}
+
+// placedJobs returns the running and cancelling jobs on each worker, keyed
+// by worker ID
+func (x *jobIndex) placedJobs() map[string][]*models.Job {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	placed := make(map[string][]*models.Job, len(x.placed))
+	for workerID, jobs := range x.placed {
+		for _, job := range jobs {
+			placed[workerID] = append(placed[workerID], job)
+		}
+	}
+	return placed
+}
+
+// placedOn returns the running and cancelling jobs on a worker
+func (x *jobIndex) placedOn(workerID string) []*models.Job {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	jobs := make([]*models.Job, 0, len(x.placed[workerID]))
+	for _, job := range x.placed[workerID] {
+		jobs = append(jobs, job)
+	}
+	return jobs
+}
+
+// activeJobs returns every job that has not finished
+func (x *jobIndex) activeJobs() []*models.Job {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	jobs := make([]*models.Job, 0, len(x.active))
+	for _, job := range x.active {
+		jobs = append(jobs, job)
+	}
+	return jobs
+}
+
+// indexedStorage keeps a jobIndex in step with the jobs saved to storage
+type indexedStorage struct {
+	Storage
+	index *jobIndex
+}
+
+// SaveJob stores a new job and counts it
+func (s indexedStorage) SaveJob(job *models.Job) error {
+	if err := s.Storage.SaveJob(job); err != nil {
+		return err
+	}
+	s.index.update(job)
+	return nil
+}
+
+// UpdateJob stores a changed job and recounts it. Jobs are changed in place,
+// so it is recounted even if storing the change fails.
+func (s indexedStorage) UpdateJob(job *models.Job) error {
+	s.index.update(job)
+	return s.Storage.UpdateJob(job)
+}
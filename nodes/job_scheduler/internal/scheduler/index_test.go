@@ -0,0 +1,125 @@
+package scheduler
+
+import (
+	"testing"
+
+	"github.com/Shishir_grez/coltnode/nodes/job_scheduler/pkg/models"
+)
+
+func TestJobIndex(t *testing.T) {
+	cpu := func(n int) models.Resources { return models.Resources{CPUCores: n} }
+
+	// Each step changes job "a" (or "b") and checks the index afterwards
+	type step struct {
+		id        string
+		status    string
+		namespace string
+		workerID  string
+	}
+	tests := []struct {
+		name       string
+		steps      []step
+		wantUsage  map[string]models.Usage
+		wantPlaced map[string]int
+		wantActive int
+	}{
+		{
+			name:       "queued job",
+			steps:      []step{{id: "a", status: "pending"}},
+			wantUsage:  map[string]models.Usage{models.DefaultNamespace: {Queued: 1}},
+			wantActive: 1,
+		},
+		{
+			name:       "waiting workflow job counts as queued",
+			steps:      []step{{id: "a", status: "waiting", namespace: "team-a"}},
+			wantUsage:  map[string]models.Usage{"team-a": {Queued: 1}},
+			wantActive: 1,
+		},
+		{
+			name:       "placed job",
+			steps:      []step{{id: "a", status: "pending"}, {id: "a", status: "running", workerID: "w1"}},
+			wantUsage:  map[string]models.Usage{models.DefaultNamespace: {Running: 1, Resources: cpu(2)}},
+			wantPlaced: map[string]int{"w1": 1},
+			wantActive: 1,
+		},
+		{
+			name: "cancelling job stays placed",
+			steps: []step{
+				{id: "a", status: "running", workerID: "w1"},
+				{id: "a", status: "cancelling", workerID: "w1"},
+			},
+			wantUsage:  map[string]models.Usage{models.DefaultNamespace: {Running: 1, Resources: cpu(2)}},
+			wantPlaced: map[string]int{"w1": 1},
+			wantActive: 1,
+		},
+		{
+			name: "finished job is dropped",
+			steps: []step{
+				{id: "a", status: "running", workerID: "w1"},
+				{id: "a", status: "completed", workerID: "w1"},
+			},
+			wantUsage: map[string]models.Usage{models.DefaultNamespace: {}},
+		},
+		{
+			name: "requeued job leaves its worker",
+			steps: []step{
+				{id: "a", status: "running", workerID: "w1"},
+				{id: "b", status: "running", workerID: "w1"},
+				{id: "a", status: "pending"},
+			},
+			wantUsage:  map[string]models.Usage{models.DefaultNamespace: {Running: 1, Queued: 1, Resources: cpu(2)}},
+			wantPlaced: map[string]int{"w1": 1},
+			wantActive: 2,
+		},
+		{
+			name: "namespaces are counted apart",
+			steps: []step{
+				{id: "a", status: "running", namespace: "team-a", workerID: "w1"},
+				{id: "b", status: "running", namespace: "team-b", workerID: "w2"},
+			},
+			wantUsage: map[string]models.Usage{
+				"team-a": {Running: 1, Resources: cpu(2)},
+				"team-b": {Running: 1, Resources: cpu(2)},
+			},
+			wantPlaced: map[string]int{"w1": 1, "w2": 1},
+			wantActive: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			x := newJobIndex()
+			jobs := make(map[string]*models.Job)
+			for _, st := range tt.steps {
+				job, exists := jobs[st.id]
+				if !exists {
+					job = &models.Job{ID: st.id, Resources: cpu(2)}
+					jobs[st.id] = job
+				}
+				// Jobs are changed in place, as the scheduler does
+				job.Status = st.status
+				job.Namespace = st.namespace
+				job.WorkerID = st.workerID
+				x.update(job)
+			}
+
+			for namespace, want := range tt.wantUsage {
+				if got := x.namespaceUsage(namespace); got != want {
+					t.Errorf("usage of %s = %+v, want %+v", namespace, got, want)
+				}
+			}
+			placed := x.placedJobs()
+			if len(placed) != len(tt.wantPlaced) {
+				t.Errorf("placed on %d workers, want %d", len(placed), len(tt.wantPlaced))
+			}
+			for workerID, want := range tt.wantPlaced {
+				if got := len(x.placedOn(workerID)); got != want {
+					t.Errorf("jobs placed on %s = %d, want %d", workerID, got, want)
+				}
+			}
+			if got := len(x.activeJobs()); got != tt.wantActive {
+				t.Errorf("active jobs = %d, want %d", got, tt.wantActive)
+			}
+		})
+	}
+}
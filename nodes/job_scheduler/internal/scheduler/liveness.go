@@ -0,0 +1,194 @@
+package scheduler
+
+import (
+	"log"
+	"time"
+
+	"github.com/Shishir_grez/coltnode/nodes/job_scheduler/pkg/models"
+)
+
+// lostJobGrace is how long a worker that comes back gets to stop jobs that were
+// taken from it while it was offline
+const lostJobGrace = 10 * time.Second
+
+// lostJobError is recorded on the attempt of a job taken from a reaped worker
+const lostJobError = "worker stopped sending heartbeats"
+
+// Heartbeat records that a worker is alive, bringing it back online if it had
+// been reaped. A worker that was cordoned comes back cordoned. Jobs taken from
+// it while it was offline are reconciled before it can be given new ones.
+func (s *Scheduler) Heartbeat(workerID string) (*models.Worker, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	worker, err := s.storage.GetWorker(workerID)
+	if err != nil {
+		return nil, err
+	}
+
+	worker.LastHeartbeat = time.Now()
+	if worker.Status == "offline" {
+		log.Printf("Worker %s is back online", worker.ID)
+		if err := s.reconcileLostJobs(worker); err != nil {
+			return nil, err
+		}
+		if worker.Cordoned {
+			worker.Status = "cordoned"
+		} else {
+			worker.Status = "active"
+			s.notify()
+		}
+	}
+	return worker.Clone(), s.storage.UpdateWorker(worker)
+}
+
+// StartReaper periodically marks workers that have not sent a heartbeat
+// within timeout as offline and requeues the jobs they were running
+func (s *Scheduler) StartReaper(timeout time.Duration) {
+	s.goEvery(timeout/3, func() {
+		if err := s.reapWorkers(timeout); err != nil {
+			log.Printf("Error reaping workers: %v", err)
+		}
+	})
+}
+
+// reapWorkers runs a single pass of the reaper
+func (s *Scheduler) reapWorkers(timeout time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	workers, err := s.storage.GetAllWorkers()
+	if err != nil {
+		return err
+	}
+
+	deadline := time.Now().Add(-timeout)
+	for _, worker := range workers {
+		if worker.Status == "offline" || worker.LastHeartbeat.After(deadline) {
+			continue
+		}
+
+		log.Printf("Worker %s missed its heartbeat, marking offline", worker.ID)
+		worker.Status = "offline"
+		// Everything it was running goes back to the queue below
+		worker.Allocated = models.Resources{}
+		if err := s.storage.UpdateWorker(worker); err != nil {
+			return err
+		}
+		if err := s.requeueWorkerJobs(worker.ID, true); err != nil {
+			return err
+		}
+		s.finishDrain(worker.ID)
+	}
+	return nil
+}
+
+// requeueWorkerJobs puts every job assigned to a worker back in the queue,
+// including those it never picked up, and finishes any it was stopping. If the
+// worker died, each job it was running loses an attempt, and is left failed
+// once it has none left. Callers must hold s.mu.
+func (s *Scheduler) requeueWorkerJobs(workerID string, dead bool) error {
+	// Drop instructions waiting for pickup; jobs are requeued from storage below
+	queue := s.assignmentQueue(workerID)
+	for len(queue) > 0 {
+		<-queue
+	}
+
+	now := time.Now()
+	for _, job := range s.index.placedOn(workerID) {
+		// Jobs that were being stopped are done; running ones go back in the queue
+		switch {
+		case s.timingOut[job.ID]:
+			job.Status = "timed_out"
+			delete(s.timingOut, job.ID)
+		case job.Status == "cancelling":
+			job.Status = "cancelled"
+		case job.Status == "running" && (job.Undelivered || !dead):
+			// Never picked up, so the attempt did not start, or taken away on purpose
+			job.Status = "pending"
+		case job.Status == "running":
+			job.Attempts = append(job.Attempts, models.Attempt{
+				WorkerID:  workerID,
+				Status:    "failed",
+				ExitCode:  -1,
+				StartTime: job.StartTime,
+				EndTime:   now,
+				Error:     lostJobError,
+			})
+			job.Status = "pending"
+			if len(job.Attempts) >= job.MaxAttempts {
+				job.Status = "failed"
+				job.ExitCode = -1
+				job.Error = lostJobError
+			}
+		default:
+			continue
+		}
+		// The worker may still be running it if it comes back
+		if dead && !job.Undelivered {
+			s.lost[workerID] = append(s.lost[workerID], job.ID)
+		}
+
+		if job.Status == "pending" {
+			log.Printf("Requeueing job %s from dead worker %s", job.ID, workerID)
+			job.WorkerID = ""
+			job.Undelivered = false
+		} else {
+			job.EndTime = now
+		}
+		if err := s.storage.UpdateJob(job); err != nil {
+			return err
+		}
+		if job.Status == "pending" {
+			s.enqueue(job)
+		}
+		if err := s.releaseDependents(job); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reconcileLostJobs settles the jobs taken from a reaped worker that has come
+// back, before it is given new ones. Jobs still waiting to be placed again are
+// handed back to it, as it may well still be running them; the others are
+// now finished or running elsewhere, so it is told to stop them.
+// Callers must hold s.mu.
+func (s *Scheduler) reconcileLostJobs(worker *models.Worker) error {
+	lost := s.lost[worker.ID]
+	delete(s.lost, worker.ID)
+
+	queue := s.assignmentQueue(worker.ID)
+	for _, jobID := range lost {
+		job, err := s.storage.GetJob(jobID)
+		if err != nil {
+			continue
+		}
+
+		if job.Status == "pending" && (s.jobQueue.Remove(job.ID) || s.delayed.Remove(job.ID)) {
+			log.Printf("Worker %s adopts job %s back", worker.ID, job.ID)
+			// The attempt was not lost after all
+			if last := len(job.Attempts) - 1; last >= 0 && job.Attempts[last].Error == lostJobError {
+				job.Attempts = job.Attempts[:last]
+			}
+			job.Status = "running"
+			job.WorkerID = worker.ID
+			job.RetryAt = time.Time{}
+			job.QueuedAt = time.Time{}
+			job.Reason = ""
+			worker.Allocated = worker.Allocated.Add(job.Resources)
+			if err := s.storage.UpdateJob(job); err != nil {
+				return err
+			}
+			continue
+		}
+
+		log.Printf("Telling worker %s to stop job %s", worker.ID, job.ID)
+		select {
+		case queue <- models.Assignment{Cancel: job.ID, GracePeriod: lostJobGrace}:
+		default:
+			log.Printf("Could not tell worker %s to stop job %s, its pickup queue is full", worker.ID, job.ID)
+		}
+	}
+	return nil
+}
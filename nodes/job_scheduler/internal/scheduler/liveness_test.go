@@ -0,0 +1,185 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Shishir_grez/coltnode/nodes/job_scheduler/internal/queue"
+	"github.com/Shishir_grez/coltnode/nodes/job_scheduler/internal/storage"
+	"github.com/Shishir_grez/coltnode/nodes/job_scheduler/pkg/models"
+)
+
+func TestReapWorkersRequeuesJobs(t *testing.T) {
+	tests := []struct {
+		name         string
+		silentFor    time.Duration
+		pickedUp     bool // Whether the worker started the job before it went silent
+		maxAttempts  int
+		cancel       bool
+		wantJob      string
+		wantWorker   string
+		wantQueued   int
+		wantAllocCPU int
+	}{
+		{name: "live worker keeps its job", silentFor: 0, wantJob: "running", wantWorker: "active", wantQueued: 0, wantAllocCPU: 1},
+		{name: "dead worker's job is requeued", silentFor: time.Minute, wantJob: "pending", wantWorker: "offline", wantQueued: 1, wantAllocCPU: 0},
+		{name: "dead worker's running job loses an attempt", silentFor: time.Minute, pickedUp: true, maxAttempts: 2, wantJob: "pending", wantWorker: "offline", wantQueued: 1, wantAllocCPU: 0},
+		{name: "dead worker's running job out of attempts fails", silentFor: time.Minute, pickedUp: true, maxAttempts: 1, wantJob: "failed", wantWorker: "offline", wantQueued: 0, wantAllocCPU: 0},
+		{name: "dead worker's cancelling job is cancelled", silentFor: time.Minute, cancel: true, wantJob: "cancelled", wantWorker: "offline", wantQueued: 0, wantAllocCPU: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := storage.NewMemoryStorage()
+			jobQueue := queue.NewJobQueue()
+			s := NewScheduler(jobQueue, store)
+
+			worker := models.NewWorker("w1", 2, 1024)
+			if err := s.RegisterWorker(worker); err != nil {
+				t.Fatal(err)
+			}
+			job := models.NewJob("sleep", "sleep", []string{"60"})
+			job.Resources = models.Resources{CPUCores: 1}
+			if tt.maxAttempts > 0 {
+				job.MaxAttempts = tt.maxAttempts
+			}
+			if err := s.Submit(job); err != nil {
+				t.Fatal(err)
+			}
+			s.schedulePending()
+			if tt.pickedUp {
+				nextAssignment(t, s, worker.ID)
+			}
+			if tt.cancel {
+				if _, err := s.CancelJob(job.ID, time.Second); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			stored, err := store.GetWorker(worker.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			stored.LastHeartbeat = time.Now().Add(-tt.silentFor)
+			if err := s.reapWorkers(30 * time.Second); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := s.Job(job.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Status != tt.wantJob {
+				t.Errorf("job status = %q, want %q", got.Status, tt.wantJob)
+			}
+			// Only a run the worker started counts as an attempt
+			wantAttempts := 0
+			if tt.pickedUp {
+				wantAttempts = 1
+			}
+			if len(got.Attempts) != wantAttempts {
+				t.Errorf("job has %d attempts, want %d", len(got.Attempts), wantAttempts)
+			}
+			if got := jobQueue.Size(); got != tt.wantQueued {
+				t.Errorf("queued jobs = %d, want %d", got, tt.wantQueued)
+			}
+			w, err := s.Worker(worker.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if w.Status != tt.wantWorker {
+				t.Errorf("worker status = %q, want %q", w.Status, tt.wantWorker)
+			}
+			if w.Allocated.CPUCores != tt.wantAllocCPU {
+				t.Errorf("allocated CPU = %d, want %d", w.Allocated.CPUCores, tt.wantAllocCPU)
+			}
+		})
+	}
+}
+
+func TestReapedWorkerComesBack(t *testing.T) {
+	tests := []struct {
+		name        string
+		placedAgain bool // Whether the job was placed on another worker meanwhile
+		wantAdopted bool
+	}{
+		{name: "job still queued is adopted", wantAdopted: true},
+		{name: "job placed elsewhere is stopped", placedAgain: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jobQueue := queue.NewJobQueue()
+			s := NewScheduler(jobQueue, storage.NewMemoryStorage())
+			registerNamed(t, s, "a")
+			job := models.NewJob("sleep", "sleep", []string{"60"})
+			job.Resources = models.Resources{CPUCores: 1}
+			job.MaxAttempts = 3
+			submitJob(t, s, job)
+			scheduleQueued(t, s)
+			nextAssignment(t, s, "a")
+
+			// a goes silent and its job goes back in the queue
+			worker, err := s.storage.GetWorker("a")
+			if err != nil {
+				t.Fatal(err)
+			}
+			worker.LastHeartbeat = time.Now().Add(-time.Minute)
+			if err := s.reapWorkers(30 * time.Second); err != nil {
+				t.Fatal(err)
+			}
+			if tt.placedAgain {
+				registerNamed(t, s, "b")
+				scheduleQueued(t, s)
+				// Anything new has to go to a
+				if _, err := s.CordonWorker("b"); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if _, err := s.Heartbeat("a"); err != nil {
+				t.Fatal(err)
+			}
+			next := models.NewJob("next", "true", nil)
+			submitJob(t, s, next)
+			scheduleQueued(t, s)
+
+			got := storedJob(t, s, job.ID)
+			assignment := nextAssignment(t, s, "a")
+			if tt.wantAdopted {
+				if got.Status != "running" || got.WorkerID != "a" || len(got.Attempts) != 0 {
+					t.Errorf("job is %q on %q with %d attempts, want running on a with none used", got.Status, got.WorkerID, len(got.Attempts))
+				}
+				if jobQueue.Size() != 0 {
+					t.Errorf("queued jobs = %d, want the job taken out of the queue", jobQueue.Size())
+				}
+				if assignment == nil || assignment.Job == nil || assignment.Job.ID != next.ID {
+					t.Errorf("worker got %+v, want the next job", assignment)
+				}
+				return
+			}
+
+			if got.Status != "running" || got.WorkerID != "b" || len(got.Attempts) != 1 {
+				t.Errorf("job is %q on %q with %d attempts, want running on b after one lost attempt", got.Status, got.WorkerID, len(got.Attempts))
+			}
+			if assignment == nil || assignment.Cancel != job.ID {
+				t.Fatalf("worker got %+v first, want it told to stop the job", assignment)
+			}
+			if assignment := nextAssignment(t, s, "a"); assignment == nil || assignment.Job == nil || assignment.Job.ID != next.ID {
+				t.Errorf("worker got %+v after stopping the job, want the next job", assignment)
+			}
+		})
+	}
+}
+
+func TestStartReaperNonPositiveTimeout(t *testing.T) {
+	for _, timeout := range []time.Duration{0, -time.Second, 2 * time.Nanosecond} {
+		t.Run(timeout.String(), func(t *testing.T) {
+			s := NewScheduler(queue.NewJobQueue(), storage.NewMemoryStorage())
+			s.StartReaper(timeout)
+			if err := s.Stop(t.Context()); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
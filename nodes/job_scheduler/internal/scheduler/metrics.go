@@ -0,0 +1,71 @@
+package scheduler
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// QueueMetrics describes the job queue and how long jobs waited in it before
+// being placed on a worker. Durations are in seconds.
+type QueueMetrics struct {
+	Queued             int     `json:"queued"`               // Jobs ready to run and waiting for a worker
+	Unschedulable      int     `json:"unschedulable"`        // Queued jobs no active worker is large enough for
+	Delayed            int     `json:"delayed"`              // Pending jobs held back until a later time
+	OldestWaitSeconds  float64 `json:"oldest_wait_seconds"`  // Longest current wait of a queued job
+	Placed             int64   `json:"placed"`               // Jobs placed on a worker since the server started
+	AverageWaitSeconds float64 `json:"average_wait_seconds"` // Mean wait of placed jobs
+	MaxWaitSeconds     float64 `json:"max_wait_seconds"`     // Longest wait of a placed job
+	LastWaitSeconds    float64 `json:"last_wait_seconds"`    // Wait of the most recently placed job
+}
+
+// waitStats accumulates how long placed jobs waited in the queue
+type waitStats struct {
+	count int64
+	total time.Duration
+	max   time.Duration
+	last  time.Duration
+	mu    sync.Mutex
+}
+
+// record adds the wait of a job that was just placed
+func (w *waitStats) record(wait time.Duration) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.count++
+	w.total += wait
+	w.last = wait
+	if wait > w.max {
+		w.max = wait
+	}
+}
+
+// Metrics returns the current state of the queue and the wait statistics
+func (s *Scheduler) Metrics() QueueMetrics {
+	now := time.Now()
+	metrics := QueueMetrics{Delayed: s.delayed.Size()}
+
+	s.mu.Lock()
+	for _, job := range s.jobQueue.Jobs() {
+		metrics.Queued++
+		if strings.HasPrefix(job.Reason, "unschedulable") {
+			metrics.Unschedulable++
+		}
+		if wait := now.Sub(job.QueuedAt).Seconds(); !job.QueuedAt.IsZero() && wait > metrics.OldestWaitSeconds {
+			metrics.OldestWaitSeconds = wait
+		}
+	}
+	s.mu.Unlock()
+
+	s.waits.mu.Lock()
+	defer s.waits.mu.Unlock()
+
+	metrics.Placed = s.waits.count
+	if s.waits.count > 0 {
+		metrics.AverageWaitSeconds = (s.waits.total / time.Duration(s.waits.count)).Seconds()
+	}
+	metrics.MaxWaitSeconds = s.waits.max.Seconds()
+	metrics.LastWaitSeconds = s.waits.last.Seconds()
+	return metrics
+}
@@ -0,0 +1,251 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/Shishir_grez/coltnode/nodes/job_scheduler/pkg/models"
+)
+
+var (
+	// ErrUnknownNamespace is returned when something refers to a namespace that was not created
+	ErrUnknownNamespace = errors.New("unknown namespace")
+	// ErrNamespaceExists is returned when creating a namespace whose name is taken
+	ErrNamespaceExists = errors.New("namespace already exists")
+	// ErrNamespaceInUse is returned when deleting a namespace that still has jobs, schedules or workers
+	ErrNamespaceInUse = errors.New("namespace is in use")
+	// ErrQuotaExceeded is returned when submitting jobs would take a namespace over its quota
+	ErrQuotaExceeded = errors.New("namespace quota exceeded")
+)
+
+// CreateNamespace saves a new namespace. The scheduler keeps its own copy,
+// so the caller may go on reading namespace.
+func (s *Scheduler) CreateNamespace(namespace *models.Namespace) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.namespace(namespace.Name); err == nil {
+		return ErrNamespaceExists
+	}
+	namespace.CreateTime = time.Now()
+	return s.storage.SaveNamespace(namespace.Clone())
+}
+
+// Namespaces returns copies of every namespace, including the default one
+func (s *Scheduler) Namespaces() ([]*models.Namespace, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	namespaces, err := s.storage.GetAllNamespaces()
+	if err != nil {
+		return nil, err
+	}
+	for i, namespace := range namespaces {
+		namespaces[i] = namespace.Clone()
+	}
+	if !slices.ContainsFunc(namespaces, func(namespace *models.Namespace) bool { return namespace.Name == models.DefaultNamespace }) {
+		namespaces = append(namespaces, &models.Namespace{Name: models.DefaultNamespace})
+	}
+	sort.Slice(namespaces, func(i, j int) bool {
+		return namespaces[i].Name < namespaces[j].Name
+	})
+	return namespaces, nil
+}
+
+// Namespace returns a copy of a namespace by name, including the default
+// namespace before a quota was ever set on it
+func (s *Scheduler) Namespace(name string) (*models.Namespace, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	namespace, err := s.namespace(name)
+	if err != nil {
+		return nil, err
+	}
+	return namespace.Clone(), nil
+}
+
+// SetQuota replaces a namespace's quota. Jobs already running are not
+// stopped if they are over the new quota; no new ones start until they fit.
+func (s *Scheduler) SetQuota(name string, quota models.Quota) (*models.Namespace, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	namespace, err := s.namespace(name)
+	if err != nil {
+		return nil, err
+	}
+
+	namespace.Quota = quota
+	if err := s.saveNamespace(namespace); err != nil {
+		return nil, err
+	}
+
+	log.Printf("Quota of namespace %s set to %+v", name, quota)
+	s.notify()
+	return namespace.Clone(), nil
+}
+
+// DeleteNamespace removes a namespace that has no unfinished jobs, schedules
+// or workers left. The default namespace cannot be deleted.
+func (s *Scheduler) DeleteNamespace(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if name == models.DefaultNamespace {
+		return fmt.Errorf("%w: the %s namespace cannot be deleted", ErrNamespaceInUse, name)
+	}
+	if _, err := s.storage.GetNamespace(name); err != nil {
+		return ErrUnknownNamespace
+	}
+
+	usage := s.usage(name)
+	if usage.Running > 0 || usage.Queued > 0 {
+		return fmt.Errorf("%w: it has %d running and %d queued jobs", ErrNamespaceInUse, usage.Running, usage.Queued)
+	}
+
+	schedules, err := s.storage.GetAllSchedules()
+	if err != nil {
+		return err
+	}
+	for _, schedule := range schedules {
+		if models.NamespaceOf(schedule.Namespace) == name {
+			return fmt.Errorf("%w: schedule %s belongs to it", ErrNamespaceInUse, schedule.ID)
+		}
+	}
+
+	workers, err := s.storage.GetAllWorkers()
+	if err != nil {
+		return err
+	}
+	for _, worker := range workers {
+		if worker.Namespace == name {
+			return fmt.Errorf("%w: worker %s belongs to it", ErrNamespaceInUse, worker.ID)
+		}
+	}
+
+	return s.storage.DeleteNamespace(name)
+}
+
+// Usage returns what a namespace's jobs currently use, including the share
+// of the active workers' capacity they hold
+func (s *Scheduler) Usage(name string) (models.Usage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	usage := s.usage(name)
+	workers, err := s.storage.GetAvailableWorkers()
+	if err != nil {
+		return usage, err
+	}
+	var capacity models.Resources
+	for _, worker := range workers {
+		capacity = capacity.Add(worker.Resources)
+	}
+	usage.DominantShare = dominantShare(usage.Resources, capacity)
+	return usage, nil
+}
+
+// namespace looks up a namespace by name. Callers must hold s.mu.
+func (s *Scheduler) namespace(name string) (*models.Namespace, error) {
+	name = models.NamespaceOf(name)
+	namespace, err := s.storage.GetNamespace(name)
+	if err == nil {
+		return namespace, nil
+	}
+	if name == models.DefaultNamespace {
+		return &models.Namespace{Name: name}, nil
+	}
+	return nil, fmt.Errorf("%w %q", ErrUnknownNamespace, name)
+}
+
+// saveNamespace stores a changed namespace. The default namespace is only
+// saved once it is first configured. Callers must hold s.mu.
+func (s *Scheduler) saveNamespace(namespace *models.Namespace) error {
+	if _, err := s.storage.GetNamespace(namespace.Name); err != nil {
+		return s.storage.SaveNamespace(namespace)
+	}
+	return s.storage.UpdateNamespace(namespace)
+}
+
+// usage returns what a namespace's jobs currently use. Callers must hold s.mu.
+func (s *Scheduler) usage(name string) models.Usage {
+	return s.index.namespaceUsage(name)
+}
+
+// admit checks that jobs about to be submitted together belong to an
+// existing namespace and fit in its quota for queued jobs, and that none of
+// them needs more resources than the namespace may ever use. Jobs without a
+// namespace are put in the default one. Callers must hold s.mu.
+func (s *Scheduler) admit(jobs []*models.Job) error {
+	admitted := make(map[string]int)
+	for _, job := range jobs {
+		job.Namespace = models.NamespaceOf(job.Namespace)
+		namespace, err := s.namespace(job.Namespace)
+		if err != nil {
+			return err
+		}
+
+		limit := namespace.Quota.Resources
+		if !withinQuota(limit, job.Resources) {
+			return fmt.Errorf("%w: job %q needs %d CPU cores and %d MB of memory, namespace %s may use at most %d CPU cores and %d MB",
+				ErrQuotaExceeded, job.Name, job.Resources.CPUCores, job.Resources.MemoryMB, namespace.Name, limit.CPUCores, limit.MemoryMB)
+		}
+		admitted[namespace.Name]++
+	}
+
+	for name, count := range admitted {
+		namespace, err := s.namespace(name)
+		if err != nil {
+			return err
+		}
+		if namespace.Quota.MaxQueued == 0 {
+			continue
+		}
+		usage := s.usage(name)
+		if usage.Queued+count > namespace.Quota.MaxQueued {
+			return fmt.Errorf("%w: namespace %s allows %d queued jobs and has %d", ErrQuotaExceeded, name, namespace.Quota.MaxQueued, usage.Queued)
+		}
+	}
+	return nil
+}
+
+// quotaReason explains why starting a job would take its namespace over its
+// quota for running jobs or resources, or returns "" if it fits.
+// Callers must hold s.mu.
+func (s *Scheduler) quotaReason(job *models.Job) (string, error) {
+	namespace, err := s.namespace(job.Namespace)
+	if err != nil {
+		return "", err
+	}
+	quota := namespace.Quota
+	if quota.MaxRunning == 0 && quota.Resources == (models.Resources{}) {
+		return "", nil
+	}
+
+	usage := s.usage(namespace.Name)
+	if quota.MaxRunning > 0 && usage.Running >= quota.MaxRunning {
+		return fmt.Sprintf("queued: namespace %s is at its quota of %d running jobs", namespace.Name, quota.MaxRunning), nil
+	}
+	if !withinQuota(quota.Resources, usage.Resources.Add(job.Resources)) {
+		return fmt.Sprintf("queued: namespace %s would go over its quota of %d CPU cores and %d MB of memory", namespace.Name, quota.Resources.CPUCores, quota.Resources.MemoryMB), nil
+	}
+	return "", nil
+}
+
+// withinQuota reports whether used fits in limit, where a zero limit on
+// CPU or memory means no limit on it
+func withinQuota(limit, used models.Resources) bool {
+	return (limit.CPUCores == 0 || used.CPUCores <= limit.CPUCores) &&
+		(limit.MemoryMB == 0 || used.MemoryMB <= limit.MemoryMB)
+}
+
+// inPool reports whether a worker takes jobs from a job's namespace: shared
+// workers take jobs from every namespace, others only from their own
+func inPool(job *models.Job, worker *models.Worker) bool {
+	return worker.Namespace == "" || worker.Namespace == models.NamespaceOf(job.Namespace)
+}
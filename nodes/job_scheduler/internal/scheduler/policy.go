@@ -0,0 +1,156 @@
+package scheduler
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+
+	"github.com/Shishir_grez/coltnode/nodes/job_scheduler/pkg/models"
+)
+
+// Policy decides which worker a job is placed on
+type Policy interface {
+	// Select returns one of the candidates, all of which have room for the job.
+	// Candidates are sorted by worker ID. Returning nil leaves the job queued.
+	Select(job *models.Job, candidates []*models.Worker) *models.Worker
+}
+
+// Names of the built-in policies
+const (
+	PolicyRoundRobin  = "round-robin"
+	PolicyLeastLoaded = "least-loaded"
+	PolicyRandom      = "random"
+	PolicyBinPack     = "bin-pack"
+	PolicySpread      = "spread"
+)
+
+// DefaultPolicy is used when neither the server nor the job picks a policy
+const DefaultPolicy = PolicyBinPack
+
+// NewPolicy creates a built-in policy by name
+func NewPolicy(name string) (Policy, error) {
+	switch name {
+	case PolicyRoundRobin:
+		return &roundRobinPolicy{}, nil
+	case PolicyLeastLoaded:
+		return leastLoadedPolicy{}, nil
+	case PolicyRandom:
+		return randomPolicy{}, nil
+	case PolicyBinPack:
+		return binPackPolicy{}, nil
+	case PolicySpread:
+		return spreadPolicy{}, nil
+	default:
+		return nil, fmt.Errorf("unknown scheduling policy %q", name)
+	}
+}
+
+// PolicyNames lists the built-in policies
+func PolicyNames() []string {
+	return []string{PolicyRoundRobin, PolicyLeastLoaded, PolicyRandom, PolicyBinPack, PolicySpread}
+}
+
+// roundRobinPolicy cycles through workers in ID order. It remembers the last
+// worker it picked rather than an index, so workers joining or leaving does
+// not make it skip or repeat anyone.
+type roundRobinPolicy struct {
+	lastID string
+	mu     sync.Mutex
+}
+
+func (p *roundRobinPolicy) Select(job *models.Job, candidates []*models.Worker) *models.Worker {
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	next := candidates[0]
+	if i := sort.Search(len(candidates), func(i int) bool { return candidates[i].ID > p.lastID }); i < len(candidates) {
+		next = candidates[i]
+	}
+	p.lastID = next.ID
+	return next
+}
+
+// leastLoadedPolicy picks the worker with the lowest current utilization
+type leastLoadedPolicy struct{}
+
+func (leastLoadedPolicy) Select(job *models.Job, candidates []*models.Worker) *models.Worker {
+	return minBy(candidates, func(worker *models.Worker) float64 {
+		return utilization(worker.Allocated, worker.Resources)
+	})
+}
+
+// randomPolicy picks any worker with room for the job
+type randomPolicy struct{}
+
+func (randomPolicy) Select(job *models.Job, candidates []*models.Worker) *models.Worker {
+	if len(candidates) == 0 {
+		return nil
+	}
+	return candidates[rand.Intn(len(candidates))]
+}
+
+// binPackPolicy picks the worker that would have the least capacity left
+// after taking the job, keeping other workers free for large jobs
+type binPackPolicy struct{}
+
+func (binPackPolicy) Select(job *models.Job, candidates []*models.Worker) *models.Worker {
+	return minBy(candidates, func(worker *models.Worker) float64 {
+		return leftover(worker, job.Resources)
+	})
+}
+
+// spreadPolicy picks the worker that would have the most capacity left
+// after taking the job, spreading work evenly over the cluster
+type spreadPolicy struct{}
+
+func (spreadPolicy) Select(job *models.Job, candidates []*models.Worker) *models.Worker {
+	return minBy(candidates, func(worker *models.Worker) float64 {
+		return -leftover(worker, job.Resources)
+	})
+}
+
+// minBy returns the worker with the lowest score, preferring earlier workers on ties
+func minBy(workers []*models.Worker, score func(*models.Worker) float64) *models.Worker {
+	var best *models.Worker
+	bestScore := 0.0
+	for _, worker := range workers {
+		if s := score(worker); best == nil || s < bestScore {
+			best = worker
+			bestScore = s
+		}
+	}
+	return best
+}
+
+// leftover is the capacity a worker would have free after taking req, as a
+// fraction of its size summed over CPU and memory
+func leftover(worker *models.Worker, req models.Resources) float64 {
+	left := worker.Free().Sub(req)
+	score := 0.0
+	if worker.Resources.CPUCores > 0 {
+		score += float64(left.CPUCores) / float64(worker.Resources.CPUCores)
+	}
+	if worker.Resources.MemoryMB > 0 {
+		score += float64(left.MemoryMB) / float64(worker.Resources.MemoryMB)
+	}
+	return score
+}
+
+// utilization is the dominant share of total that used takes up
+func utilization(used, total models.Resources) float64 {
+	share := 0.0
+	if total.CPUCores > 0 {
+		share = float64(used.CPUCores) / float64(total.CPUCores)
+	}
+	if total.MemoryMB > 0 {
+		if mem := float64(used.MemoryMB) / float64(total.MemoryMB); mem > share {
+			share = mem
+		}
+	}
+	return share
+}
@@ -0,0 +1,143 @@
+package scheduler
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/Shishir_grez/coltnode/nodes/job_scheduler/internal/queue"
+	"github.com/Shishir_grez/coltnode/nodes/job_scheduler/internal/storage"
+	"github.com/Shishir_grez/coltnode/nodes/job_scheduler/pkg/models"
+)
+
+// policyWorkers returns three workers, sorted by ID like the candidates a
+// policy is given: a is small and nearly full, b is small and nearly idle,
+// c is large and half used
+func policyWorkers() []*models.Worker {
+	worker := func(id string, cpu, memory, usedCPU, usedMemory int) *models.Worker {
+		return &models.Worker{
+			ID:        id,
+			Name:      id,
+			Status:    "active",
+			Resources: models.Resources{CPUCores: cpu, MemoryMB: memory},
+			Allocated: models.Resources{CPUCores: usedCPU, MemoryMB: usedMemory},
+		}
+	}
+	return []*models.Worker{
+		worker("a", 8, 8192, 6, 6144),
+		worker("b", 4, 4096, 1, 1024),
+		worker("c", 16, 16384, 8, 4096),
+	}
+}
+
+func TestPolicySelect(t *testing.T) {
+	job := &models.Job{ID: "job", Resources: models.Resources{CPUCores: 1, MemoryMB: 1024}}
+
+	tests := []struct {
+		policy string
+		want   string
+	}{
+		// Least capacity left after the job
+		{policy: PolicyBinPack, want: "a"},
+		// Most capacity left after the job
+		{policy: PolicySpread, want: "c"},
+		// Lowest dominant share in use
+		{policy: PolicyLeastLoaded, want: "b"},
+		// First worker in ID order
+		{policy: PolicyRoundRobin, want: "a"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			policy, err := NewPolicy(tt.policy)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := policy.Select(job, policyWorkers()); got == nil || got.ID != tt.want {
+				t.Errorf("Select() = %v, want worker %s", got, tt.want)
+			}
+			if got := policy.Select(job, nil); got != nil {
+				t.Errorf("Select() with no candidates = %s, want nil", got.ID)
+			}
+		})
+	}
+}
+
+func TestRandomPolicy(t *testing.T) {
+	job := &models.Job{ID: "job"}
+	workers := policyWorkers()
+	policy, err := NewPolicy(PolicyRandom)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 50; i++ {
+		if got := policy.Select(job, workers); !slices.Contains(workers, got) {
+			t.Fatalf("Select() = %v, want one of the candidates", got)
+		}
+	}
+	if got := policy.Select(job, nil); got != nil {
+		t.Errorf("Select() with no candidates = %s, want nil", got.ID)
+	}
+}
+
+func TestRoundRobinPolicy(t *testing.T) {
+	job := &models.Job{ID: "job"}
+	all := policyWorkers()
+	a, b, c := all[0], all[1], all[2]
+
+	policy := &roundRobinPolicy{}
+	tests := []struct {
+		name       string
+		candidates []*models.Worker
+		want       string
+	}{
+		{name: "starts at the first worker", candidates: all, want: "a"},
+		{name: "moves on", candidates: all, want: "b"},
+		{name: "moves on again", candidates: all, want: "c"},
+		{name: "wraps around", candidates: all, want: "a"},
+		{name: "skips a worker without room", candidates: []*models.Worker{a, c}, want: "c"},
+		{name: "wraps when the last worker left", candidates: []*models.Worker{a, b}, want: "a"},
+		{name: "continues after the last pick", candidates: all, want: "b"},
+	}
+
+	for _, tt := range tests {
+		if got := policy.Select(job, tt.candidates); got == nil || got.ID != tt.want {
+			t.Fatalf("%s: Select() = %v, want worker %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPolicyNames(t *testing.T) {
+	s := NewScheduler(queue.NewJobQueue(), storage.NewMemoryStorage())
+
+	tests := []struct {
+		name    string
+		wantErr bool
+	}{
+		{name: PolicyRoundRobin},
+		{name: PolicyLeastLoaded},
+		{name: PolicyRandom},
+		{name: PolicyBinPack},
+		{name: PolicySpread},
+		{name: "", wantErr: true},
+		{name: "fastest", wantErr: true},
+		{name: "Bin-Pack", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewPolicy(tt.name); (err != nil) != tt.wantErr {
+				t.Errorf("NewPolicy(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			}
+			if err := s.SetDefaultPolicy(tt.name); (err != nil) != tt.wantErr {
+				t.Errorf("SetDefaultPolicy(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			}
+			if got := s.HasPolicy(tt.name); got == tt.wantErr {
+				t.Errorf("HasPolicy(%q) = %v, want %v", tt.name, got, !tt.wantErr)
+			}
+			if got := slices.Contains(PolicyNames(), tt.name); got == tt.wantErr {
+				t.Errorf("PolicyNames() contains %q = %v, want %v", tt.name, got, !tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Shishir_grez/coltnode/nodes/job_scheduler/internal/queue"
+	"github.com/Shishir_grez/coltnode/nodes/job_scheduler/internal/storage"
+	"github.com/Shishir_grez/coltnode/nodes/job_scheduler/pkg/models"
+)
+
+func TestRecoverRequeuesUndeliveredJobs(t *testing.T) {
+	tests := []struct {
+		name       string
+		pickedUp   bool
+		wantStatus string
+		wantQueued int
+		wantCPU    int
+	}{
+		{name: "never picked up", pickedUp: false, wantStatus: "pending", wantQueued: 1, wantCPU: 0},
+		{name: "picked up", pickedUp: true, wantStatus: "running", wantQueued: 0, wantCPU: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			before, err := storage.NewWALStorage(dir, 1000, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			s := NewScheduler(queue.NewJobQueue(), before)
+
+			worker := models.NewWorker("w1", 2, 1024)
+			if err := s.RegisterWorker(worker); err != nil {
+				t.Fatal(err)
+			}
+			job := models.NewJob("sleep", "sleep", []string{"60"})
+			job.Resources = models.Resources{CPUCores: 1}
+			if err := s.Submit(job); err != nil {
+				t.Fatal(err)
+			}
+			s.schedulePending()
+
+			if tt.pickedUp {
+				ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+				defer cancel()
+				assignment, err := s.NextAssignment(ctx, worker.ID)
+				if err != nil || assignment == nil || assignment.Job == nil || assignment.Job.ID != job.ID {
+					t.Fatalf("NextAssignment() = %+v, %v, want job %s", assignment, err, job.ID)
+				}
+			}
+
+			// Simulate the server being killed: the pickup queues are lost and
+			// only what reached the log survives
+			if err := before.Close(); err != nil {
+				t.Fatal(err)
+			}
+			after, err := storage.NewWALStorage(dir, 1000, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer after.Close()
+			jobQueue := queue.NewJobQueue()
+			restarted := NewScheduler(jobQueue, after)
+			if err := restarted.Recover(); err != nil {
+				t.Fatal(err)
+			}
+
+			recovered, err := after.GetJob(job.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if recovered.Status != tt.wantStatus {
+				t.Errorf("status = %q, want %q", recovered.Status, tt.wantStatus)
+			}
+			if got := jobQueue.Size(); got != tt.wantQueued {
+				t.Errorf("queued jobs = %d, want %d", got, tt.wantQueued)
+			}
+			w, err := after.GetWorker(worker.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if w.Allocated.CPUCores != tt.wantCPU {
+				t.Errorf("allocated CPU = %d, want %d", w.Allocated.CPUCores, tt.wantCPU)
+			}
+		})
+	}
+}
@@ -0,0 +1,21 @@
+package scheduler
+
+import (
+	"log"
+	"time"
+
+	"github.com/Shishir_grez/coltnode/nodes/job_scheduler/pkg/models"
+)
+
+// retryLater puts a failed job back to pending and schedules it to be
+// enqueued again once its backoff delay is over. Callers must hold s.mu.
+func (s *Scheduler) retryLater(job *models.Job) {
+	delay := job.Backoff.NextDelay(len(job.Attempts))
+
+	job.Status = "pending"
+	job.WorkerID = ""
+	job.RetryAt = time.Now().Add(delay)
+
+	log.Printf("Job %s failed attempt %d of %d, retrying in %s", job.ID, len(job.Attempts), job.MaxAttempts, delay)
+	s.enqueueAt(job, job.RetryAt)
+}
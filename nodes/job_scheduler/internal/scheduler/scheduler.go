@@ -1,6 +1,11 @@
 package scheduler
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
 	"sync"
 	"time"
 
@@ -11,13 +16,38 @@ import (
 // Scheduler manages job assignments to available workers
 type Scheduler struct {
 	jobQueue    *queue.JobQueue
-	workers     []*models.Worker
-	workerIndex int // For round-robin assignment
+	delayed     *queue.DelayedQueue // Pending jobs held back until a later time
 	mu          sync.Mutex
-	storage     Storage // Interface for persistence
+	storage     Storage // Interface for persistence, keeping index up to date
+	index       *jobIndex                   // Usage and placement of the jobs that have not finished
+	assignments map[string]chan models.Assignment // Instructions waiting to be picked up, keyed by worker ID
+	policies    map[string]Policy           // Placement policies by name
+	policy      string                      // Name of the policy used when a job does not pick one
+	timingOut   map[string]bool             // Running jobs past their deadline that workers were told to stop
+	lost        map[string][]string         // Jobs taken from reaped workers that may still run there, keyed by worker ID
+	wake        chan struct{}               // Signalled when jobs were queued or capacity was freed
+	ctx         context.Context             // Cancelled by Stop to end the background goroutines
+	cancel      context.CancelFunc
+	wg          sync.WaitGroup              // Background goroutines still running
+	waits       waitStats                   // How long placed jobs waited in the queue
+	draining    bool                        // No new jobs are placed while set
+	drained     map[string]chan struct{}    // Closed once a draining worker has no jobs left, keyed by worker ID
+	fairShare   bool                        // Share capacity between namespaces rather than placing strictly by priority
 }
 
-// Storage defines the interface for job and worker persistence
+// assignmentBuffer is how many instructions can wait for a single worker to pick them up
+const assignmentBuffer = 64
+
+var (
+	// ErrJobNotAssigned is returned when a worker reports on a job it does not own
+	ErrJobNotAssigned = errors.New("job is not assigned to this worker")
+	// ErrInvalidResult is returned when a worker reports an unknown final status
+	ErrInvalidResult = errors.New("result status must be completed, failed or timed_out")
+	// ErrJobFinished is returned when cancelling a job that has already finished
+	ErrJobFinished = errors.New("job has already finished")
+)
+
+// Storage defines the interface for job, worker, workflow, schedule and namespace persistence
 type Storage interface {
 	SaveJob(*models.Job) error
 	GetJob(id string) (*models.Job, error)
@@ -25,83 +55,691 @@ type Storage interface {
 	SaveWorker(*models.Worker) error
 	GetWorker(id string) (*models.Worker, error)
 	UpdateWorker(*models.Worker) error
+	DeleteWorker(id string) error
 	GetAvailableWorkers() ([]*models.Worker, error)
+	GetAllJobs() ([]*models.Job, error)
+	GetAllWorkers() ([]*models.Worker, error)
+	SaveWorkflow(*models.Workflow) error
+	GetWorkflow(id string) (*models.Workflow, error)
+	SaveSchedule(*models.Schedule) error
+	GetSchedule(id string) (*models.Schedule, error)
+	UpdateSchedule(*models.Schedule) error
+	DeleteSchedule(id string) error
+	GetAllSchedules() ([]*models.Schedule, error)
+	SaveNamespace(*models.Namespace) error
+	GetNamespace(name string) (*models.Namespace, error)
+	UpdateNamespace(*models.Namespace) error
+	DeleteNamespace(name string) error
+	GetAllNamespaces() ([]*models.Namespace, error)
 }
 
 // NewScheduler creates a new scheduler with the given queue and storage
 func NewScheduler(jobQueue *queue.JobQueue, storage Storage) *Scheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+	index := newJobIndex()
 	return &Scheduler{
 		jobQueue:    jobQueue,
-		workers:     make([]*models.Worker, 0),
-		workerIndex: 0,
-		storage:     storage,
+		delayed:     queue.NewDelayedQueue(),
+		storage:     indexedStorage{Storage: storage, index: index},
+		index:       index,
+		assignments: make(map[string]chan models.Assignment),
+		policies:    builtinPolicies(),
+		policy:      DefaultPolicy,
+		timingOut:   make(map[string]bool),
+		lost:        make(map[string][]string),
+		drained:     make(map[string]chan struct{}),
+		wake:        make(chan struct{}, 1),
+		ctx:         ctx,
+		cancel:      cancel,
+	}
+}
+
+// builtinPolicies creates one instance of every built-in policy, so stateful
+// policies like round-robin keep their position across jobs
+func builtinPolicies() map[string]Policy {
+	policies := make(map[string]Policy)
+	for _, name := range PolicyNames() {
+		policy, _ := NewPolicy(name)
+		policies[name] = policy
+	}
+	return policies
+}
+
+// SetDefaultPolicy selects the policy used for jobs that do not name one
+func (s *Scheduler) SetDefaultPolicy(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	
+	if _, exists := s.policies[name]; !exists {
+		return fmt.Errorf("unknown scheduling policy %q", name)
 	}
+	s.policy = name
+	return nil
+}
+
+// HasPolicy reports whether a policy with the given name is available
+func (s *Scheduler) HasPolicy(name string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	
+	_, exists := s.policies[name]
+	return exists
 }
 
-// RegisterWorker adds a new worker to the scheduler
+// RegisterWorker adds a new worker to the scheduler. A worker that names a
+// namespace only runs that namespace's jobs. The scheduler keeps its own
+// copy, so the caller may go on reading worker.
 func (s *Scheduler) RegisterWorker(worker *models.Worker) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	
-	s.workers = append(s.workers, worker)
-	return s.storage.SaveWorker(worker)
+	if worker.Namespace != "" {
+		if _, err := s.namespace(worker.Namespace); err != nil {
+			return err
+		}
+	}
+	
+	worker = worker.Clone()
+	s.assignmentQueue(worker.ID)
+	if err := s.storage.SaveWorker(worker); err != nil {
+		return err
+	}
+	s.notify()
+	return nil
 }
 
-// ScheduleJob assigns a job to a worker using round-robin
-func (s *Scheduler) ScheduleJob(job *models.Job) error {
+// assignmentQueue returns the pickup channel for a worker, creating it if needed.
+// Callers must hold s.mu.
+func (s *Scheduler) assignmentQueue(workerID string) chan models.Assignment {
+	queue, exists := s.assignments[workerID]
+	if !exists {
+		queue = make(chan models.Assignment, assignmentBuffer)
+		s.assignments[workerID] = queue
+	}
+	return queue
+}
+
+// Job returns a copy of a job, which callers may read without holding s.mu
+// while the scheduler goes on changing the job
+func (s *Scheduler) Job(id string) (*models.Job, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	
+	job, err := s.storage.GetJob(id)
+	if err != nil {
+		return nil, err
+	}
+	return job.Clone(), nil
+}
+
+// Jobs returns copies of every job
+func (s *Scheduler) Jobs() ([]*models.Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	
+	jobs, err := s.storage.GetAllJobs()
+	if err != nil {
+		return nil, err
+	}
+	for i, job := range jobs {
+		jobs[i] = job.Clone()
+	}
+	return jobs, nil
+}
+
+// ScheduleJob assigns a queued job to one of the workers with room for it,
+// chosen by the job's policy or the scheduler's default, and reports whether
+// it did. The job only leaves the queue once it is placed; otherwise it keeps
+// its place and the reason it is still waiting is recorded on it.
+func (s *Scheduler) ScheduleJob(job *models.Job) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	
+	// The job may have been cancelled since the queue was read
+	if job.Status != "pending" {
+		s.jobQueue.Remove(job.ID)
+		return false, nil
+	}
+	
+	// Nothing new starts while draining
+	if s.draining {
+		return false, nil
+	}
+	
+	// Too late to start it
+	if job.MissedStart(time.Now()) {
+		return false, s.expire(job)
+	}
+	
+	// Starting it must keep its namespace within its quota
+	reason, err := s.quotaReason(job)
+	if err != nil {
+		return false, err
+	}
+	if reason != "" {
+		return false, s.setReason(job, reason)
+	}
+	
 	availableWorkers, err := s.storage.GetAvailableWorkers()
 	if err != nil {
-		return err
+		return false, err
 	}
+	placed := s.placedJobs(job)
 	
-	if len(availableWorkers) == 0 {
-		// No workers available, keep job in queue
-		return nil
+	// Only workers the job may run on with enough free resources are
+	// candidates, narrowed down to those the job prefers most
+	candidates := make([]*models.Worker, 0, len(availableWorkers))
+	for _, worker := range availableWorkers {
+		if eligible(job, worker) && affinityAllows(job, worker, placed) && worker.Free().Covers(job.Resources) {
+			candidates = append(candidates, worker)
+		}
 	}
+	candidates = mostPreferred(job, leastTainted(job, candidates), placed)
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].ID < candidates[j].ID
+	})
 	
-	// Simple round-robin worker selection
-	worker := availableWorkers[s.workerIndex]
-	s.workerIndex = (s.workerIndex + 1) % len(availableWorkers)
+	policy, exists := s.policies[job.Policy]
+	if !exists {
+		policy = s.policies[s.policy]
+	}
+	
+	worker := policy.Select(job, candidates)
+	if worker == nil {
+		reason := unplacedReason(job, availableWorkers, placed)
+		if len(candidates) > 0 {
+			reason = fmt.Sprintf("queued: scheduling policy did not pick any of %d workers with room", len(candidates))
+		}
+		return false, s.setReason(job, reason)
+	}
 	
 	// Update job status
 	job.Status = "running"
-	err = s.storage.UpdateJob(job)
+	job.WorkerID = worker.ID
+	job.StartTime = time.Now() // Replaced by the worker's own start time in its result
+	job.Undelivered = true
+	
+	// Hand the job to the worker, unless its pickup queue is full
+	select {
+	case s.assignmentQueue(worker.ID) <- models.Assignment{Job: job}:
+	default:
+		job.Status = "pending"
+		job.WorkerID = ""
+		job.Undelivered = false
+		return false, s.setReason(job, fmt.Sprintf("queued: worker %s is not picking up jobs", worker.ID))
+	}
+	
+	s.jobQueue.Remove(job.ID)
+	if !job.QueuedAt.IsZero() {
+		job.WaitTime = time.Since(job.QueuedAt)
+		s.waits.record(job.WaitTime)
+	}
+	job.QueuedAt = time.Time{}
+	job.Reason = ""
+	
+	// Reserve the job's resources until it finishes
+	worker.Allocated = worker.Allocated.Add(job.Resources)
+	if err := s.storage.UpdateWorker(worker); err != nil {
+		return false, err
+	}
+	
+	// Jobs queued ahead of this one may have been waiting to join it
+	if job.Group != "" || len(job.Labels) > 0 {
+		s.notify()
+	}
+	
+	return true, s.storage.UpdateJob(job)
+}
+
+// releaseResources returns a job's reservation to the worker it ran on.
+// Callers must hold s.mu.
+func (s *Scheduler) releaseResources(job *models.Job) error {
+	worker, err := s.storage.GetWorker(job.WorkerID)
 	if err != nil {
 		return err
 	}
 	
-	// In a real system, you'd send the job to the worker here
-	// For now, we'll just simulate it
-	go s.simulateJobExecution(job, worker)
+	worker.Allocated = worker.Allocated.Sub(job.Resources)
+	return s.storage.UpdateWorker(worker)
+}
+
+// NextAssignment blocks until there is an instruction for the given worker or ctx is done.
+// It returns nil if nothing arrived before ctx expired.
+func (s *Scheduler) NextAssignment(ctx context.Context, workerID string) (*models.Assignment, error) {
+	if _, err := s.storage.GetWorker(workerID); err != nil {
+		return nil, err
+	}
 	
-	return nil
+	s.mu.Lock()
+	queue := s.assignmentQueue(workerID)
+	s.mu.Unlock()
+	
+	for {
+		select {
+		case assignment := <-queue:
+			// There is room in the pickup queue again
+			s.notify()
+			if assignment.Job != nil {
+				// Skip jobs that were cancelled before the worker picked them up
+				s.mu.Lock()
+				running := assignment.Job.Status == "running"
+				if running {
+					s.delivered(assignment.Job)
+				}
+				assignment.Job = assignment.Job.Clone()
+				s.mu.Unlock()
+				if !running {
+					continue
+				}
+			}
+			return &assignment, nil
+		case <-ctx.Done():
+			return nil, nil
+		case <-s.ctx.Done():
+			return nil, nil
+		}
+	}
+}
+
+// delivered records that a job's worker picked it up, so it is no longer
+// requeued on restart. The worker gets the job even if that cannot be
+// stored: running it twice after a restart beats never running it.
+// Callers must hold s.mu.
+func (s *Scheduler) delivered(job *models.Job) {
+	job.Undelivered = false
+	if err := s.storage.UpdateJob(job); err != nil {
+		log.Printf("Error recording delivery of job %s: %v", job.ID, err)
+	}
+}
+
+// unassign puts a running job its worker never picked up back to pending and
+// frees the resources reserved for it. Callers must hold s.mu.
+func (s *Scheduler) unassign(job *models.Job) error {
+	if err := s.releaseResources(job); err != nil {
+		return err
+	}
+	job.Status = "pending"
+	job.WorkerID = ""
+	job.Undelivered = false
+	return s.storage.UpdateJob(job)
+}
+
+// CancelJob stops a job. A pending or waiting job is taken out of the queue
+// and cancelled right away; a running job is marked cancelling and its worker is told to
+// terminate it, sending SIGKILL if it is still running after gracePeriod.
+// The job becomes cancelled once the worker reports back.
+func (s *Scheduler) CancelJob(jobID string, gracePeriod time.Duration) (*models.Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	
+	job, err := s.storage.GetJob(jobID)
+	if err != nil {
+		return nil, err
+	}
+	
+	if err := s.cancelJob(job, gracePeriod); err != nil {
+		return nil, err
+	}
+	return job.Clone(), nil
+}
+
+// cancelJob does the work of CancelJob. Callers must hold s.mu.
+func (s *Scheduler) cancelJob(job *models.Job, gracePeriod time.Duration) error {
+	switch job.Status {
+	case "waiting", "pending":
+		s.jobQueue.Remove(job.ID)
+		s.delayed.Remove(job.ID)
+		job.Status = "cancelled"
+		job.EndTime = time.Now()
+	case "running":
+		select {
+		case s.assignmentQueue(job.WorkerID) <- models.Assignment{Cancel: job.ID, GracePeriod: gracePeriod}:
+		default:
+			return fmt.Errorf("worker %s is not picking up instructions", job.WorkerID)
+		}
+		job.Status = "cancelling"
+	case "cancelling":
+		return nil
+	default:
+		return ErrJobFinished
+	}
+	
+	if err := s.storage.UpdateJob(job); err != nil {
+		return err
+	}
+	return s.releaseDependents(job)
+}
+
+// CompleteJob records the result a worker reported for one of its jobs
+func (s *Scheduler) CompleteJob(jobID string, result models.JobResult) error {
+	if result.Status != "completed" && result.Status != "failed" && result.Status != "timed_out" {
+		return ErrInvalidResult
+	}
+	
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	
+	job, err := s.storage.GetJob(jobID)
+	if err != nil {
+		return err
+	}
+	if job.WorkerID != result.WorkerID || (job.Status != "running" && job.Status != "cancelling") {
+		return ErrJobNotAssigned
+	}
+	
+	if err := s.releaseResources(job); err != nil {
+		return err
+	}
+	s.notify()
+	
+	// A job stopped for running past its deadline times out, and one stopped
+	// on request ends up cancelled, whatever their exit status
+	switch {
+	case s.timingOut[job.ID]:
+		job.Status = "timed_out"
+		delete(s.timingOut, job.ID)
+	case job.Status == "cancelling":
+		job.Status = "cancelled"
+	default:
+		job.Status = result.Status
+	}
+	job.ExitCode = result.ExitCode
+	job.StartTime = result.StartTime
+	job.EndTime = result.EndTime
+	job.Error = result.Error
+	job.Attempts = append(job.Attempts, models.Attempt{
+		WorkerID:  job.WorkerID,
+		Status:    job.Status,
+		ExitCode:  result.ExitCode,
+		StartTime: result.StartTime,
+		EndTime:   result.EndTime,
+		Error:     result.Error,
+	})
+	
+	if job.Status == "failed" && len(job.Attempts) < job.MaxAttempts {
+		s.retryLater(job)
+	}
+	if err := s.storage.UpdateJob(job); err != nil {
+		return err
+	}
+	if err := s.checkDrained(result.WorkerID); err != nil {
+		return err
+	}
+	return s.releaseDependents(job)
 }
 
-// simulateJobExecution simulates a job running on a worker
-func (s *Scheduler) simulateJobExecution(job *models.Job, worker *models.Worker) {
-	// Simulate job execution time
-	time.Sleep(5 * time.Second)
+// Recover rebuilds the job queue and index from storage after a restart.
+// Pending jobs are re-enqueued in the order they were submitted, along with
+// jobs that were handed to a worker that never picked them up: the pickup
+// queues do not survive the restart, so those would otherwise never run.
+func (s *Scheduler) Recover() error {
+	jobs, err := s.storage.GetAllJobs()
+	if err != nil {
+		return err
+	}
+	s.index.reset(jobs)
+	
+	s.mu.Lock()
+	for _, job := range jobs {
+		if job.Status == "running" && job.Undelivered {
+			log.Printf("Requeueing job %s, worker %s never picked it up", job.ID, job.WorkerID)
+			if err := s.unassign(job); err != nil {
+				s.mu.Unlock()
+				return err
+			}
+		}
+	}
+	s.mu.Unlock()
+	
+	pending := make([]*models.Job, 0)
+	for _, job := range jobs {
+		if job.Status != "pending" {
+			continue
+		}
+		// Jobs waiting to be retried or run later go back in once they are due
+		if at := job.NotBefore(); time.Now().Before(at) {
+			s.enqueueAt(job, at)
+			continue
+		}
+		pending = append(pending, job)
+	}
+	sort.Slice(pending, func(i, j int) bool {
+		return pending[i].SubmitTime.Before(pending[j].SubmitTime)
+	})
 	
-	// Update job status to completed
-	job.Status = "completed"
-	s.storage.UpdateJob(job)
+	for _, job := range pending {
+		s.ready(job)
+	}
+	
+	// Catch up on workflow jobs whose dependencies finished just before the
+	// server stopped
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, job := range jobs {
+		if err := s.releaseDependents(job); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-// Start begins the scheduling process
+// Start begins the scheduling process. The scheduling loop sleeps until jobs
+// are queued or capacity is freed, then places as many jobs as it can.
 func (s *Scheduler) Start() {
+	s.wg.Add(2)
 	go func() {
+		defer s.wg.Done()
+		s.promoteDelayed()
+	}()
+	go func() {
+		defer s.wg.Done()
 		for {
-			// Check for jobs in the queue
-			job := s.jobQueue.Dequeue()
-			if job != nil {
-				s.ScheduleJob(job)
+			s.schedulePending()
+			
+			select {
+			case <-s.wake:
+			case <-s.ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// Stop ends the scheduling loop and the other background goroutines, waiting
+// for them to finish their current pass until ctx is done. Jobs handed to
+// workers that have not picked them up yet go back to pending.
+func (s *Scheduler) Stop(ctx context.Context) error {
+	s.cancel()
+	
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+	
+	select {
+	case <-done:
+		return s.returnUndelivered()
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// returnUndelivered takes back the instructions no worker has picked up, so
+// they are not lost when the server stops: jobs go back to pending, to be
+// queued again on restart, and jobs being cancelled go back to running, to be
+// cancelled again if still needed
+func (s *Scheduler) returnUndelivered() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	
+	for _, queue := range s.assignments {
+		for len(queue) > 0 {
+			assignment := <-queue
+			if assignment.Cancel != "" {
+				job, err := s.storage.GetJob(assignment.Cancel)
+				if err != nil || job.Status != "cancelling" {
+					continue
+				}
+				job.Status = "running"
+				if err := s.storage.UpdateJob(job); err != nil {
+					return err
+				}
+				continue
 			}
 			
-			// Don't busy-wait
-			time.Sleep(1 * time.Second)
+			if assignment.Job.Status != "running" {
+				continue
+			}
+			if err := s.unassign(assignment.Job); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Drain stops placing queued jobs on workers while running jobs finish, so the
+// server can be stopped without interrupting work. Jobs are still accepted and
+// queued. Resume undoes it.
+func (s *Scheduler) Drain() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	
+	s.draining = true
+}
+
+// Resume starts placing queued jobs again after Drain
+func (s *Scheduler) Resume() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	
+	s.draining = false
+	s.notify()
+}
+
+// Draining reports whether the scheduler has been drained
+func (s *Scheduler) Draining() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	
+	return s.draining
+}
+
+// schedulePending makes one pass over the queue in priority order, trying to
+// place every job in it, or in fair-share order between namespaces if that
+// is enabled. Jobs that do not fit anywhere stay queued, so they cannot hold
+// up smaller jobs behind them, and are retried on the next wake-up rather
+// than in a busy loop.
+func (s *Scheduler) schedulePending() {
+	if s.Draining() {
+		return
+	}
+	s.mu.Lock()
+	fairShare := s.fairShare
+	s.mu.Unlock()
+	if fairShare {
+		s.scheduleFairly(s.jobQueue.Jobs())
+		return
+	}
+	for _, job := range s.jobQueue.Jobs() {
+		if s.ctx.Err() != nil {
+			return
+		}
+		if _, err := s.ScheduleJob(job); err != nil {
+			log.Printf("Error scheduling job %s: %v", job.ID, err)
+		}
+	}
+}
+
+// setReason records why a queued job has not been placed, saving the job
+// only if the reason changed. Callers must hold s.mu.
+func (s *Scheduler) setReason(job *models.Job, reason string) error {
+	if job.Reason == reason {
+		return nil
+	}
+	job.Reason = reason
+	return s.storage.UpdateJob(job)
+}
+
+// unplacedReason explains why none of the active workers has room for a job:
+// either none of them takes jobs from its namespace, matches its selector,
+// tolerates its taints or is large enough, making the job unschedulable for
+// now, or its affinity rules rule out the others or they are busy, and the
+// job is queued until that changes
+func unplacedReason(job *models.Job, workers []*models.Worker, placed map[string][]*models.Job) string {
+	if len(workers) == 0 {
+		return "unschedulable: no active workers"
+	}
+	pooled, matching, tolerated, allowed := 0, 0, 0, 0
+	for _, worker := range workers {
+		if !inPool(job, worker) {
+			continue
+		}
+		pooled++
+		if !job.Selector.Matches(worker.Labels) {
+			continue
+		}
+		matching++
+		if !eligible(job, worker) {
+			continue
+		}
+		tolerated++
+		if !affinityAllows(job, worker, placed) {
+			continue
+		}
+		allowed++
+		if worker.Resources.Covers(job.Resources) {
+			return fmt.Sprintf("queued: waiting for %d CPU cores and %d MB of memory to free up", job.Resources.CPUCores, job.Resources.MemoryMB)
+		}
+	}
+	if pooled == 0 {
+		return fmt.Sprintf("unschedulable: no active worker takes jobs from namespace %s", models.NamespaceOf(job.Namespace))
+	}
+	if matching == 0 {
+		return fmt.Sprintf("unschedulable: no active worker matches selector %s", job.Selector)
+	}
+	if tolerated == 0 {
+		return "unschedulable: every active worker it could run on has a NoSchedule taint the job does not tolerate"
+	}
+	if allowed == 0 {
+		return "queued: no active worker satisfies the job's affinity rules"
+	}
+	if len(job.Selector) > 0 {
+		return fmt.Sprintf("unschedulable: no active worker matching selector %s has %d CPU cores and %d MB of memory", job.Selector, job.Resources.CPUCores, job.Resources.MemoryMB)
+	}
+	return fmt.Sprintf("unschedulable: no active worker has %d CPU cores and %d MB of memory", job.Resources.CPUCores, job.Resources.MemoryMB)
+}
+
+// notify wakes the scheduling loop
+func (s *Scheduler) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// goEvery calls pass every interval in a background goroutine until the
+// scheduler is stopped. A non-positive interval cannot tick, so pass is then
+// never called.
+func (s *Scheduler) goEvery(interval time.Duration, pass func()) {
+	if interval <= 0 {
+		log.Printf("Not starting a background task with non-positive interval %s", interval)
+		return
+	}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		
+		for {
+			select {
+			case <-ticker.C:
+				pass()
+			case <-s.ctx.Done():
+				return
+			}
 		}
 	}()
-}
\ No newline at end of file
+}
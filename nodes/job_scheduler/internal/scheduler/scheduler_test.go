@@ -0,0 +1,359 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Shishir_grez/coltnode/nodes/job_scheduler/internal/queue"
+	"github.com/Shishir_grez/coltnode/nodes/job_scheduler/internal/storage"
+	"github.com/Shishir_grez/coltnode/nodes/job_scheduler/pkg/models"
+)
+
+// submitJob submits a job like the submit endpoint
+func submitJob(t *testing.T, s *Scheduler, job *models.Job) {
+	t.Helper()
+	if err := s.Submit(job); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// scheduleQueued makes one placement attempt for every queued job
+func scheduleQueued(t *testing.T, s *Scheduler) {
+	t.Helper()
+	for _, job := range s.jobQueue.Jobs() {
+		if _, err := s.ScheduleJob(job); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// nextAssignment returns the next instruction for a worker, or nil if there is none
+func nextAssignment(t *testing.T, s *Scheduler, workerID string) *models.Assignment {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assignment, err := s.NextAssignment(ctx, workerID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return assignment
+}
+
+// storedJob returns the scheduler's state of a job
+func storedJob(t *testing.T, s *Scheduler, id string) *models.Job {
+	t.Helper()
+	job, err := s.storage.GetJob(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return job
+}
+
+func TestCancelJob(t *testing.T) {
+	tests := []struct {
+		name       string
+		state      string // queued, running, cancelling or completed
+		wantStatus string
+		wantErr    error
+		wantCancel bool // Whether the worker is told to stop the job
+	}{
+		{name: "queued", state: "queued", wantStatus: "cancelled"},
+		{name: "running", state: "running", wantStatus: "cancelling", wantCancel: true},
+		{name: "already cancelling", state: "cancelling", wantStatus: "cancelling"},
+		{name: "already finished", state: "completed", wantErr: ErrJobFinished},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jobQueue := queue.NewJobQueue()
+			s := NewScheduler(jobQueue, storage.NewMemoryStorage())
+			worker := models.NewWorker("w1", 2, 1024)
+			if err := s.RegisterWorker(worker); err != nil {
+				t.Fatal(err)
+			}
+			job := models.NewJob("sleep", "sleep", []string{"60"})
+			job.Resources = models.Resources{CPUCores: 1}
+			submitJob(t, s, job)
+
+			if tt.state != "queued" {
+				scheduleQueued(t, s)
+				if assignment := nextAssignment(t, s, worker.ID); assignment == nil || assignment.Job == nil {
+					t.Fatalf("worker got %+v, want the job", assignment)
+				}
+			}
+			switch tt.state {
+			case "cancelling":
+				if _, err := s.CancelJob(job.ID, time.Second); err != nil {
+					t.Fatal(err)
+				}
+				nextAssignment(t, s, worker.ID)
+			case "completed":
+				if err := s.CompleteJob(job.ID, models.JobResult{WorkerID: worker.ID, Status: "completed"}); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			_, err := s.CancelJob(job.ID, 5*time.Second)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CancelJob() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if got := storedJob(t, s, job.ID); got.Status != tt.wantStatus {
+				t.Errorf("status = %q, want %q", got.Status, tt.wantStatus)
+			}
+			if jobQueue.Size() != 0 {
+				t.Errorf("queue holds %d jobs, want 0", jobQueue.Size())
+			}
+
+			assignment := nextAssignment(t, s, worker.ID)
+			if got := assignment != nil && assignment.Cancel == job.ID; got != tt.wantCancel {
+				t.Fatalf("worker got %+v, want a cancel instruction: %v", assignment, tt.wantCancel)
+			}
+			if !tt.wantCancel {
+				return
+			}
+			if assignment.GracePeriod != 5*time.Second {
+				t.Errorf("grace period = %s, want 5s", assignment.GracePeriod)
+			}
+
+			// The job ends up cancelled whatever the worker reports, and frees its resources
+			if err := s.CompleteJob(job.ID, models.JobResult{WorkerID: worker.ID, Status: "failed", ExitCode: -1}); err != nil {
+				t.Fatal(err)
+			}
+			if got := storedJob(t, s, job.ID); got.Status != "cancelled" {
+				t.Errorf("status after the worker reported = %q, want cancelled", got.Status)
+			}
+			w, err := s.storage.GetWorker(worker.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if w.Allocated.CPUCores != 0 {
+				t.Errorf("allocated CPU = %d after cancelling, want 0", w.Allocated.CPUCores)
+			}
+		})
+	}
+}
+
+// waitAssignment waits up to d for the next instruction for a worker
+func waitAssignment(t *testing.T, s *Scheduler, workerID string, d time.Duration) *models.Assignment {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), d)
+	defer cancel()
+	assignment, err := s.NextAssignment(ctx, workerID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return assignment
+}
+
+func TestSchedulingLoopWakes(t *testing.T) {
+	s := NewScheduler(queue.NewJobQueue(), storage.NewMemoryStorage())
+	s.Start()
+	t.Cleanup(func() {
+		if err := s.Stop(context.Background()); err != nil {
+			t.Error(err)
+		}
+	})
+
+	// Both jobs wait for a worker, which only fits one of them at a time
+	first := models.NewJob("first", "true", nil)
+	first.Resources = models.Resources{CPUCores: 1}
+	second := models.NewJob("second", "true", nil)
+	second.Resources = models.Resources{CPUCores: 1}
+	submitJob(t, s, first)
+	submitJob(t, s, second)
+
+	// Well below the old 1s polling interval
+	const wait = 300 * time.Millisecond
+
+	// Registering a worker wakes the loop
+	worker := models.NewWorker("w1", 1, 1024)
+	if err := s.RegisterWorker(worker); err != nil {
+		t.Fatal(err)
+	}
+	assignment := waitAssignment(t, s, worker.ID, wait)
+	if assignment == nil || assignment.Job == nil || assignment.Job.ID != first.ID {
+		t.Fatalf("worker got %+v, want the first job", assignment)
+	}
+	if assignment := nextAssignment(t, s, worker.ID); assignment != nil {
+		t.Fatalf("worker got %+v while full", assignment)
+	}
+
+	// Finishing the first job frees room for the second
+	if err := s.CompleteJob(first.ID, models.JobResult{WorkerID: worker.ID, Status: "completed"}); err != nil {
+		t.Fatal(err)
+	}
+	assignment = waitAssignment(t, s, worker.ID, wait)
+	if assignment == nil || assignment.Job == nil || assignment.Job.ID != second.ID {
+		t.Fatalf("worker got %+v, want the second job", assignment)
+	}
+
+	// So does submitting a job once the worker has room again
+	if err := s.CompleteJob(second.ID, models.JobResult{WorkerID: worker.ID, Status: "completed"}); err != nil {
+		t.Fatal(err)
+	}
+	third := models.NewJob("third", "true", nil)
+	submitJob(t, s, third)
+	assignment = waitAssignment(t, s, worker.ID, wait)
+	if assignment == nil || assignment.Job == nil || assignment.Job.ID != third.ID {
+		t.Fatalf("worker got %+v, want the third job", assignment)
+	}
+}
+
+func TestStopEndsBackgroundGoroutines(t *testing.T) {
+	s := NewScheduler(queue.NewJobQueue(), storage.NewMemoryStorage())
+	s.Start()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := s.Stop(ctx); err != nil {
+		t.Fatalf("Stop() error = %v, want the loops to end", err)
+	}
+}
+
+func TestUnplacedJobsStayQueued(t *testing.T) {
+	tests := []struct {
+		name              string
+		workerCPU         int // 0 registers no worker
+		busy              bool
+		wantReason        string
+		wantUnschedulable int
+	}{
+		{name: "no workers", wantReason: "unschedulable: no active workers", wantUnschedulable: 1},
+		{name: "worker too small", workerCPU: 1, wantReason: "unschedulable: no active worker has 2 CPU cores", wantUnschedulable: 1},
+		{name: "worker busy", workerCPU: 2, busy: true, wantReason: "queued: waiting for 2 CPU cores", wantUnschedulable: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jobQueue := queue.NewJobQueue()
+			s := NewScheduler(jobQueue, storage.NewMemoryStorage())
+			if tt.workerCPU > 0 {
+				if err := s.RegisterWorker(models.NewWorker("small", tt.workerCPU, 1024)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			submit := func() *models.Job {
+				job := models.NewJob("job", "true", nil)
+				job.Resources = models.Resources{CPUCores: 2}
+				if err := s.Submit(job); err != nil {
+					t.Fatal(err)
+				}
+				return job
+			}
+			if tt.busy {
+				submit()
+				s.schedulePending()
+			}
+
+			job := submit()
+			s.schedulePending()
+
+			got := storedJob(t, s, job.ID)
+			if got.Status != "pending" || !strings.HasPrefix(got.Reason, tt.wantReason) {
+				t.Errorf("job is %q with reason %q, want pending with reason starting %q", got.Status, got.Reason, tt.wantReason)
+			}
+			metrics := s.Metrics()
+			if metrics.Queued != 1 || metrics.Unschedulable != tt.wantUnschedulable {
+				t.Errorf("queued = %d, unschedulable = %d, want 1 and %d", metrics.Queued, metrics.Unschedulable, tt.wantUnschedulable)
+			}
+
+			// Capacity turning up places the job that kept its place in the queue
+			if err := s.RegisterWorker(models.NewWorker("large", 4, 1024)); err != nil {
+				t.Fatal(err)
+			}
+			s.schedulePending()
+
+			got = storedJob(t, s, job.ID)
+			if got.Status != "running" || got.Reason != "" {
+				t.Errorf("job is %q with reason %q once a worker has room, want running with no reason", got.Status, got.Reason)
+			}
+			if jobQueue.Size() != 0 {
+				t.Errorf("queue still holds %d jobs", jobQueue.Size())
+			}
+			if metrics := s.Metrics(); metrics.Placed == 0 || metrics.LastWaitSeconds <= 0 {
+				t.Errorf("placed = %d, last wait = %vs, want the placed job's wait recorded", metrics.Placed, metrics.LastWaitSeconds)
+			}
+		})
+	}
+}
+
+func TestStopReturnsUndelivered(t *testing.T) {
+	s := NewScheduler(queue.NewJobQueue(), storage.NewMemoryStorage())
+	worker := models.NewWorker("w1", 2, 1024)
+	if err := s.RegisterWorker(worker); err != nil {
+		t.Fatal(err)
+	}
+	picked := models.NewJob("picked", "sleep", []string{"60"})
+	picked.Resources = models.Resources{CPUCores: 1}
+	waiting := models.NewJob("waiting", "sleep", []string{"60"})
+	waiting.Resources = models.Resources{CPUCores: 1}
+	submitJob(t, s, picked)
+	submitJob(t, s, waiting)
+	scheduleQueued(t, s)
+
+	// The worker takes the first job and is told to cancel it, but neither
+	// the second job nor the cancellation reach it before the server stops
+	if assignment := nextAssignment(t, s, worker.ID); assignment == nil || assignment.Job == nil || assignment.Job.ID != picked.ID {
+		t.Fatalf("worker got %+v, want the first job", assignment)
+	}
+	if _, err := s.CancelJob(picked.ID, time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	s.Start()
+	if err := s.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := storedJob(t, s, waiting.ID); got.Status != "pending" || got.WorkerID != "" {
+		t.Errorf("undelivered job is %q on %q, want pending on no worker", got.Status, got.WorkerID)
+	}
+	if got := storedJob(t, s, picked.ID); got.Status != "running" {
+		t.Errorf("job with an undelivered cancellation is %q, want running", got.Status)
+	}
+	w, err := s.storage.GetWorker(worker.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w.Allocated.CPUCores != 1 {
+		t.Errorf("allocated CPU = %d, want only the picked up job's 1", w.Allocated.CPUCores)
+	}
+
+	// Workers polling a stopped scheduler get nothing
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if assignment, err := s.NextAssignment(ctx, worker.ID); err != nil || assignment != nil {
+		t.Errorf("NextAssignment() = %+v, %v after Stop, want nothing", assignment, err)
+	}
+	if ctx.Err() != nil {
+		t.Errorf("NextAssignment() waited for its own timeout after Stop")
+	}
+}
+
+func TestDrain(t *testing.T) {
+	s := NewScheduler(queue.NewJobQueue(), storage.NewMemoryStorage())
+	worker := models.NewWorker("w1", 2, 1024)
+	if err := s.RegisterWorker(worker); err != nil {
+		t.Fatal(err)
+	}
+	job := models.NewJob("job", "true", nil)
+
+	s.Drain()
+	submitJob(t, s, job)
+	s.schedulePending()
+	if got := storedJob(t, s, job.ID); got.Status != "pending" || !s.Draining() {
+		t.Fatalf("job is %q while draining, want pending", got.Status)
+	}
+
+	s.Resume()
+	s.schedulePending()
+	if got := storedJob(t, s, job.ID); got.Status != "running" || s.Draining() {
+		t.Errorf("job is %q after resuming, want running", got.Status)
+	}
+}
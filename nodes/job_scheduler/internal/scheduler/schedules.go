@@ -0,0 +1,237 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Shishir_grez/coltnode/nodes/job_scheduler/internal/cron"
+	"github.com/Shishir_grez/coltnode/nodes/job_scheduler/pkg/models"
+	"github.com/google/uuid"
+)
+
+// ErrInvalidSchedule is returned when a schedule's cron expression or overlap policy is not valid
+var ErrInvalidSchedule = errors.New("invalid schedule")
+
+// CreateSchedule validates and saves a new schedule, working out when it
+// first runs. The scheduler keeps its own copy, so the caller may go on
+// reading schedule.
+func (s *Scheduler) CreateSchedule(schedule *models.Schedule) error {
+	schedule.Namespace = models.NamespaceOf(schedule.Namespace)
+	if _, err := s.Namespace(schedule.Namespace); err != nil {
+		return err
+	}
+
+	expr, err := cron.Parse(schedule.Cron)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSchedule, err)
+	}
+	switch schedule.Overlap {
+	case "":
+		schedule.Overlap = models.OverlapAllow
+	case models.OverlapAllow, models.OverlapSkip, models.OverlapReplace:
+	default:
+		return fmt.Errorf("%w: overlap must be %s, %s or %s", ErrInvalidSchedule, models.OverlapAllow, models.OverlapSkip, models.OverlapReplace)
+	}
+
+	schedule.NextRun = expr.Next(time.Now())
+	if schedule.NextRun.IsZero() {
+		return fmt.Errorf("%w: cron expression %q never fires", ErrInvalidSchedule, schedule.Cron)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.storage.SaveSchedule(schedule.Clone())
+}
+
+// Schedule returns a copy of a schedule, which callers may read without
+// holding s.mu while the schedule runner goes on changing the schedule
+func (s *Scheduler) Schedule(id string) (*models.Schedule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	schedule, err := s.storage.GetSchedule(id)
+	if err != nil {
+		return nil, err
+	}
+	return schedule.Clone(), nil
+}
+
+// Schedules returns copies of every schedule
+func (s *Scheduler) Schedules() ([]*models.Schedule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	schedules, err := s.storage.GetAllSchedules()
+	if err != nil {
+		return nil, err
+	}
+	for i, schedule := range schedules {
+		schedules[i] = schedule.Clone()
+	}
+	return schedules, nil
+}
+
+// PauseSchedule stops a schedule from running until it is resumed
+func (s *Scheduler) PauseSchedule(scheduleID string) (*models.Schedule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	schedule, err := s.storage.GetSchedule(scheduleID)
+	if err != nil {
+		return nil, err
+	}
+
+	schedule.Paused = true
+	return schedule.Clone(), s.storage.UpdateSchedule(schedule)
+}
+
+// ResumeSchedule lets a paused schedule run again. Runs missed while it was
+// paused are not made up for; it next runs when its expression next fires.
+func (s *Scheduler) ResumeSchedule(scheduleID string) (*models.Schedule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	schedule, err := s.storage.GetSchedule(scheduleID)
+	if err != nil {
+		return nil, err
+	}
+	if !schedule.Paused {
+		return schedule.Clone(), nil
+	}
+
+	expr, err := cron.Parse(schedule.Cron)
+	if err != nil {
+		return nil, err
+	}
+	schedule.Paused = false
+	schedule.NextRun = expr.Next(time.Now())
+	return schedule.Clone(), s.storage.UpdateSchedule(schedule)
+}
+
+// DeleteSchedule removes a schedule. Jobs it already created are left alone.
+func (s *Scheduler) DeleteSchedule(scheduleID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.storage.DeleteSchedule(scheduleID)
+}
+
+// StartScheduleRunner checks every interval for schedules that are due and
+// queues a job for each of them. A job a schedule is replacing gets
+// gracePeriod to exit after SIGTERM.
+func (s *Scheduler) StartScheduleRunner(interval time.Duration, gracePeriod time.Duration) {
+	s.goEvery(interval, func() {
+		if err := s.runDueSchedules(gracePeriod); err != nil {
+			log.Printf("Error running schedules: %v", err)
+		}
+	})
+}
+
+// runDueSchedules runs a single pass of the schedule runner. A schedule that
+// was due several times since the last pass, for example because the server
+// was down, only runs once.
+func (s *Scheduler) runDueSchedules(gracePeriod time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	schedules, err := s.storage.GetAllSchedules()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, schedule := range schedules {
+		if schedule.Paused || now.Before(schedule.NextRun) {
+			continue
+		}
+
+		expr, err := cron.Parse(schedule.Cron)
+		if err != nil {
+			log.Printf("Schedule %s has an invalid cron expression: %v", schedule.ID, err)
+			continue
+		}
+		if err := s.runSchedule(schedule, now, gracePeriod); err != nil {
+			return err
+		}
+
+		schedule.LastRun = now
+		schedule.NextRun = expr.Next(now)
+		if err := s.storage.UpdateSchedule(schedule); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runSchedule queues a job for a due schedule, subject to its overlap policy.
+// Callers must hold s.mu.
+func (s *Scheduler) runSchedule(schedule *models.Schedule, now time.Time, gracePeriod time.Duration) error {
+	if previous, err := s.storage.GetJob(schedule.LastJobID); err == nil && !previous.Finished() {
+		switch schedule.Overlap {
+		case models.OverlapSkip:
+			log.Printf("Schedule %s skipped a run, job %s is still %s", schedule.ID, previous.ID, previous.Status)
+			return nil
+		case models.OverlapReplace:
+			log.Printf("Schedule %s is replacing job %s", schedule.ID, previous.ID)
+			if err := s.cancelJob(previous, gracePeriod); err != nil {
+				log.Printf("Error cancelling job %s: %v", previous.ID, err)
+			}
+		}
+	}
+
+	job := newScheduledJob(schedule, now)
+	if err := s.admit([]*models.Job{job}); err != nil {
+		if errors.Is(err, ErrQuotaExceeded) {
+			log.Printf("Schedule %s skipped a run: %v", schedule.ID, err)
+			return nil
+		}
+		return err
+	}
+	if err := s.storage.SaveJob(job); err != nil {
+		return err
+	}
+	s.enqueue(job)
+
+	log.Printf("Schedule %s queued job %s", schedule.ID, job.ID)
+	schedule.LastJobID = job.ID
+	return nil
+}
+
+// newScheduledJob creates a fresh job from a schedule's template. The whole
+// template is copied, so every job setting carries over to the runs, and only
+// the state of a single run is reset. Delays and deadlines in the template are
+// kept relative to the time the job is submitted.
+func newScheduledJob(schedule *models.Schedule, now time.Time) *models.Job {
+	template := &schedule.Template
+
+	job := template.Clone()
+	job.ID = uuid.New().String()
+	job.Status = "pending"
+	job.SubmitTime = now
+	job.WorkerID = ""
+	job.Undelivered = false
+	job.ExitCode = 0
+	job.StartTime = time.Time{}
+	job.EndTime = time.Time{}
+	job.Error = ""
+	job.Attempts = nil
+	job.RetryAt = time.Time{}
+	job.QueuedAt = time.Time{}
+	job.WaitTime = 0
+	job.Reason = ""
+	job.ScheduleID = schedule.ID
+	job.Namespace = schedule.Namespace
+	if !template.StartBy.IsZero() {
+		job.StartBy = now.Add(template.StartBy.Sub(template.SubmitTime))
+	}
+	if !template.FinishBy.IsZero() {
+		job.FinishBy = now.Add(template.FinishBy.Sub(template.SubmitTime))
+	}
+	if !template.RunAt.IsZero() {
+		job.RunAt = now.Add(template.RunAt.Sub(template.SubmitTime))
+	}
+	return job
+}
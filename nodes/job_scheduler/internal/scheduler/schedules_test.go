@@ -0,0 +1,114 @@
+package scheduler
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Shishir_grez/coltnode/nodes/job_scheduler/pkg/models"
+)
+
+func TestNewScheduledJob(t *testing.T) {
+	created := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := created.Add(24 * time.Hour)
+
+	template := models.Job{
+		ID:          "template",
+		Name:        "backup",
+		Owner:       "token-1",
+		Command:     "backup.sh",
+		Args:        []string{"--full"},
+		Status:      "completed",
+		SubmitTime:  created,
+		Resources:   models.Resources{CPUCores: 2, MemoryMB: 512},
+		Policy:      "spread",
+		Selector:    models.Selector{{Key: "disk", Operator: models.SelectorIn, Values: []string{"ssd"}}},
+		Preferences: []models.Preference{{Weight: 10}},
+		Tolerations: []models.Toleration{{Key: "dedicated", Value: "ops"}},
+		Labels:      map[string]string{"app": "backup"},
+		Group:       "backups",
+		Affinity:    []models.AffinityRule{{Type: models.AffinitySameWorker, Group: "db", Hard: true}},
+		Priority:    8,
+		MaxAttempts: 3,
+		Backoff:     models.Backoff{Strategy: models.BackoffExponential, Delay: time.Second},
+		Timeout:     time.Hour,
+		StartBy:     created.Add(time.Minute),
+		FinishBy:    created.Add(2 * time.Hour),
+		RunAt:       created.Add(30 * time.Second),
+		// Per-run state that must not carry over
+		WorkerID:    "w1",
+		Undelivered: true,
+		ExitCode:    1,
+		StartTime:   created,
+		EndTime:     created,
+		Error:       "boom",
+		Attempts:    []models.Attempt{{WorkerID: "w1"}},
+		RetryAt:     created,
+		QueuedAt:    created,
+		WaitTime:    time.Minute,
+		Reason:      "queued",
+	}
+	schedule := &models.Schedule{ID: "s1", Namespace: "ops", Template: template}
+
+	job := newScheduledJob(schedule, now)
+
+	tests := []struct {
+		field string
+		got   any
+		want  any
+	}{
+		{"Name", job.Name, template.Name},
+		{"Owner", job.Owner, template.Owner},
+		{"Command", job.Command, template.Command},
+		{"Args", job.Args, template.Args},
+		{"Resources", job.Resources, template.Resources},
+		{"Policy", job.Policy, template.Policy},
+		{"Selector", job.Selector, template.Selector},
+		{"Preferences", job.Preferences, template.Preferences},
+		{"Tolerations", job.Tolerations, template.Tolerations},
+		{"Labels", job.Labels, template.Labels},
+		{"Group", job.Group, template.Group},
+		{"Affinity", job.Affinity, template.Affinity},
+		{"Priority", job.Priority, template.Priority},
+		{"MaxAttempts", job.MaxAttempts, template.MaxAttempts},
+		{"Backoff", job.Backoff, template.Backoff},
+		{"Timeout", job.Timeout, template.Timeout},
+		{"Namespace", job.Namespace, "ops"},
+		{"ScheduleID", job.ScheduleID, "s1"},
+		{"Status", job.Status, "pending"},
+		{"SubmitTime", job.SubmitTime, now},
+		{"StartBy", job.StartBy, now.Add(time.Minute)},
+		{"FinishBy", job.FinishBy, now.Add(2 * time.Hour)},
+		{"RunAt", job.RunAt, now.Add(30 * time.Second)},
+		{"WorkerID", job.WorkerID, ""},
+		{"Undelivered", job.Undelivered, false},
+		{"ExitCode", job.ExitCode, 0},
+		{"StartTime", job.StartTime, time.Time{}},
+		{"EndTime", job.EndTime, time.Time{}},
+		{"Error", job.Error, ""},
+		{"Attempts", len(job.Attempts), 0},
+		{"RetryAt", job.RetryAt, time.Time{}},
+		{"QueuedAt", job.QueuedAt, time.Time{}},
+		{"WaitTime", job.WaitTime, time.Duration(0)},
+		{"Reason", job.Reason, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			if !reflect.DeepEqual(tt.got, tt.want) {
+				t.Errorf("%s = %v, want %v", tt.field, tt.got, tt.want)
+			}
+		})
+	}
+
+	if job.ID == "" || job.ID == template.ID {
+		t.Errorf("ID = %q, want a new one", job.ID)
+	}
+
+	// Runs must not share slices or maps with the template
+	job.Args[0] = "--incremental"
+	job.Labels["app"] = "changed"
+	job.Selector[0].Values[0] = "hdd"
+	if schedule.Template.Args[0] != "--full" || schedule.Template.Labels["app"] != "backup" || schedule.Template.Selector[0].Values[0] != "ssd" {
+		t.Errorf("changing the job changed the schedule's template")
+	}
+}
@@ -0,0 +1,56 @@
+package scheduler
+
+import "github.com/Shishir_grez/coltnode/nodes/job_scheduler/pkg/models"
+
+// eligible reports whether a job may be placed on a worker at all: the worker
+// must take jobs from the job's namespace, match the job's selector and have
+// no NoSchedule taint it does not tolerate
+func eligible(job *models.Job, worker *models.Worker) bool {
+	return inPool(job, worker) && job.Selector.Matches(worker.Labels) && len(models.Untolerated(worker.Taints, job.Tolerations, models.TaintNoSchedule)) == 0
+}
+
+// leastTainted drops candidates with PreferNoSchedule taints the job does not
+// tolerate, unless that would leave none
+func leastTainted(job *models.Job, candidates []*models.Worker) []*models.Worker {
+	untainted := make([]*models.Worker, 0, len(candidates))
+	for _, worker := range candidates {
+		if len(models.Untolerated(worker.Taints, job.Tolerations, models.TaintPreferNoSchedule)) == 0 {
+			untainted = append(untainted, worker)
+		}
+	}
+	if len(untainted) == 0 {
+		return candidates
+	}
+	return untainted
+}
+
+// mostPreferred keeps the candidates with the highest preference score for a
+// job, the score being the total weight of its label preferences a worker
+// matches and of its soft affinity rules that hold there. The job's policy
+// then picks among them as usual. Jobs without preferences or soft affinity
+// rules keep every candidate.
+func mostPreferred(job *models.Job, candidates []*models.Worker, placed map[string][]*models.Job) []*models.Worker {
+	if (len(job.Preferences) == 0 && len(job.Affinity) == 0) || len(candidates) == 0 {
+		return candidates
+	}
+
+	best := make([]*models.Worker, 0, len(candidates))
+	bestScore := 0
+	for i, worker := range candidates {
+		score := affinityScore(job, worker, placed)
+		for _, preference := range job.Preferences {
+			if preference.Selector.Matches(worker.Labels) {
+				score += preference.Weight
+			}
+		}
+
+		switch {
+		case i == 0 || score > bestScore:
+			best = append(best[:0], worker)
+			bestScore = score
+		case score == bestScore:
+			best = append(best, worker)
+		}
+	}
+	return best
+}
@@ -0,0 +1,71 @@
+package scheduler
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Shishir_grez/coltnode/nodes/job_scheduler/internal/queue"
+	"github.com/Shishir_grez/coltnode/nodes/job_scheduler/internal/storage"
+	"github.com/Shishir_grez/coltnode/nodes/job_scheduler/pkg/models"
+)
+
+// registerNamed registers a worker whose ID is its name, so tests can tell
+// where jobs went
+func registerNamed(t *testing.T, s *Scheduler, name string) {
+	t.Helper()
+	worker := models.NewWorker(name, 2, 1024)
+	worker.ID = name
+	if err := s.RegisterWorker(worker); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTaintPlacement(t *testing.T) {
+	gpu := models.Taint{Key: "gpu", Value: "true", Effect: models.TaintNoSchedule}
+	spot := models.Taint{Key: "spot", Effect: models.TaintPreferNoSchedule}
+
+	tests := []struct {
+		name        string
+		taint       models.Taint // Put on worker a
+		clean       bool         // Also register worker b without taints
+		tolerations []models.Toleration
+		wantWorker  string // Empty if the job stays queued
+		wantReason  string
+	}{
+		{name: "NoSchedule blocks a job without a toleration", taint: gpu, wantReason: "unschedulable: every active worker it could run on has a NoSchedule taint"},
+		{name: "NoSchedule sends the job elsewhere", taint: gpu, clean: true, wantWorker: "b"},
+		{name: "NoSchedule tolerated", taint: gpu, tolerations: []models.Toleration{{Key: "gpu", Operator: models.TolerationEqual, Value: "true"}}, wantWorker: "a"},
+		{name: "NoSchedule tolerated with another value", taint: gpu, tolerations: []models.Toleration{{Key: "gpu", Operator: models.TolerationEqual, Value: "false"}}, wantReason: "unschedulable"},
+		{name: "PreferNoSchedule avoided when another worker has room", taint: spot, clean: true, wantWorker: "b"},
+		{name: "PreferNoSchedule used when it is the only worker", taint: spot, wantWorker: "a"},
+		{name: "PreferNoSchedule tolerated", taint: spot, clean: true, tolerations: []models.Toleration{{Key: "spot", Operator: models.TolerationExists}}, wantWorker: "a"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewScheduler(queue.NewJobQueue(), storage.NewMemoryStorage())
+			registerNamed(t, s, "a")
+			if _, err := s.TaintWorker("a", tt.taint); err != nil {
+				t.Fatal(err)
+			}
+			if tt.clean {
+				registerNamed(t, s, "b")
+			}
+
+			// Round robin would otherwise start at a
+			job := models.NewJob("job", "true", nil)
+			job.Policy = PolicyRoundRobin
+			job.Tolerations = tt.tolerations
+			submitJob(t, s, job)
+			scheduleQueued(t, s)
+
+			got := storedJob(t, s, job.ID)
+			if got.WorkerID != tt.wantWorker {
+				t.Errorf("job placed on %q, want %q", got.WorkerID, tt.wantWorker)
+			}
+			if tt.wantWorker == "" && (got.Status != "pending" || !strings.HasPrefix(got.Reason, tt.wantReason)) {
+				t.Errorf("job is %q with reason %q, want pending with reason starting %q", got.Status, got.Reason, tt.wantReason)
+			}
+		})
+	}
+}
@@ -0,0 +1,228 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"slices"
+
+	"github.com/Shishir_grez/coltnode/nodes/job_scheduler/pkg/models"
+)
+
+// ErrWorkerBusy is returned when removing a worker that still has jobs running
+var ErrWorkerBusy = errors.New("worker still has jobs running")
+
+// Worker returns a copy of a worker, which callers may read without holding
+// s.mu while the scheduler goes on changing the worker
+func (s *Scheduler) Worker(id string) (*models.Worker, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	worker, err := s.storage.GetWorker(id)
+	if err != nil {
+		return nil, err
+	}
+	return worker.Clone(), nil
+}
+
+// Workers returns copies of every worker, whatever its status
+func (s *Scheduler) Workers() ([]*models.Worker, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	workers, err := s.storage.GetAllWorkers()
+	if err != nil {
+		return nil, err
+	}
+	for i, worker := range workers {
+		workers[i] = worker.Clone()
+	}
+	return workers, nil
+}
+
+// CordonWorker takes a worker out of rotation: no new jobs are placed on it,
+// but jobs it is already running carry on. It stays cordoned if it goes
+// offline and comes back, until UncordonWorker is called.
+func (s *Scheduler) CordonWorker(workerID string) (*models.Worker, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	worker, err := s.storage.GetWorker(workerID)
+	if err != nil {
+		return nil, err
+	}
+
+	worker.Cordoned = true
+	if worker.Status == "active" {
+		log.Printf("Worker %s cordoned", worker.ID)
+		worker.Status = "cordoned"
+	}
+	return worker.Clone(), s.storage.UpdateWorker(worker)
+}
+
+// UncordonWorker puts a cordoned or draining worker back in rotation
+func (s *Scheduler) UncordonWorker(workerID string) (*models.Worker, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	worker, err := s.storage.GetWorker(workerID)
+	if err != nil {
+		return nil, err
+	}
+
+	worker.Cordoned = false
+	if worker.Status == "cordoned" || worker.Status == "draining" {
+		log.Printf("Worker %s back in rotation", worker.ID)
+		worker.Status = "active"
+		s.notify()
+	}
+	s.finishDrain(worker.ID)
+	return worker.Clone(), s.storage.UpdateWorker(worker)
+}
+
+// DrainWorker cordons a worker and marks it draining until the jobs it is
+// running have finished, after which it is cordoned. Use WaitWorkerDrained
+// to block until then.
+func (s *Scheduler) DrainWorker(workerID string) (*models.Worker, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	worker, err := s.storage.GetWorker(workerID)
+	if err != nil {
+		return nil, err
+	}
+
+	worker.Cordoned = true
+	if worker.Status == "active" || worker.Status == "cordoned" {
+		log.Printf("Draining worker %s", worker.ID)
+		worker.Status = "draining"
+		if _, exists := s.drained[worker.ID]; !exists {
+			s.drained[worker.ID] = make(chan struct{})
+		}
+	}
+	if err := s.storage.UpdateWorker(worker); err != nil {
+		return nil, err
+	}
+	if err := s.checkDrained(worker.ID); err != nil {
+		return nil, err
+	}
+	return worker.Clone(), nil
+}
+
+// WaitWorkerDrained blocks until a draining worker has no jobs left running,
+// it is uncordoned or removed, or ctx is done. It returns right away if the
+// worker is not draining.
+func (s *Scheduler) WaitWorkerDrained(ctx context.Context, workerID string) error {
+	s.mu.Lock()
+	done, draining := s.drained[workerID]
+	s.mu.Unlock()
+	if !draining {
+		return nil
+	}
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// TaintWorker adds a taint to a worker, replacing any taint with the same key
+// and effect. Jobs already placed on it are left alone.
+func (s *Scheduler) TaintWorker(workerID string, taint models.Taint) (*models.Worker, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	worker, err := s.storage.GetWorker(workerID)
+	if err != nil {
+		return nil, err
+	}
+
+	worker.Taints = slices.DeleteFunc(worker.Taints, func(existing models.Taint) bool {
+		return existing.Key == taint.Key && existing.Effect == taint.Effect
+	})
+	worker.Taints = append(worker.Taints, taint)
+	log.Printf("Worker %s tainted with %s", worker.ID, taint)
+	return worker.Clone(), s.storage.UpdateWorker(worker)
+}
+
+// UntaintWorker removes every taint with the given key from a worker
+func (s *Scheduler) UntaintWorker(workerID string, key string) (*models.Worker, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	worker, err := s.storage.GetWorker(workerID)
+	if err != nil {
+		return nil, err
+	}
+
+	worker.Taints = slices.DeleteFunc(worker.Taints, func(taint models.Taint) bool {
+		return taint.Key == key
+	})
+	s.notify()
+	return worker.Clone(), s.storage.UpdateWorker(worker)
+}
+
+// RemoveWorker deletes a worker. It fails with ErrWorkerBusy if the worker
+// still has jobs running, unless force is set, in which case they go back
+// in the queue as if the worker had died.
+func (s *Scheduler) RemoveWorker(workerID string, force bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.storage.GetWorker(workerID); err != nil {
+		return err
+	}
+
+	if s.runningJobs(workerID) > 0 {
+		if !force {
+			return ErrWorkerBusy
+		}
+		if err := s.requeueWorkerJobs(workerID, false); err != nil {
+			return err
+		}
+	}
+
+	if err := s.storage.DeleteWorker(workerID); err != nil {
+		return err
+	}
+	log.Printf("Worker %s removed", workerID)
+	delete(s.assignments, workerID)
+	delete(s.lost, workerID)
+	s.finishDrain(workerID)
+	return nil
+}
+
+// checkDrained cordons a draining worker once it has no jobs left running.
+// Callers must hold s.mu.
+func (s *Scheduler) checkDrained(workerID string) error {
+	worker, err := s.storage.GetWorker(workerID)
+	if err != nil || worker.Status != "draining" {
+		return nil
+	}
+
+	if s.runningJobs(workerID) > 0 {
+		return nil
+	}
+
+	log.Printf("Worker %s drained", worker.ID)
+	worker.Status = "cordoned"
+	s.finishDrain(worker.ID)
+	return s.storage.UpdateWorker(worker)
+}
+
+// finishDrain releases everyone waiting for a worker to drain.
+// Callers must hold s.mu.
+func (s *Scheduler) finishDrain(workerID string) {
+	if done, exists := s.drained[workerID]; exists {
+		close(done)
+		delete(s.drained, workerID)
+	}
+}
+
+// runningJobs counts the jobs assigned to a worker that have not finished,
+// including those it has not picked up yet. Callers must hold s.mu.
+func (s *Scheduler) runningJobs(workerID string) int {
+	return len(s.index.placedOn(workerID))
+}
@@ -0,0 +1,163 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Shishir_grez/coltnode/nodes/job_scheduler/internal/queue"
+	"github.com/Shishir_grez/coltnode/nodes/job_scheduler/internal/storage"
+	"github.com/Shishir_grez/coltnode/nodes/job_scheduler/pkg/models"
+)
+
+// runJobOn places a job on a worker and has the worker pick it up
+func runJobOn(t *testing.T, s *Scheduler, workerID string) *models.Job {
+	t.Helper()
+	job := models.NewJob("sleep", "sleep", []string{"60"})
+	job.Resources = models.Resources{CPUCores: 1}
+	submitJob(t, s, job)
+	scheduleQueued(t, s)
+	if assignment := nextAssignment(t, s, workerID); assignment == nil || assignment.Job == nil || assignment.Job.ID != job.ID {
+		t.Fatalf("worker got %+v, want the job", assignment)
+	}
+	return job
+}
+
+func TestCordonWorker(t *testing.T) {
+	s := NewScheduler(queue.NewJobQueue(), storage.NewMemoryStorage())
+	worker := models.NewWorker("w1", 2, 1024)
+	if err := s.RegisterWorker(worker); err != nil {
+		t.Fatal(err)
+	}
+	running := runJobOn(t, s, worker.ID)
+
+	if _, err := s.CordonWorker(worker.ID); err != nil {
+		t.Fatal(err)
+	}
+	job := models.NewJob("job", "true", nil)
+	submitJob(t, s, job)
+	scheduleQueued(t, s)
+	if got := storedJob(t, s, job.ID); got.Status != "pending" {
+		t.Errorf("job is %q with only a cordoned worker, want pending", got.Status)
+	}
+	if got := storedJob(t, s, running.ID); got.Status != "running" {
+		t.Errorf("job already running is %q after cordoning, want running", got.Status)
+	}
+
+	// Coming back from offline keeps the worker cordoned
+	storedWorker, err := s.storage.GetWorker(worker.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	storedWorker.Status = "offline"
+	if w, err := s.Heartbeat(worker.ID); err != nil || w.Status != "cordoned" {
+		t.Fatalf("Heartbeat() = %+v, %v, want the worker cordoned", w, err)
+	}
+
+	if _, err := s.UncordonWorker(worker.ID); err != nil {
+		t.Fatal(err)
+	}
+	scheduleQueued(t, s)
+	if got := storedJob(t, s, job.ID); got.Status != "running" || got.WorkerID != worker.ID {
+		t.Errorf("job is %q on %q after uncordoning, want running on w1", got.Status, got.WorkerID)
+	}
+}
+
+func TestDrainWorker(t *testing.T) {
+	s := NewScheduler(queue.NewJobQueue(), storage.NewMemoryStorage())
+	worker := models.NewWorker("w1", 2, 1024)
+	if err := s.RegisterWorker(worker); err != nil {
+		t.Fatal(err)
+	}
+	job := runJobOn(t, s, worker.ID)
+
+	if w, err := s.DrainWorker(worker.ID); err != nil || w.Status != "draining" {
+		t.Fatalf("DrainWorker() = %+v, %v, want the worker draining", w, err)
+	}
+
+	// Not drained while the job runs
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := s.WaitWorkerDrained(ctx, worker.ID); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("WaitWorkerDrained() with a job running = %v, want it to wait", err)
+	}
+
+	drained := make(chan error, 1)
+	go func() {
+		drained <- s.WaitWorkerDrained(context.Background(), worker.ID)
+	}()
+	if err := s.CompleteJob(job.ID, models.JobResult{WorkerID: worker.ID, Status: "completed"}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-drained:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("WaitWorkerDrained() still waiting after the last job finished")
+	}
+
+	w, err := s.storage.GetWorker(worker.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w.Status != "cordoned" {
+		t.Errorf("worker is %q once drained, want cordoned", w.Status)
+	}
+	// An idle worker drains right away
+	if err := s.WaitWorkerDrained(context.Background(), worker.ID); err != nil {
+		t.Errorf("WaitWorkerDrained() on a drained worker = %v", err)
+	}
+}
+
+func TestRemoveWorker(t *testing.T) {
+	tests := []struct {
+		name        string
+		running     bool
+		force       bool
+		wantErr     error
+		wantRemoved bool
+	}{
+		{name: "idle", wantRemoved: true},
+		{name: "busy", running: true, wantErr: ErrWorkerBusy},
+		{name: "busy forced", running: true, force: true, wantRemoved: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jobQueue := queue.NewJobQueue()
+			s := NewScheduler(jobQueue, storage.NewMemoryStorage())
+			worker := models.NewWorker("w1", 2, 1024)
+			if err := s.RegisterWorker(worker); err != nil {
+				t.Fatal(err)
+			}
+			var job *models.Job
+			if tt.running {
+				job = runJobOn(t, s, worker.ID)
+			}
+
+			if err := s.RemoveWorker(worker.ID, tt.force); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("RemoveWorker() error = %v, want %v", err, tt.wantErr)
+			}
+			_, err := s.storage.GetWorker(worker.ID)
+			if removed := err != nil; removed != tt.wantRemoved {
+				t.Errorf("worker removed = %v, want %v", removed, tt.wantRemoved)
+			}
+			if job == nil {
+				return
+			}
+
+			got := storedJob(t, s, job.ID)
+			if tt.force {
+				// The job goes back in the queue without losing an attempt
+				if got.Status != "pending" || got.WorkerID != "" || jobQueue.Size() != 1 {
+					t.Errorf("job is %q on %q with %d queued, want pending and queued", got.Status, got.WorkerID, jobQueue.Size())
+				}
+			} else if got.Status != "running" {
+				t.Errorf("job is %q after a refused removal, want running", got.Status)
+			}
+		})
+	}
+}
@@ -0,0 +1,177 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/Shishir_grez/coltnode/nodes/job_scheduler/pkg/models"
+)
+
+// ErrInvalidWorkflow is returned when a workflow's dependencies do not form a DAG
+var ErrInvalidWorkflow = errors.New("invalid workflow")
+
+// SubmitWorkflow saves a workflow and its jobs. Jobs without dependencies are
+// queued right away; the others wait until every job they depend on completed.
+// DependsOn must hold the IDs of other jobs in jobs. The jobs are put in the
+// workflow's namespace, which must have room in its quota for all of them.
+func (s *Scheduler) SubmitWorkflow(workflow *models.Workflow, jobs []*models.Job) error {
+	if err := validateDAG(jobs); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	workflow.Namespace = models.NamespaceOf(workflow.Namespace)
+	for _, job := range jobs {
+		job.Namespace = workflow.Namespace
+	}
+	if err := s.admit(jobs); err != nil {
+		return err
+	}
+
+	// The scheduler keeps its own copies of the jobs
+	workflow.JobIDs = make([]string, 0, len(jobs))
+	saved := make([]*models.Job, 0, len(jobs))
+	for _, job := range jobs {
+		job.WorkflowID = workflow.ID
+		if len(job.DependsOn) > 0 {
+			job.Status = "waiting"
+		}
+		job = job.Clone()
+		if err := s.storage.SaveJob(job); err != nil {
+			return err
+		}
+		workflow.JobIDs = append(workflow.JobIDs, job.ID)
+		saved = append(saved, job)
+	}
+	if err := s.storage.SaveWorkflow(workflow); err != nil {
+		return err
+	}
+
+	for _, job := range saved {
+		if job.Status == "pending" {
+			s.enqueue(job)
+		}
+	}
+	return nil
+}
+
+// validateDAG checks that every dependency is another job in jobs and that
+// there are no cycles, by repeatedly removing jobs with no unmet dependencies
+func validateDAG(jobs []*models.Job) error {
+	byID := make(map[string]*models.Job, len(jobs))
+	for _, job := range jobs {
+		byID[job.ID] = job
+	}
+
+	unmet := make(map[string]int, len(jobs))
+	children := make(map[string][]string)
+	for _, job := range jobs {
+		for _, parentID := range job.DependsOn {
+			if parentID == job.ID {
+				return fmt.Errorf("%w: job %q depends on itself", ErrInvalidWorkflow, job.Name)
+			}
+			if _, exists := byID[parentID]; !exists {
+				return fmt.Errorf("%w: job %q depends on unknown job %s", ErrInvalidWorkflow, job.Name, parentID)
+			}
+			unmet[job.ID]++
+			children[parentID] = append(children[parentID], job.ID)
+		}
+	}
+
+	ready := make([]string, 0, len(jobs))
+	for _, job := range jobs {
+		if unmet[job.ID] == 0 {
+			ready = append(ready, job.ID)
+		}
+	}
+	for i := 0; i < len(ready); i++ {
+		for _, childID := range children[ready[i]] {
+			unmet[childID]--
+			if unmet[childID] == 0 {
+				ready = append(ready, childID)
+			}
+		}
+	}
+
+	if len(ready) < len(jobs) {
+		for _, job := range jobs {
+			if unmet[job.ID] > 0 {
+				return fmt.Errorf("%w: dependency cycle through job %q", ErrInvalidWorkflow, job.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// releaseDependents reacts to a workflow job reaching a final status. Waiting
+// jobs that depend on it are queued once all their dependencies completed, or
+// skipped along with their own dependents if it did not complete.
+// Callers must hold s.mu.
+func (s *Scheduler) releaseDependents(job *models.Job) error {
+	if job.WorkflowID == "" || !job.Finished() {
+		return nil
+	}
+
+	workflow, err := s.storage.GetWorkflow(job.WorkflowID)
+	if err != nil {
+		return err
+	}
+
+	for _, id := range workflow.JobIDs {
+		child, err := s.storage.GetJob(id)
+		if err != nil {
+			return err
+		}
+		if child.Status != "waiting" || !slices.Contains(child.DependsOn, job.ID) {
+			continue
+		}
+
+		if job.Status != "completed" {
+			child.Status = "skipped"
+			child.Error = fmt.Sprintf("dependency %s (%s) %s", job.Name, job.ID, job.Status)
+			child.EndTime = time.Now()
+			if err := s.storage.UpdateJob(child); err != nil {
+				return err
+			}
+			if err := s.releaseDependents(child); err != nil {
+				return err
+			}
+			continue
+		}
+
+		ready, err := s.dependenciesCompleted(child)
+		if err != nil {
+			return err
+		}
+		if !ready {
+			continue
+		}
+		log.Printf("Dependencies of job %s completed, queueing it", child.ID)
+		child.Status = "pending"
+		if err := s.storage.UpdateJob(child); err != nil {
+			return err
+		}
+		s.enqueue(child)
+	}
+	return nil
+}
+
+// dependenciesCompleted reports whether every job a job depends on completed.
+// Callers must hold s.mu.
+func (s *Scheduler) dependenciesCompleted(job *models.Job) (bool, error) {
+	for _, parentID := range job.DependsOn {
+		parent, err := s.storage.GetJob(parentID)
+		if err != nil {
+			return false, err
+		}
+		if parent.Status != "completed" {
+			return false, nil
+		}
+	}
+	return true, nil
+}
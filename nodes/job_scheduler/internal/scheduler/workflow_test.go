@@ -0,0 +1,43 @@
+package scheduler
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Shishir_grez/coltnode/nodes/job_scheduler/pkg/models"
+)
+
+func TestValidateDAG(t *testing.T) {
+	tests := []struct {
+		name    string
+		deps    map[string][]string // Dependencies of each job, by job ID
+		wantErr bool
+	}{
+		{name: "no jobs", deps: map[string][]string{}},
+		{name: "independent jobs", deps: map[string][]string{"a": nil, "b": nil}},
+		{name: "chain", deps: map[string][]string{"a": nil, "b": {"a"}, "c": {"b"}}},
+		{name: "diamond", deps: map[string][]string{"a": nil, "b": {"a"}, "c": {"a"}, "d": {"b", "c"}}},
+		{name: "self dependency", deps: map[string][]string{"a": {"a"}}, wantErr: true},
+		{name: "unknown dependency", deps: map[string][]string{"a": nil, "b": {"x"}}, wantErr: true},
+		{name: "two job cycle", deps: map[string][]string{"a": {"b"}, "b": {"a"}}, wantErr: true},
+		{name: "cycle below a root", deps: map[string][]string{"a": nil, "b": {"a", "d"}, "c": {"b"}, "d": {"c"}}, wantErr: true},
+		{name: "every job in a cycle", deps: map[string][]string{"a": {"c"}, "b": {"a"}, "c": {"b"}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var jobs []*models.Job
+			for id, deps := range tt.deps {
+				jobs = append(jobs, &models.Job{ID: id, Name: id, DependsOn: deps})
+			}
+
+			err := validateDAG(jobs)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("validateDAG() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidWorkflow) {
+				t.Errorf("validateDAG() error = %v, want ErrInvalidWorkflow", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,283 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/Shishir_grez/coltnode/nodes/job_scheduler/pkg/models"
+)
+
+// FileStorage keeps jobs, workers, workflows, schedules, namespaces and API tokens in memory and persists the full state
+// to a single JSON file after every mutation, so it survives a restart. Updates
+// that only move a worker's last heartbeat are not written on their own, as
+// rewriting the whole file for every heartbeat does not scale with workers.
+type FileStorage struct {
+	*MemoryStorage
+	path  string
+	saved map[string]*models.Worker // Workers as last written, to spot heartbeat-only updates
+	mu    sync.Mutex                // Serializes writes to the file
+}
+
+// fileState is the on-disk layout of a FileStorage
+type fileState struct {
+	Jobs       []*models.Job
+	Workers    []*models.Worker
+	Workflows  []*models.Workflow
+	Schedules  []*models.Schedule
+	Namespaces []*models.Namespace
+	Tokens     []*models.Token
+}
+
+// NewFileStorage opens the storage file at path, loading any existing state
+func NewFileStorage(path string) (*FileStorage, error) {
+	s := &FileStorage{
+		MemoryStorage: NewMemoryStorage(),
+		path:          path,
+		saved:         make(map[string]*models.Worker),
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var state fileState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, err
+	}
+	for _, job := range state.Jobs {
+		s.jobs[job.ID] = job
+	}
+	// Heartbeats in the file may be older than the last ones received, so
+	// workers get a full heartbeat timeout from now to check in again
+	now := time.Now()
+	for _, worker := range state.Workers {
+		worker.LastHeartbeat = now
+		s.workers[worker.ID] = worker
+		s.saved[worker.ID] = worker.Clone()
+	}
+	for _, workflow := range state.Workflows {
+		s.workflows[workflow.ID] = workflow
+	}
+	for _, schedule := range state.Schedules {
+		s.schedules[schedule.ID] = schedule
+	}
+	for _, namespace := range state.Namespaces {
+		s.namespaces[namespace.Name] = namespace
+	}
+	for _, token := range state.Tokens {
+		s.tokens[token.ID] = token
+	}
+	return s, nil
+}
+
+// SaveJob stores a job and persists the state
+func (s *FileStorage) SaveJob(job *models.Job) error {
+	if err := s.MemoryStorage.SaveJob(job); err != nil {
+		return err
+	}
+	return s.persist()
+}
+
+// UpdateJob updates an existing job and persists the state
+func (s *FileStorage) UpdateJob(job *models.Job) error {
+	if err := s.MemoryStorage.UpdateJob(job); err != nil {
+		return err
+	}
+	return s.persist()
+}
+
+// SaveWorker stores a worker and persists the state
+func (s *FileStorage) SaveWorker(worker *models.Worker) error {
+	if err := s.MemoryStorage.SaveWorker(worker); err != nil {
+		return err
+	}
+	return s.persist()
+}
+
+// UpdateWorker updates an existing worker and persists the state, unless
+// only its last heartbeat changed
+func (s *FileStorage) UpdateWorker(worker *models.Worker) error {
+	if err := s.MemoryStorage.UpdateWorker(worker); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	unchanged := heartbeatOnly(s.saved[worker.ID], worker)
+	s.mu.Unlock()
+	if unchanged {
+		return nil
+	}
+	return s.persist()
+}
+
+// DeleteWorker removes a worker and persists the state
+func (s *FileStorage) DeleteWorker(id string) error {
+	if err := s.MemoryStorage.DeleteWorker(id); err != nil {
+		return err
+	}
+	return s.persist()
+}
+
+// SaveWorkflow stores a workflow and persists the state
+func (s *FileStorage) SaveWorkflow(workflow *models.Workflow) error {
+	if err := s.MemoryStorage.SaveWorkflow(workflow); err != nil {
+		return err
+	}
+	return s.persist()
+}
+
+// SaveSchedule stores a schedule and persists the state
+func (s *FileStorage) SaveSchedule(schedule *models.Schedule) error {
+	if err := s.MemoryStorage.SaveSchedule(schedule); err != nil {
+		return err
+	}
+	return s.persist()
+}
+
+// UpdateSchedule updates an existing schedule and persists the state
+func (s *FileStorage) UpdateSchedule(schedule *models.Schedule) error {
+	if err := s.MemoryStorage.UpdateSchedule(schedule); err != nil {
+		return err
+	}
+	return s.persist()
+}
+
+// DeleteSchedule removes a schedule and persists the state
+func (s *FileStorage) DeleteSchedule(id string) error {
+	if err := s.MemoryStorage.DeleteSchedule(id); err != nil {
+		return err
+	}
+	return s.persist()
+}
+
+// SaveNamespace stores a namespace and persists the state
+func (s *FileStorage) SaveNamespace(namespace *models.Namespace) error {
+	if err := s.MemoryStorage.SaveNamespace(namespace); err != nil {
+		return err
+	}
+	return s.persist()
+}
+
+// UpdateNamespace updates an existing namespace and persists the state
+func (s *FileStorage) UpdateNamespace(namespace *models.Namespace) error {
+	if err := s.MemoryStorage.UpdateNamespace(namespace); err != nil {
+		return err
+	}
+	return s.persist()
+}
+
+// DeleteNamespace removes a namespace and persists the state
+func (s *FileStorage) DeleteNamespace(name string) error {
+	if err := s.MemoryStorage.DeleteNamespace(name); err != nil {
+		return err
+	}
+	return s.persist()
+}
+
+// SaveToken stores an API token and persists the state
+func (s *FileStorage) SaveToken(token *models.Token) error {
+	if err := s.MemoryStorage.SaveToken(token); err != nil {
+		return err
+	}
+	return s.persist()
+}
+
+// DeleteToken removes an API token and persists the state
+func (s *FileStorage) DeleteToken(id string) error {
+	if err := s.MemoryStorage.DeleteToken(id); err != nil {
+		return err
+	}
+	return s.persist()
+}
+
+// persist writes the whole state to a temporary file and renames it over
+// the storage file, so a crash never leaves a partially written file behind
+func (s *FileStorage) persist() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	jobs, err := s.GetAllJobs()
+	if err != nil {
+		return err
+	}
+	workers, err := s.GetAllWorkers()
+	if err != nil {
+		return err
+	}
+	workflows, err := s.GetAllWorkflows()
+	if err != nil {
+		return err
+	}
+	schedules, err := s.GetAllSchedules()
+	if err != nil {
+		return err
+	}
+
+	namespaces, err := s.GetAllNamespaces()
+	if err != nil {
+		return err
+	}
+
+	tokens, err := s.GetAllTokens()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(fileState{Jobs: jobs, Workers: workers, Workflows: workflows, Schedules: schedules, Namespaces: namespaces, Tokens: tokens})
+	if err != nil {
+		return err
+	}
+
+	if err := writeFileAtomic(s.path, data); err != nil {
+		return err
+	}
+
+	s.saved = make(map[string]*models.Worker, len(workers))
+	for _, worker := range workers {
+		s.saved[worker.ID] = worker.Clone()
+	}
+	return nil
+}
+
+// heartbeatOnly reports whether a worker differs from its last written state
+// in nothing but its last heartbeat
+func heartbeatOnly(saved, worker *models.Worker) bool {
+	if saved == nil {
+		return false
+	}
+	before, after := *saved, *worker
+	before.LastHeartbeat = time.Time{}
+	after.LastHeartbeat = time.Time{}
+	return reflect.DeepEqual(before, after)
+}
+
+// writeFileAtomic replaces path with data via a synced temporary file
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
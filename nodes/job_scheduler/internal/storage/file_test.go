@@ -0,0 +1,183 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Shishir_grez/coltnode/nodes/job_scheduler/pkg/models"
+)
+
+func TestFileStorageReload(t *testing.T) {
+	submitted := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		mutate     func(t *testing.T, s *FileStorage)
+		wantJobs   map[string]string // Status of each job, by ID
+		wantWorker string            // Status of worker w1, empty if it should not exist
+	}{
+		{
+			name:     "empty",
+			mutate:   func(t *testing.T, s *FileStorage) {},
+			wantJobs: map[string]string{},
+		},
+		{
+			name: "saved",
+			mutate: func(t *testing.T, s *FileStorage) {
+				check(t, s.SaveJob(&models.Job{ID: "j1", Name: "build", Command: "make", Status: "pending", SubmitTime: submitted}))
+				check(t, s.SaveWorker(&models.Worker{ID: "w1", Name: "worker", Status: "active"}))
+			},
+			wantJobs:   map[string]string{"j1": "pending"},
+			wantWorker: "active",
+		},
+		{
+			name: "updated",
+			mutate: func(t *testing.T, s *FileStorage) {
+				job := &models.Job{ID: "j1", Name: "build", Command: "make", Status: "pending", SubmitTime: submitted}
+				check(t, s.SaveJob(job))
+				check(t, s.SaveJob(&models.Job{ID: "j2", Name: "test", Status: "pending", SubmitTime: submitted}))
+				job.Status = "completed"
+				check(t, s.UpdateJob(job))
+				worker := &models.Worker{ID: "w1", Name: "worker", Status: "active"}
+				check(t, s.SaveWorker(worker))
+				worker.Status = "inactive"
+				check(t, s.UpdateWorker(worker))
+			},
+			wantJobs:   map[string]string{"j1": "completed", "j2": "pending"},
+			wantWorker: "inactive",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "state.json")
+			s, err := NewFileStorage(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.mutate(t, s)
+
+			reloaded, err := NewFileStorage(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			jobs, err := reloaded.GetAllJobs()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(jobs) != len(tt.wantJobs) {
+				t.Errorf("reloaded %d jobs, want %d", len(jobs), len(tt.wantJobs))
+			}
+			for _, job := range jobs {
+				if job.Status != tt.wantJobs[job.ID] {
+					t.Errorf("job %s is %q, want %q", job.ID, job.Status, tt.wantJobs[job.ID])
+				}
+				if !job.SubmitTime.Equal(submitted) {
+					t.Errorf("job %s submit time = %s, want %s", job.ID, job.SubmitTime, submitted)
+				}
+			}
+			if j1, err := reloaded.GetJob("j1"); err == nil && (j1.Name != "build" || j1.Command != "make") {
+				t.Errorf("job j1 = %+v, want the saved name and command", j1)
+			}
+
+			worker, err := reloaded.GetWorker("w1")
+			switch {
+			case tt.wantWorker == "" && err == nil:
+				t.Errorf("worker w1 exists after reload, want none")
+			case tt.wantWorker != "" && err != nil:
+				t.Errorf("worker w1: %v", err)
+			case tt.wantWorker != "" && worker.Status != tt.wantWorker:
+				t.Errorf("worker w1 is %q, want %q", worker.Status, tt.wantWorker)
+			}
+
+			// Writes go through a temporary file that must not be left behind
+			entries, err := os.ReadDir(filepath.Dir(path))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, entry := range entries {
+				if entry.Name() != "state.json" {
+					t.Errorf("unexpected file %s left next to the state", entry.Name())
+				}
+			}
+		})
+	}
+}
+
+func TestFileStorageHeartbeats(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	s, err := NewFileStorage(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	worker := &models.Worker{ID: "w1", Name: "worker", Status: "active", LastHeartbeat: time.Now().Add(-time.Hour)}
+	check(t, s.SaveWorker(worker))
+	written, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A heartbeat alone is kept in memory only
+	worker.LastHeartbeat = time.Now()
+	check(t, s.UpdateWorker(worker))
+	if data, err := os.ReadFile(path); err != nil || string(data) != string(written) {
+		t.Errorf("file rewritten for a heartbeat")
+	}
+	if got, err := s.GetWorker("w1"); err != nil || !got.LastHeartbeat.Equal(worker.LastHeartbeat) {
+		t.Errorf("worker = %+v, %v, want the new heartbeat in memory", got, err)
+	}
+
+	// Any other change is written, heartbeat included
+	before := time.Now()
+	worker.Status = "offline"
+	check(t, s.UpdateWorker(worker))
+	reloaded, err := NewFileStorage(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := reloaded.GetWorker("w1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != "offline" {
+		t.Errorf("reloaded worker is %q, want offline", got.Status)
+	}
+	// Reloaded workers get a fresh heartbeat to check in again
+	if got.LastHeartbeat.Before(before) {
+		t.Errorf("reloaded heartbeat = %s, want at least %s", got.LastHeartbeat, before)
+	}
+}
+
+func TestFileStorageOpenErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string // Written to the state file first; empty leaves no file
+		wantErr bool
+	}{
+		{name: "missing file"},
+		{name: "valid state", content: `{"Jobs":[{"ID":"j1","Status":"pending"}],"Workers":null}`},
+		{name: "corrupt state", content: `{"Jobs":[`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "state.json")
+			if tt.content != "" {
+				check(t, os.WriteFile(path, []byte(tt.content), 0o644))
+			}
+			_, err := NewFileStorage(path)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewFileStorage() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func check(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
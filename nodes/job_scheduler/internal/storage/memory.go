@@ -7,18 +7,26 @@ import (
 	"github.com/Shishir_grez/coltnode/nodes/job_scheduler/pkg/models"
 )
 
-// MemoryStorage implements in-memory storage for jobs and workers
+// MemoryStorage implements in-memory storage for jobs, workers, workflows, schedules, namespaces and API tokens
 type MemoryStorage struct {
-	jobs    map[string]*models.Job
-	workers map[string]*models.Worker
-	mu      sync.RWMutex
+	jobs       map[string]*models.Job
+	workers    map[string]*models.Worker
+	workflows  map[string]*models.Workflow
+	schedules  map[string]*models.Schedule
+	namespaces map[string]*models.Namespace // Keyed by name
+	tokens     map[string]*models.Token
+	mu         sync.RWMutex
 }
 
 // NewMemoryStorage creates a new memory storage instance
 func NewMemoryStorage() *MemoryStorage {
 	return &MemoryStorage{
-		jobs:    make(map[string]*models.Job),
-		workers: make(map[string]*models.Worker),
+		jobs:       make(map[string]*models.Job),
+		workers:    make(map[string]*models.Worker),
+		workflows:  make(map[string]*models.Workflow),
+		schedules:  make(map[string]*models.Schedule),
+		namespaces: make(map[string]*models.Namespace),
+		tokens:     make(map[string]*models.Token),
 	}
 }
 
@@ -92,6 +100,20 @@ func (s *MemoryStorage) UpdateWorker(worker *models.Worker) error {
 	return nil
 }
 
+// DeleteWorker removes a worker
+func (s *MemoryStorage) DeleteWorker(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	
+	_, exists := s.workers[id]
+	if !exists {
+		return errors.New("worker not found")
+	}
+	
+	delete(s.workers, id)
+	return nil
+}
+
 // GetAvailableWorkers returns all workers with "active" status
 func (s *MemoryStorage) GetAvailableWorkers() ([]*models.Worker, error) {
 	s.mu.RLock()
@@ -116,4 +138,218 @@ func (s *MemoryStorage) GetAllJobs() ([]*models.Job, error) {
 		jobs = append(jobs, job)
 	}
 	return jobs, nil
+}
+
+// GetAllWorkers returns all workers in the storage, whatever their status
+func (s *MemoryStorage) GetAllWorkers() ([]*models.Worker, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	
+	workers := make([]*models.Worker, 0, len(s.workers))
+	for _, worker := range s.workers {
+		workers = append(workers, worker)
+	}
+	return workers, nil
+}
+
+// SaveWorkflow stores a workflow in memory
+func (s *MemoryStorage) SaveWorkflow(workflow *models.Workflow) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	
+	s.workflows[workflow.ID] = workflow
+	return nil
+}
+
+// GetWorkflow retrieves a workflow by ID
+func (s *MemoryStorage) GetWorkflow(id string) (*models.Workflow, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	
+	workflow, exists := s.workflows[id]
+	if !exists {
+		return nil, errors.New("workflow not found")
+	}
+	return workflow, nil
+}
+
+// GetAllWorkflows returns all workflows in the storage
+func (s *MemoryStorage) GetAllWorkflows() ([]*models.Workflow, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	
+	workflows := make([]*models.Workflow, 0, len(s.workflows))
+	for _, workflow := range s.workflows {
+		workflows = append(workflows, workflow)
+	}
+	return workflows, nil
+}
+
+// SaveSchedule stores a schedule in memory
+func (s *MemoryStorage) SaveSchedule(schedule *models.Schedule) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	
+	s.schedules[schedule.ID] = schedule
+	return nil
+}
+
+// GetSchedule retrieves a schedule by ID
+func (s *MemoryStorage) GetSchedule(id string) (*models.Schedule, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	
+	schedule, exists := s.schedules[id]
+	if !exists {
+		return nil, errors.New("schedule not found")
+	}
+	return schedule, nil
+}
+
+// UpdateSchedule updates an existing schedule
+func (s *MemoryStorage) UpdateSchedule(schedule *models.Schedule) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	
+	_, exists := s.schedules[schedule.ID]
+	if !exists {
+		return errors.New("schedule not found")
+	}
+	
+	s.schedules[schedule.ID] = schedule
+	return nil
+}
+
+// DeleteSchedule removes a schedule
+func (s *MemoryStorage) DeleteSchedule(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	
+	_, exists := s.schedules[id]
+	if !exists {
+		return errors.New("schedule not found")
+	}
+	
+	delete(s.schedules, id)
+	return nil
+}
+
+// GetAllSchedules returns all schedules in the storage
+func (s *MemoryStorage) GetAllSchedules() ([]*models.Schedule, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	
+	schedules := make([]*models.Schedule, 0, len(s.schedules))
+	for _, schedule := range s.schedules {
+		schedules = append(schedules, schedule)
+	}
+	return schedules, nil
+}
+
+// SaveNamespace stores a namespace in memory
+func (s *MemoryStorage) SaveNamespace(namespace *models.Namespace) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	
+	s.namespaces[namespace.Name] = namespace
+	return nil
+}
+
+// GetNamespace retrieves a namespace by name
+func (s *MemoryStorage) GetNamespace(name string) (*models.Namespace, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	
+	namespace, exists := s.namespaces[name]
+	if !exists {
+		return nil, errors.New("namespace not found")
+	}
+	return namespace, nil
+}
+
+// UpdateNamespace updates an existing namespace
+func (s *MemoryStorage) UpdateNamespace(namespace *models.Namespace) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	
+	_, exists := s.namespaces[namespace.Name]
+	if !exists {
+		return errors.New("namespace not found")
+	}
+	
+	s.namespaces[namespace.Name] = namespace
+	return nil
+}
+
+// DeleteNamespace removes a namespace
+func (s *MemoryStorage) DeleteNamespace(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	
+	_, exists := s.namespaces[name]
+	if !exists {
+		return errors.New("namespace not found")
+	}
+	
+	delete(s.namespaces, name)
+	return nil
+}
+
+// GetAllNamespaces returns all namespaces in the storage
+func (s *MemoryStorage) GetAllNamespaces() ([]*models.Namespace, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	
+	namespaces := make([]*models.Namespace, 0, len(s.namespaces))
+	for _, namespace := range s.namespaces {
+		namespaces = append(namespaces, namespace)
+	}
+	return namespaces, nil
+}
+
+// SaveToken stores an API token in memory
+func (s *MemoryStorage) SaveToken(token *models.Token) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	
+	s.tokens[token.ID] = token
+	return nil
+}
+
+// GetToken retrieves an API token by ID
+func (s *MemoryStorage) GetToken(id string) (*models.Token, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	
+	token, exists := s.tokens[id]
+	if !exists {
+		return nil, errors.New("token not found")
+	}
+	return token, nil
+}
+
+// DeleteToken removes an API token
+func (s *MemoryStorage) DeleteToken(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	
+	_, exists := s.tokens[id]
+	if !exists {
+		return errors.New("token not found")
+	}
+	
+	delete(s.tokens, id)
+	return nil
+}
+
+// GetAllTokens returns all API tokens in the storage
+func (s *MemoryStorage) GetAllTokens() ([]*models.Token, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	
+	tokens := make([]*models.Token, 0, len(s.tokens))
+	for _, token := range s.tokens {
+		tokens = append(tokens, token)
+	}
+	return tokens, nil
 }
\ No newline at end of file
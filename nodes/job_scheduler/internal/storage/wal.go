@@ -0,0 +1,430 @@
+package storage
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/Shishir_grez/coltnode/nodes/job_scheduler/pkg/models"
+)
+
+// ErrStorageFailed is returned for every mutation once the write-ahead log
+// could not be written. The server has to be restarted to recover from the log.
+var ErrStorageFailed = errors.New("storage failed")
+
+const (
+	walFileName      = "wal.log"
+	snapshotFileName = "snapshot.json"
+)
+
+// WALStorage serves reads from memory like MemoryStorage, but appends every
+// mutation to a write-ahead log first. The log is compacted into a snapshot
+// every compactEvery entries, and snapshot+log are replayed when it is opened,
+// so the state survives the process being killed.
+type WALStorage struct {
+	*MemoryStorage
+	dir          string
+	wal          *os.File
+	seq          uint64 // Sequence number of the last entry written
+	entries      int    // Entries appended since the last snapshot
+	size         int64  // Length of the log up to the end of the last complete entry
+	compactEvery int
+	sync         bool  // fsync after every entry, to also survive power loss
+	failed       error // Set once a write failed; every later mutation returns it
+	mu           sync.Mutex
+}
+
+// walEntry is a single line of the write-ahead log. Saves and updates are
+// both recorded as the full object, so replaying an entry is an upsert.
+// Deletions are recorded by ID, or by name for namespaces.
+type walEntry struct {
+	Seq              uint64
+	Job              *models.Job       `json:",omitempty"`
+	Worker           *models.Worker    `json:",omitempty"`
+	Workflow         *models.Workflow  `json:",omitempty"`
+	Schedule         *models.Schedule  `json:",omitempty"`
+	Namespace        *models.Namespace `json:",omitempty"`
+	Token            *models.Token     `json:",omitempty"`
+	DeletedWorker    string            `json:",omitempty"`
+	DeletedSchedule  string            `json:",omitempty"`
+	DeletedNamespace string            `json:",omitempty"`
+	DeletedToken     string            `json:",omitempty"`
+}
+
+// walSnapshot is the compacted state, covering every entry up to Seq
+type walSnapshot struct {
+	Seq        uint64
+	Jobs       []*models.Job
+	Workers    []*models.Worker
+	Workflows  []*models.Workflow
+	Schedules  []*models.Schedule
+	Namespaces []*models.Namespace
+	Tokens     []*models.Token
+}
+
+// NewWALStorage opens the log in dir, replaying any snapshot and log found there
+func NewWALStorage(dir string, compactEvery int, sync bool) (*WALStorage, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	s := &WALStorage{
+		MemoryStorage: NewMemoryStorage(),
+		dir:           dir,
+		compactEvery:  compactEvery,
+		sync:          sync,
+	}
+	if err := s.replay(); err != nil {
+		return nil, err
+	}
+
+	// Start from a fresh snapshot so the old log does not have to be replayed again
+	if err := s.compact(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// SaveJob logs and stores a job
+func (s *WALStorage) SaveJob(job *models.Job) error {
+	return s.record(walEntry{Job: job}, nil, func() error {
+		return s.MemoryStorage.SaveJob(job)
+	})
+}
+
+// UpdateJob logs and updates an existing job
+func (s *WALStorage) UpdateJob(job *models.Job) error {
+	return s.record(walEntry{Job: job}, func() error {
+		_, err := s.MemoryStorage.GetJob(job.ID)
+		return err
+	}, func() error {
+		return s.MemoryStorage.UpdateJob(job)
+	})
+}
+
+// SaveWorker logs and stores a worker
+func (s *WALStorage) SaveWorker(worker *models.Worker) error {
+	return s.record(walEntry{Worker: worker}, nil, func() error {
+		return s.MemoryStorage.SaveWorker(worker)
+	})
+}
+
+// UpdateWorker logs and updates an existing worker
+func (s *WALStorage) UpdateWorker(worker *models.Worker) error {
+	return s.record(walEntry{Worker: worker}, func() error {
+		_, err := s.MemoryStorage.GetWorker(worker.ID)
+		return err
+	}, func() error {
+		return s.MemoryStorage.UpdateWorker(worker)
+	})
+}
+
+// DeleteWorker logs and removes a worker
+func (s *WALStorage) DeleteWorker(id string) error {
+	return s.record(walEntry{DeletedWorker: id}, func() error {
+		_, err := s.MemoryStorage.GetWorker(id)
+		return err
+	}, func() error {
+		return s.MemoryStorage.DeleteWorker(id)
+	})
+}
+
+// SaveWorkflow logs and stores a workflow
+func (s *WALStorage) SaveWorkflow(workflow *models.Workflow) error {
+	return s.record(walEntry{Workflow: workflow}, nil, func() error {
+		return s.MemoryStorage.SaveWorkflow(workflow)
+	})
+}
+
+// SaveSchedule logs and stores a schedule
+func (s *WALStorage) SaveSchedule(schedule *models.Schedule) error {
+	return s.record(walEntry{Schedule: schedule}, nil, func() error {
+		return s.MemoryStorage.SaveSchedule(schedule)
+	})
+}
+
+// UpdateSchedule logs and updates an existing schedule
+func (s *WALStorage) UpdateSchedule(schedule *models.Schedule) error {
+	return s.record(walEntry{Schedule: schedule}, func() error {
+		_, err := s.MemoryStorage.GetSchedule(schedule.ID)
+		return err
+	}, func() error {
+		return s.MemoryStorage.UpdateSchedule(schedule)
+	})
+}
+
+// DeleteSchedule logs and removes a schedule
+func (s *WALStorage) DeleteSchedule(id string) error {
+	return s.record(walEntry{DeletedSchedule: id}, func() error {
+		_, err := s.MemoryStorage.GetSchedule(id)
+		return err
+	}, func() error {
+		return s.MemoryStorage.DeleteSchedule(id)
+	})
+}
+
+// SaveNamespace logs and stores a namespace
+func (s *WALStorage) SaveNamespace(namespace *models.Namespace) error {
+	return s.record(walEntry{Namespace: namespace}, nil, func() error {
+		return s.MemoryStorage.SaveNamespace(namespace)
+	})
+}
+
+// UpdateNamespace logs and updates an existing namespace
+func (s *WALStorage) UpdateNamespace(namespace *models.Namespace) error {
+	return s.record(walEntry{Namespace: namespace}, func() error {
+		_, err := s.MemoryStorage.GetNamespace(namespace.Name)
+		return err
+	}, func() error {
+		return s.MemoryStorage.UpdateNamespace(namespace)
+	})
+}
+
+// DeleteNamespace logs and removes a namespace
+func (s *WALStorage) DeleteNamespace(name string) error {
+	return s.record(walEntry{DeletedNamespace: name}, func() error {
+		_, err := s.MemoryStorage.GetNamespace(name)
+		return err
+	}, func() error {
+		return s.MemoryStorage.DeleteNamespace(name)
+	})
+}
+
+// SaveToken logs and stores an API token
+func (s *WALStorage) SaveToken(token *models.Token) error {
+	return s.record(walEntry{Token: token}, nil, func() error {
+		return s.MemoryStorage.SaveToken(token)
+	})
+}
+
+// DeleteToken logs and removes an API token
+func (s *WALStorage) DeleteToken(id string) error {
+	return s.record(walEntry{DeletedToken: id}, func() error {
+		_, err := s.MemoryStorage.GetToken(id)
+		return err
+	}, func() error {
+		return s.MemoryStorage.DeleteToken(id)
+	})
+}
+
+// Close writes a final snapshot and closes the log
+func (s *WALStorage) Close() error {
+	if err := s.compact(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.wal.Close()
+}
+
+// record validates an entry with check, appends it to the log and then
+// applies it to memory, compacting the log once it has grown enough. All of it
+// happens under s.mu, so the check still holds when apply runs and a snapshot
+// never misses a mutation whose log entry it covers. If the entry cannot be
+// written, the log is cut back to the last complete entry and the store stops
+// accepting mutations, since memory and disk could no longer be kept in step.
+func (s *WALStorage) record(entry walEntry, check func() error, apply func() error) error {
+	s.mu.Lock()
+	if s.failed != nil {
+		s.mu.Unlock()
+		return s.failed
+	}
+	if check != nil {
+		if err := check(); err != nil {
+			s.mu.Unlock()
+			return err
+		}
+	}
+
+	entry.Seq = s.seq + 1
+	data, err := json.Marshal(entry)
+	if err != nil {
+		s.mu.Unlock()
+		return err
+	}
+	if err := s.append(append(data, '\n')); err != nil {
+		s.mu.Unlock()
+		return err
+	}
+	s.seq = entry.Seq
+	err = apply()
+	s.entries++
+	due := s.compactEvery > 0 && s.entries >= s.compactEvery
+	s.mu.Unlock()
+
+	if err != nil {
+		return err
+	}
+	if due {
+		return s.compact()
+	}
+	return nil
+}
+
+// append writes a log entry and syncs it if asked to. On failure it truncates
+// the log back to where the entry started and marks the store failed.
+// Callers must hold s.mu.
+func (s *WALStorage) append(data []byte) error {
+	_, err := s.wal.Write(data)
+	if err == nil && s.sync {
+		err = s.wal.Sync()
+	}
+	if err == nil {
+		s.size += int64(len(data))
+		return nil
+	}
+
+	if truncErr := os.Truncate(filepath.Join(s.dir, walFileName), s.size); truncErr != nil {
+		err = errors.Join(err, truncErr)
+	}
+	s.failed = fmt.Errorf("%w: %w", ErrStorageFailed, err)
+	return s.failed
+}
+
+// compact writes the current state to the snapshot file and truncates the log
+func (s *WALStorage) compact() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	jobs, err := s.GetAllJobs()
+	if err != nil {
+		return err
+	}
+	workers, err := s.GetAllWorkers()
+	if err != nil {
+		return err
+	}
+	workflows, err := s.GetAllWorkflows()
+	if err != nil {
+		return err
+	}
+	schedules, err := s.GetAllSchedules()
+	if err != nil {
+		return err
+	}
+
+	namespaces, err := s.GetAllNamespaces()
+	if err != nil {
+		return err
+	}
+
+	tokens, err := s.GetAllTokens()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(walSnapshot{Seq: s.seq, Jobs: jobs, Workers: workers, Workflows: workflows, Schedules: schedules, Namespaces: namespaces, Tokens: tokens})
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(filepath.Join(s.dir, snapshotFileName), data); err != nil {
+		return err
+	}
+
+	// Entries up to seq are now covered by the snapshot and skipped on replay,
+	// so a crash before the truncate below is harmless
+	if s.wal != nil {
+		s.wal.Close()
+	}
+	s.wal, err = os.OpenFile(filepath.Join(s.dir, walFileName), os.O_CREATE|os.O_TRUNC|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		s.failed = fmt.Errorf("%w: %w", ErrStorageFailed, err)
+		return s.failed
+	}
+	s.size = 0
+	s.entries = 0
+	return nil
+}
+
+// replay loads the snapshot and applies every newer log entry on top of it
+func (s *WALStorage) replay() error {
+	data, err := os.ReadFile(filepath.Join(s.dir, snapshotFileName))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if err == nil {
+		var snapshot walSnapshot
+		if err := json.Unmarshal(data, &snapshot); err != nil {
+			return err
+		}
+		for _, job := range snapshot.Jobs {
+			s.jobs[job.ID] = job
+		}
+		for _, worker := range snapshot.Workers {
+			s.workers[worker.ID] = worker
+		}
+		for _, workflow := range snapshot.Workflows {
+			s.workflows[workflow.ID] = workflow
+		}
+		for _, schedule := range snapshot.Schedules {
+			s.schedules[schedule.ID] = schedule
+		}
+		for _, namespace := range snapshot.Namespaces {
+			s.namespaces[namespace.Name] = namespace
+		}
+		for _, token := range snapshot.Tokens {
+			s.tokens[token.ID] = token
+		}
+		s.seq = snapshot.Seq
+	}
+
+	f, err := os.Open(filepath.Join(s.dir, walFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		var entry walEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			// A torn final write from a crash; everything before it is intact
+			break
+		}
+		if entry.Seq <= s.seq {
+			continue
+		}
+		if entry.Job != nil {
+			s.jobs[entry.Job.ID] = entry.Job
+		}
+		if entry.Worker != nil {
+			s.workers[entry.Worker.ID] = entry.Worker
+		}
+		if entry.Workflow != nil {
+			s.workflows[entry.Workflow.ID] = entry.Workflow
+		}
+		if entry.Schedule != nil {
+			s.schedules[entry.Schedule.ID] = entry.Schedule
+		}
+		if entry.DeletedWorker != "" {
+			delete(s.workers, entry.DeletedWorker)
+		}
+		if entry.Namespace != nil {
+			s.namespaces[entry.Namespace.Name] = entry.Namespace
+		}
+		if entry.DeletedSchedule != "" {
+			delete(s.schedules, entry.DeletedSchedule)
+		}
+		if entry.DeletedNamespace != "" {
+			delete(s.namespaces, entry.DeletedNamespace)
+		}
+		if entry.Token != nil {
+			s.tokens[entry.Token.ID] = entry.Token
+		}
+		if entry.DeletedToken != "" {
+			delete(s.tokens, entry.DeletedToken)
+		}
+		s.seq = entry.Seq
+	}
+	return scanner.Err()
+}
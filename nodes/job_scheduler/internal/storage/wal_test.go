@@ -0,0 +1,170 @@
+package storage
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Shishir_grez/coltnode/nodes/job_scheduler/pkg/models"
+)
+
+func TestWALReplay(t *testing.T) {
+	tests := []struct {
+		name         string
+		compactEvery int
+		close        bool   // Close cleanly rather than simulate a crash
+		torn         string // Partial entry left at the end of the log by a crash
+		wantEntries  int    // Entries left in the log before reopening
+	}{
+		{name: "crash without compaction", compactEvery: 0, wantEntries: 5},
+		{name: "crash after compactions", compactEvery: 2, wantEntries: 1},
+		{name: "crash right after compacting", compactEvery: 5, wantEntries: 0},
+		{name: "clean close", compactEvery: 0, close: true, wantEntries: 0},
+		{name: "torn final entry", compactEvery: 0, torn: `{"Seq":99,"Job":{"ID":"j`, wantEntries: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			s, err := NewWALStorage(dir, tt.compactEvery, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Five entries: two saves, an update and a save and delete
+			job := &models.Job{ID: "j1", Name: "build", Status: "pending"}
+			worker := &models.Worker{ID: "w1", Name: "worker"}
+			check(t, s.SaveJob(job))
+			check(t, s.SaveWorker(worker))
+			job.Status = "completed"
+			check(t, s.UpdateJob(job))
+			check(t, s.SaveWorker(&models.Worker{ID: "w2", Name: "gone"}))
+			check(t, s.DeleteWorker("w2"))
+
+			if tt.close {
+				check(t, s.Close())
+			}
+			if tt.torn != "" {
+				f, err := os.OpenFile(filepath.Join(dir, walFileName), os.O_APPEND|os.O_WRONLY, 0o644)
+				if err != nil {
+					t.Fatal(err)
+				}
+				f.WriteString(tt.torn)
+				f.Close()
+			}
+
+			data, err := os.ReadFile(filepath.Join(dir, walFileName))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := bytes.Count(data, []byte("\n")); got != tt.wantEntries {
+				t.Errorf("log holds %d entries, want %d", got, tt.wantEntries)
+			}
+
+			reopened, err := NewWALStorage(dir, tt.compactEvery, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer reopened.Close()
+
+			gotJob, err := reopened.GetJob("j1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if gotJob.Status != "completed" || gotJob.Name != "build" {
+				t.Errorf("job = %+v, want the updated job", gotJob)
+			}
+			if _, err := reopened.GetWorker("w1"); err != nil {
+				t.Errorf("worker w1: %v", err)
+			}
+			if _, err := reopened.GetWorker("w2"); err == nil {
+				t.Errorf("deleted worker w2 came back")
+			}
+
+			// Reopening compacts, so the next crash only replays new entries
+			check(t, reopened.SaveJob(&models.Job{ID: "j2", Status: "pending"}))
+			again, err := NewWALStorage(dir, tt.compactEvery, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer again.Close()
+			jobs, err := again.GetAllJobs()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(jobs) != 2 {
+				t.Errorf("jobs after a second reopen = %d, want 2", len(jobs))
+			}
+		})
+	}
+}
+
+func TestWALWriteFailure(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, walFileName)
+	s, err := NewWALStorage(dir, 0, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(t, s.SaveJob(&models.Job{ID: "j1", Status: "pending"}))
+	written, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Entries that would not apply are rejected before they reach the log
+	if err := s.UpdateJob(&models.Job{ID: "missing"}); err == nil || errors.Is(err, ErrStorageFailed) {
+		t.Fatalf("UpdateJob() of a missing job error = %v, want not found", err)
+	}
+	if data, _ := os.ReadFile(path); !bytes.Equal(data, written) {
+		t.Fatalf("log = %q after a rejected update, want %q", data, written)
+	}
+
+	// A write that fails halfway leaves part of an entry behind
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"Seq":2,"Job":{"ID":"j2"`)
+	f.Close()
+	readOnly, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.wal.Close()
+	s.wal = readOnly
+
+	if err := s.SaveJob(&models.Job{ID: "j2", Status: "pending"}); !errors.Is(err, ErrStorageFailed) {
+		t.Fatalf("SaveJob() with a failing log error = %v, want ErrStorageFailed", err)
+	}
+	if data, _ := os.ReadFile(path); !bytes.Equal(data, written) {
+		t.Errorf("log = %q after a failed write, want it cut back to %q", data, written)
+	}
+	if _, err := s.GetJob("j2"); err == nil {
+		t.Errorf("job j2 was stored although it was not logged")
+	}
+
+	// The store stays failed even once the log could be written again
+	if s.wal, err = os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	defer s.wal.Close()
+	if err := s.SaveJob(&models.Job{ID: "j3", Status: "pending"}); !errors.Is(err, ErrStorageFailed) {
+		t.Errorf("SaveJob() after a failure error = %v, want ErrStorageFailed", err)
+	}
+
+	reopened, err := NewWALStorage(dir, 0, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.Close()
+	jobs, err := reopened.GetAllJobs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs) != 1 || jobs[0].ID != "j1" {
+		t.Errorf("jobs after reopening = %v, want only j1", jobs)
+	}
+}
@@ -0,0 +1,51 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Affinity rule types
+const (
+	AffinitySameWorker      = "same-worker"      // Place the job with the jobs the rule is about
+	AffinityDifferentWorker = "different-worker" // Keep the job away from the jobs the rule is about
+)
+
+// AffinityRule places a job relative to other running jobs, picked by their
+// group or labels. Hard rules must hold for the job to be placed; soft rules
+// make the workers they hold on preferred, by their weight.
+type AffinityRule struct {
+	Type     string   // same-worker or different-worker
+	Group    string   // Jobs in this group; takes precedence over Selector
+	Selector Selector // Jobs whose labels match
+	Hard     bool     // Whether the rule must hold, rather than being a preference
+	Weight   int      // How strongly a soft rule is preferred
+}
+
+// Matches reports whether a job is one the rule is about
+func (r AffinityRule) Matches(job *Job) bool {
+	if r.Group != "" {
+		return job.Group == r.Group
+	}
+	return r.Selector.Matches(job.Labels)
+}
+
+// String formats the rule the way it is written, e.g. "same-worker:@cache"
+// or "prefer-different-worker=5:app=web"
+func (r AffinityRule) String() string {
+	var b strings.Builder
+	if !r.Hard {
+		b.WriteString("prefer-")
+	}
+	b.WriteString(r.Type)
+	if !r.Hard {
+		fmt.Fprintf(&b, "=%d", r.Weight)
+	}
+	b.WriteString(":")
+	if r.Group != "" {
+		b.WriteString("@" + r.Group)
+	} else {
+		b.WriteString(r.Selector.String())
+	}
+	return b.String()
+}
@@ -1,20 +1,136 @@
 package models
 
 import (
-	"time"
 	"github.com/google/uuid"
+	"maps"
+	"slices"
+	"time"
 )
 
 // Job represents a task to be executed by a worker
 type Job struct {
-	ID         string    // Unique identifier for the job
-	Name       string    // Human-readable name for the job
-	Command    string    // Command to be executed
-	Args       []string  // Arguments for the command
-	Status     string    // Current status: pending, running, completed, failed
-	SubmitTime time.Time // Time when the job was submitted
+	ID          string            // Unique identifier for the job
+	Name        string            // Human-readable name for the job
+	Namespace   string            // Namespace the job belongs to and is counted against the quota of
+	Owner       string            // ID of the API token that submitted the job; empty if authentication was off
+	Command     string            // Command to be executed
+	Args        []string          // Arguments for the command
+	Status      string            // Current status: waiting, pending, running, cancelling, completed, failed, cancelled, timed_out, expired, skipped
+	SubmitTime  time.Time         // Time when the job was submitted
+	Resources   Resources         // CPU and memory reserved on the worker while the job runs
+	Policy      string            // Scheduling policy for this job; empty uses the server default
+	Selector    Selector          // Labels a worker must have for the job to be placed on it
+	Preferences []Preference      // Labels that make a worker preferred over others with room
+	Tolerations []Toleration      // Worker taints the job may be placed despite
+	Labels      map[string]string // Key/value attributes other jobs' affinity rules can refer to
+	Group       string            // Group other jobs' affinity rules can refer to, e.g. replicas of a service
+	Affinity    []AffinityRule    // Where to place the job relative to other running jobs
+	Priority    int               // MinPriority to MaxPriority, higher runs first
+	WorkerID    string            // ID of the worker the job was assigned to
+	Undelivered bool              // Set while a running job waits in its worker's pickup queue, which a restart loses
+	ExitCode    int               // Exit code reported by the worker
+	StartTime   time.Time         // Time when the worker started executing the job
+	EndTime     time.Time         // Time when the worker finished executing the job
+	Error       string            // Error reported by the worker, if the command could not run
+	MaxAttempts int               // Number of times the job is run before it is left failed
+	Backoff     Backoff           // Delay between a failed attempt and the next one
+	Attempts    []Attempt         // Every finished attempt, oldest first
+	RetryAt     time.Time         // When a failed job goes back in the queue for its next attempt
+	Timeout     time.Duration     // Longest a single attempt may run; 0 means no limit
+	StartBy     time.Time         // The job expires if it has not started by then; zero means never
+	FinishBy    time.Time         // The job must be done by then or it times out; zero means never
+	WorkflowID  string            // Workflow the job belongs to, if any
+	DependsOn   []string          // IDs of jobs in the same workflow that must complete before this one is queued
+	ScheduleID  string            // Schedule that created the job, if any
+	RunAt       time.Time         // The job is held back until then; zero means it may run right away
+	QueuedAt    time.Time         // When the job last became ready to run; zero while it is not queued
+	WaitTime    time.Duration     // How long the job was ready to run before it was last placed on a worker
+	Reason      string            // Why a queued job has not been placed yet
+}
+
+// NotBefore returns the earliest time a pending job may be queued, which is
+// the later of its RunAt and RetryAt
+func (j *Job) NotBefore() time.Time {
+	if j.RetryAt.After(j.RunAt) {
+		return j.RetryAt
+	}
+	return j.RunAt
+}
+
+// Deadline returns when an attempt that started at start must be stopped,
+// or the zero time if it may run forever
+func (j *Job) Deadline(start time.Time) time.Time {
+	deadline := j.FinishBy
+	if j.Timeout > 0 {
+		if byTimeout := start.Add(j.Timeout); deadline.IsZero() || byTimeout.Before(deadline) {
+			deadline = byTimeout
+		}
+	}
+	return deadline
+}
+
+// MissedStart reports whether the job can no longer start in time. Start by
+// only applies to the first attempt, as a retry has started once already.
+func (j *Job) MissedStart(now time.Time) bool {
+	return len(j.Attempts) == 0 && !j.StartBy.IsZero() && now.After(j.StartBy)
+}
+
+// Clone returns a deep copy of the job, which can be read and changed
+// without affecting the original
+func (j *Job) Clone() *Job {
+	clone := *j
+	clone.Args = slices.Clone(j.Args)
+	clone.Selector = j.Selector.Clone()
+	clone.Preferences = slices.Clone(j.Preferences)
+	for i := range clone.Preferences {
+		clone.Preferences[i].Selector = clone.Preferences[i].Selector.Clone()
+	}
+	clone.Tolerations = slices.Clone(j.Tolerations)
+	clone.Labels = maps.Clone(j.Labels)
+	clone.Affinity = slices.Clone(j.Affinity)
+	for i := range clone.Affinity {
+		clone.Affinity[i].Selector = clone.Affinity[i].Selector.Clone()
+	}
+	clone.Attempts = slices.Clone(j.Attempts)
+	clone.DependsOn = slices.Clone(j.DependsOn)
+	return &clone
+}
+
+// Finished reports whether the job has reached a final status
+func (j *Job) Finished() bool {
+	switch j.Status {
+	case "completed", "failed", "cancelled", "timed_out", "expired", "skipped":
+		return true
+	default:
+		return false
+	}
+}
+
+// Assignment is an instruction sent to a worker: either a job to start or a
+// running job to stop
+type Assignment struct {
+	Job         *Job          `json:"job,omitempty"`
+	Cancel      string        `json:"cancel,omitempty"`       // ID of the job to stop
+	GracePeriod time.Duration `json:"grace_period,omitempty"` // Time between SIGTERM and SIGKILL when stopping
+}
+
+// JobResult is reported by a worker once it has finished executing a job
+type JobResult struct {
+	WorkerID  string    `json:"worker_id" binding:"required"`
+	Status    string    `json:"status" binding:"required"` // completed, failed or timed_out
+	ExitCode  int       `json:"exit_code"`
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+	Error     string    `json:"error"`
 }
 
+// Range of job priorities; jobs get DefaultPriority unless they ask otherwise
+const (
+	MinPriority     = 0
+	MaxPriority     = 9
+	DefaultPriority = 5
+)
+
 func generateUniqueID() string {
 	return uuid.New().String()
 }
@@ -22,11 +138,13 @@ func generateUniqueID() string {
 // NewJob creates a new Job with default values
 func NewJob(name, command string, args []string) *Job {
 	return &Job{
-		ID:         generateUniqueID(), // You'll need to implement this
-		Name:       name,
-		Command:    command,
-		Args:       args,
-		Status:     "pending",
-		SubmitTime: time.Now(),
+		ID:          generateUniqueID(), // You'll need to implement this
+		Name:        name,
+		Command:     command,
+		Args:        args,
+		Status:      "pending",
+		SubmitTime:  time.Now(),
+		Priority:    DefaultPriority,
+		MaxAttempts: 1,
 	}
-}
\ No newline at end of file
+}
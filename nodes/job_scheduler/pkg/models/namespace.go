@@ -0,0 +1,48 @@
+package models
+
+import "time"
+
+// DefaultNamespace holds jobs, schedules and workers that do not name a
+// namespace. It always exists, without a quota unless one is set.
+const DefaultNamespace = "default"
+
+// NamespaceOf returns the namespace a job, schedule or workflow belongs to,
+// given its Namespace field. Ones saved before namespaces existed have none
+// and belong to the default namespace.
+func NamespaceOf(name string) string {
+	if name == "" {
+		return DefaultNamespace
+	}
+	return name
+}
+
+// Namespace partitions jobs, schedules and workers between teams or projects
+type Namespace struct {
+	Name       string    // Unique name of the namespace
+	Quota      Quota     // Limits on the namespace's jobs
+	Weight     int       // Share of the cluster relative to other namespaces under fair-share scheduling; 0 counts as 1
+	CreateTime time.Time // Time when the namespace was created
+}
+
+// Quota limits what a namespace's jobs may use. Zero values mean no limit.
+type Quota struct {
+	MaxRunning int       // Jobs running at the same time
+	MaxQueued  int       // Jobs waiting to run, including held back and waiting workflow jobs
+	Resources  Resources // CPU and memory reserved by running jobs in total
+}
+
+// Usage is what a namespace's jobs currently use, counted against its quota
+type Usage struct {
+	Running   int
+	Queued    int
+	Resources Resources // Reserved by running jobs
+
+	DominantShare float64 // The larger of the shares of the active workers' CPU and memory that Resources takes up
+}
+
+// Clone returns a copy of the namespace, which can be read and changed
+// without affecting the original
+func (n *Namespace) Clone() *Namespace {
+	clone := *n
+	return &clone
+}
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"math/rand"
+	"time"
+)
+
+// Backoff strategies for retrying failed jobs
+const (
+	BackoffFixed       = "fixed"
+	BackoffExponential = "exponential"
+)
+
+// MaxBackoffDelay bounds exponential backoff that does not set MaxDelay, so
+// doubling the delay cannot overflow
+const MaxBackoffDelay = 24 * time.Hour
+
+// Backoff describes how long to wait before retrying a failed job
+type Backoff struct {
+	Strategy string        // fixed or exponential
+	Delay    time.Duration // Wait before the first retry
+	MaxDelay time.Duration // Upper bound for exponential backoff; 0 means MaxBackoffDelay, or Delay if longer
+}
+
+// Attempt records one execution of a job on a worker
+type Attempt struct {
+	WorkerID  string    // Worker the attempt ran on
+	Status    string    // completed, failed or cancelled
+	ExitCode  int       // Exit code reported by the worker
+	StartTime time.Time // Time the attempt started
+	EndTime   time.Time // Time the attempt finished
+	Error     string    // Error reported by the worker, if the command could not run
+}
+
+// NextDelay returns how long to wait before the given retry, counting from 1.
+// Exponential backoff doubles the delay for every retry up to its bound and
+// applies jitter, picking a random delay between half and all of it so that
+// jobs failing together do not all retry at the same moment.
+func (b Backoff) NextDelay(retry int) time.Duration {
+	if b.Strategy != BackoffExponential {
+		return b.Delay
+	}
+
+	limit := b.MaxDelay
+	if limit == 0 {
+		limit = max(MaxBackoffDelay, b.Delay)
+	}
+	delay := b.Delay
+	for i := 1; i < retry && delay > 0 && delay < limit; i++ {
+		// Stop before doubling could go past the limit, or overflow
+		if delay > limit/2 {
+			delay = limit
+			break
+		}
+		delay *= 2
+	}
+	if delay > limit {
+		delay = limit
+	}
+	if delay <= 0 {
+		return 0
+	}
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
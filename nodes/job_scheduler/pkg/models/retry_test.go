@@ -0,0 +1,41 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNextDelay(t *testing.T) {
+	tests := []struct {
+		name    string
+		backoff Backoff
+		retry   int
+		want    time.Duration // Exponential delays are jittered between want/2 and want
+	}{
+		{name: "fixed", backoff: Backoff{Strategy: BackoffFixed, Delay: 10 * time.Second}, retry: 5, want: 10 * time.Second},
+		{name: "no strategy is fixed", backoff: Backoff{Delay: 10 * time.Second}, retry: 5, want: 10 * time.Second},
+		{name: "first retry", backoff: Backoff{Strategy: BackoffExponential, Delay: time.Second}, retry: 1, want: time.Second},
+		{name: "doubles per retry", backoff: Backoff{Strategy: BackoffExponential, Delay: time.Second}, retry: 4, want: 8 * time.Second},
+		{name: "capped by max delay", backoff: Backoff{Strategy: BackoffExponential, Delay: time.Second, MaxDelay: 5 * time.Second}, retry: 4, want: 5 * time.Second},
+		{name: "many retries do not overflow", backoff: Backoff{Strategy: BackoffExponential, Delay: time.Second}, retry: 1000, want: MaxBackoffDelay},
+		{name: "many retries with max delay", backoff: Backoff{Strategy: BackoffExponential, Delay: time.Second, MaxDelay: time.Minute}, retry: 1000, want: time.Minute},
+		{name: "delay above default bound", backoff: Backoff{Strategy: BackoffExponential, Delay: 48 * time.Hour}, retry: 3, want: 48 * time.Hour},
+		{name: "huge delay does not overflow", backoff: Backoff{Strategy: BackoffExponential, Delay: 1 << 62}, retry: 10, want: 1 << 62},
+		{name: "zero delay", backoff: Backoff{Strategy: BackoffExponential}, retry: 10, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			low := tt.want
+			if tt.backoff.Strategy == BackoffExponential {
+				low = tt.want / 2
+			}
+			for i := 0; i < 100; i++ {
+				got := tt.backoff.NextDelay(tt.retry)
+				if got < low || got > tt.want {
+					t.Fatalf("NextDelay(%d) = %s, want between %s and %s", tt.retry, got, low, tt.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// What a schedule does when it is due while the job from its previous run
+// has not finished yet
+const (
+	OverlapAllow   = "allow"   // Start another job alongside it
+	OverlapSkip    = "skip"    // Skip this run
+	OverlapReplace = "replace" // Cancel the previous job and start a new one
+)
+
+// Schedule creates a job from a template every time its cron expression fires
+type Schedule struct {
+	ID         string    // Unique identifier for the schedule
+	Name       string    // Human-readable name for the schedule
+	Namespace  string    // Namespace the schedule and the jobs it creates belong to
+	Cron       string    // Cron expression saying when to run
+	Overlap    string    // allow, skip or replace
+	Template   Job       // Job to create on every run; ID, status and times are filled in per run
+	Paused     bool      // Paused schedules do not run until resumed
+	NextRun    time.Time // Next time the schedule is due
+	LastRun    time.Time // Last time the schedule ran or skipped a run
+	LastJobID  string    // Job created by the most recent run
+	CreateTime time.Time // Time when the schedule was created
+}
+
+// Clone returns a deep copy of the schedule, which can be read and changed
+// without affecting the original
+func (s *Schedule) Clone() *Schedule {
+	clone := *s
+	clone.Template = *s.Template.Clone()
+	return &clone
+}
@@ -0,0 +1,95 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Operators a label requirement can use
+const (
+	SelectorEquals    = "="
+	SelectorNotEquals = "!="
+	SelectorIn        = "in"
+	SelectorNotIn     = "notin"
+	SelectorExists    = "exists"
+	SelectorNotExists = "!exists"
+)
+
+// Requirement is a single condition on a worker's labels
+type Requirement struct {
+	Key      string   // Label the condition is about
+	Operator string   // One of the Selector* operators
+	Values   []string // One value for = and !=, any number for in and notin, none otherwise
+}
+
+// Matches reports whether a worker with the given labels satisfies the
+// requirement. != and notin also match workers without the label at all.
+func (r Requirement) Matches(labels map[string]string) bool {
+	value, exists := labels[r.Key]
+	switch r.Operator {
+	case SelectorEquals, SelectorIn:
+		return exists && slices.Contains(r.Values, value)
+	case SelectorNotEquals, SelectorNotIn:
+		return !exists || !slices.Contains(r.Values, value)
+	case SelectorExists:
+		return exists
+	case SelectorNotExists:
+		return !exists
+	default:
+		return false
+	}
+}
+
+// String formats the requirement the way it is written in a selector
+func (r Requirement) String() string {
+	switch r.Operator {
+	case SelectorExists:
+		return r.Key
+	case SelectorNotExists:
+		return "!" + r.Key
+	case SelectorIn, SelectorNotIn:
+		return fmt.Sprintf("%s %s (%s)", r.Key, r.Operator, strings.Join(r.Values, ","))
+	default:
+		return r.Key + r.Operator + strings.Join(r.Values, ",")
+	}
+}
+
+// Selector is a set of requirements that must all hold for a worker to match
+type Selector []Requirement
+
+// Matches reports whether a worker with the given labels satisfies every
+// requirement. An empty selector matches every worker.
+func (s Selector) Matches(labels map[string]string) bool {
+	for _, requirement := range s {
+		if !requirement.Matches(labels) {
+			return false
+		}
+	}
+	return true
+}
+
+// Clone returns a deep copy of the selector
+func (s Selector) Clone() Selector {
+	clone := slices.Clone(s)
+	for i := range clone {
+		clone[i].Values = slices.Clone(clone[i].Values)
+	}
+	return clone
+}
+
+// String formats the selector the way it is written, e.g. "disk=ssd,!gpu"
+func (s Selector) String() string {
+	requirements := make([]string, len(s))
+	for i, requirement := range s {
+		requirements[i] = requirement.String()
+	}
+	return strings.Join(requirements, ",")
+}
+
+// Preference is a soft placement constraint: workers matching its selector
+// are preferred over those that do not, by its weight
+type Preference struct {
+	Selector Selector
+	Weight   int
+}
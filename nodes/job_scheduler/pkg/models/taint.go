@@ -0,0 +1,73 @@
+package models
+
+// Taint effects
+const (
+	TaintNoSchedule       = "NoSchedule"       // Jobs that do not tolerate the taint are never placed on the worker
+	TaintPreferNoSchedule = "PreferNoSchedule" // Jobs that do not tolerate the taint only go there if no other worker has room
+)
+
+// Toleration operators
+const (
+	TolerationEqual  = "Equal"  // The taint's key and value must match
+	TolerationExists = "Exists" // Only the taint's key must match, whatever its value
+)
+
+// Taint keeps jobs off a worker unless they tolerate it, e.g. to reserve the
+// worker for a team or a kind of job
+type Taint struct {
+	Key    string
+	Value  string
+	Effect string // NoSchedule or PreferNoSchedule
+}
+
+// String formats the taint as key=value:Effect
+func (t Taint) String() string {
+	if t.Value == "" {
+		return t.Key + ":" + t.Effect
+	}
+	return t.Key + "=" + t.Value + ":" + t.Effect
+}
+
+// Toleration lets a job be placed on workers with a matching taint
+type Toleration struct {
+	Key      string // Empty with Exists tolerates every taint
+	Operator string // Equal or Exists
+	Value    string // Only used with Equal
+	Effect   string // Empty tolerates the key with any effect
+}
+
+// Tolerates reports whether the toleration matches a taint
+func (t Toleration) Tolerates(taint Taint) bool {
+	if t.Effect != "" && t.Effect != taint.Effect {
+		return false
+	}
+	if t.Key == "" {
+		return t.Operator == TolerationExists
+	}
+	if t.Key != taint.Key {
+		return false
+	}
+	return t.Operator == TolerationExists || t.Value == taint.Value
+}
+
+// Untolerated returns the taints with the given effect that none of the
+// tolerations matches
+func Untolerated(taints []Taint, tolerations []Toleration, effect string) []Taint {
+	var untolerated []Taint
+	for _, taint := range taints {
+		if taint.Effect != effect {
+			continue
+		}
+		tolerated := false
+		for _, toleration := range tolerations {
+			if toleration.Tolerates(taint) {
+				tolerated = true
+				break
+			}
+		}
+		if !tolerated {
+			untolerated = append(untolerated, taint)
+		}
+	}
+	return untolerated
+}
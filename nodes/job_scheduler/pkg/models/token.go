@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// Token kinds
+const (
+	TokenAdmin  = "admin"  // Full access to the API, including managing tokens
+	TokenUser   = "user"   // Access to the API used by people and the CLI, limited by its role
+	TokenWorker = "worker" // Access to the endpoints worker agents call, as the worker it registered, and nothing else
+)
+
+// Roles of user tokens, from least to most access
+const (
+	RoleViewer    = "viewer"    // Read jobs, workflows, schedules, workers, namespaces and metrics
+	RoleSubmitter = "submitter" // Also submit jobs and workflows, cancel its own jobs and manage its own schedules
+	RoleOperator  = "operator"  // Also cancel anyone's jobs, manage anyone's schedules and register, cordon, drain and remove workers
+	RoleAdmin     = "admin"     // Everything, including namespaces, tokens and draining the server
+)
+
+// Token is an API credential. Only a hash of its secret is kept; the secret
+// itself is shown once, when the token is created.
+type Token struct {
+	ID         string    // Unique identifier, also used to revoke the token
+	Name       string    // What the token is for, e.g. who it was issued to
+	Kind       string    // admin, user or worker
+	Role       string    // Role of a user token; admin and worker tokens have none
+	Namespaces []string  // Namespaces the token may act in; empty for all of them
+	WorkerID   string    // Worker a worker token last registered, the only one it may act as
+	Hash       string    // Hex-encoded SHA-256 of the secret
+	CreateTime time.Time // Time when the token was created
+	ExpiresAt  time.Time // Time after which the token is rejected; zero if it never expires
+}
+
+// Expired reports whether the token may no longer be used
+func (t *Token) Expired(now time.Time) bool {
+	return !t.ExpiresAt.IsZero() && now.After(t.ExpiresAt)
+}
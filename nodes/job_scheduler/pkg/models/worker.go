@@ -1,6 +1,8 @@
 package models
 
 import (
+	"maps"
+	"slices"
 	"time"
 	"github.com/google/uuid"
 )
@@ -11,15 +13,49 @@ type Resources struct {
 	MemoryMB int // Available memory in MB
 }
 
+// Add returns the sum of two sets of resources
+func (r Resources) Add(other Resources) Resources {
+	return Resources{CPUCores: r.CPUCores + other.CPUCores, MemoryMB: r.MemoryMB + other.MemoryMB}
+}
+
+// Sub returns r minus other
+func (r Resources) Sub(other Resources) Resources {
+	return Resources{CPUCores: r.CPUCores - other.CPUCores, MemoryMB: r.MemoryMB - other.MemoryMB}
+}
+
+// Covers reports whether r is at least as large as other in every dimension
+func (r Resources) Covers(other Resources) bool {
+	return r.CPUCores >= other.CPUCores && r.MemoryMB >= other.MemoryMB
+}
+
 // Worker represents a node that can execute jobs
 type Worker struct {
 	ID           string    // Unique identifier for the worker
 	Name         string    // Human-readable name for the worker
-	Status       string    // Current status: active, offline, busy
+	Namespace    string    // Only runs jobs from this namespace; empty for a worker shared by all of them
+	Status       string    // Current status: active, cordoned, draining, offline, busy
+	Cordoned     bool      // Taken out of rotation by an operator; kept while the worker is offline
 	Resources    Resources // Available resources on this worker
+	Allocated    Resources // Resources reserved by jobs currently assigned to this worker
+	Labels       map[string]string // Key/value attributes jobs can select workers by, e.g. disk=ssd
+	Taints       []Taint   // Keep jobs that do not tolerate them off this worker
 	LastHeartbeat time.Time // Last time we heard from this worker
 }
 
+// Free returns the resources not reserved by any assigned job
+func (w *Worker) Free() Resources {
+	return w.Resources.Sub(w.Allocated)
+}
+
+// Clone returns a deep copy of the worker, which can be read and changed
+// without affecting the original
+func (w *Worker) Clone() *Worker {
+	clone := *w
+	clone.Labels = maps.Clone(w.Labels)
+	clone.Taints = slices.Clone(w.Taints)
+	return &clone
+}
+
 // NewWorker creates a new Worker with default values
 func NewWorker(name string, cpuCores, memoryMB int) *Worker {
 	return &Worker{
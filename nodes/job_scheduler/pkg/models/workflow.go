@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// Workflow is a group of jobs submitted together, where jobs may depend on
+// others in the same workflow and only run once those completed
+type Workflow struct {
+	ID         string    // Unique identifier for the workflow
+	Name       string    // Human-readable name for the workflow
+	Namespace  string    // Namespace the workflow and its jobs belong to
+	JobIDs     []string  // Jobs in the workflow, in the order they were submitted
+	SubmitTime time.Time // Time when the workflow was submitted
+}
+
+// WorkflowStatus summarizes the statuses of a workflow's jobs: running while
+// any job has not finished, completed once every job completed and failed
+// otherwise
+func WorkflowStatus(jobs []*Job) string {
+	status := "completed"
+	for _, job := range jobs {
+		if !job.Finished() {
+			return "running"
+		}
+		if job.Status != "completed" {
+			status = "failed"
+		}
+	}
+	return status
+}